@@ -0,0 +1,128 @@
+package apigen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ActionInfo describes a custom endpoint registered on a model
+type ActionInfo struct {
+	Verb       string // HTTP method
+	ActionPath string // Path relative to the model's base path, e.g. "/:id/activate"
+	Path       string // Full route path, including the mount path, e.g. "/api/users/:id/activate"
+	Handler    gin.HandlerFunc
+	Meta       ActionSwaggerMeta
+}
+
+// ActionSwaggerMeta documents a custom action in the Swagger document
+type ActionSwaggerMeta struct {
+	Summary     string
+	Description string
+	Tags        []string
+	RequestBody map[string]any // Schema of the request body, if the action takes one
+	Responses   map[string]any // Swagger responses keyed by status code
+}
+
+// RegisterAction adds a custom endpoint under the base path of a registered model,
+// e.g. RegisterAction("User", "POST", "/:id/activate", handler) serves
+// POST /api/users/:id/activate
+func (g *APIGenerator) RegisterAction(modelName, verb, actionPath string, handler gin.HandlerFunc) error {
+	return g.RegisterActionWithMeta(modelName, verb, actionPath, handler, ActionSwaggerMeta{})
+}
+
+// RegisterActionWithMeta adds a custom endpoint like RegisterAction, documented by meta
+func (g *APIGenerator) RegisterActionWithMeta(modelName, verb, actionPath string, handler gin.HandlerFunc, meta ActionSwaggerMeta) error {
+	modelInfo, exists := g.Models[modelName]
+	if !exists {
+		return fmt.Errorf("model %s is not registered", modelName)
+	}
+
+	verb = strings.ToUpper(verb)
+	if !strings.HasPrefix(actionPath, "/") {
+		actionPath = "/" + actionPath
+	}
+
+	for _, action := range modelInfo.Actions {
+		if action.Verb == verb && action.ActionPath == actionPath {
+			return fmt.Errorf("action %s %s is already registered on model %s", verb, actionPath, modelName)
+		}
+	}
+
+	routePath := "/api/" + modelInfo.PluralName + actionPath
+	action := ActionInfo{
+		Verb:       verb,
+		ActionPath: actionPath,
+		Path:       g.mountPath + routePath,
+		Handler:    handler,
+		Meta:       meta,
+	}
+	g.handle(modelInfo, VerbCustom, verb, routePath, handler)
+
+	modelInfo.Actions = append(modelInfo.Actions, action)
+	g.Models[modelName] = modelInfo
+
+	// Document the action if the Swagger document was already built
+	if g.swaggerJSON.Load() != nil {
+		return g.buildSwaggerDocument()
+	}
+	return nil
+}
+
+// ListActions returns the custom actions registered on a model
+func (g *APIGenerator) ListActions(modelName string) []ActionInfo {
+	return append([]ActionInfo(nil), g.Models[modelName].Actions...)
+}
+
+// swaggerAction builds the Swagger operation of a custom action
+func swaggerAction(modelInfo ModelInfo, action ActionInfo) map[string]any {
+	summary := action.Meta.Summary
+	if summary == "" {
+		summary = fmt.Sprintf("%s %s on a %s", action.Verb, action.ActionPath, modelInfo.ResourceName)
+	}
+
+	var parameters []map[string]any
+	for _, segment := range strings.Split(action.ActionPath, "/") {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			parameters = append(parameters, map[string]any{"name": segment[1:], "in": "path", "required": true, "type": "string"})
+		}
+	}
+	if action.Meta.RequestBody != nil {
+		parameters = append(parameters, map[string]any{
+			"in":       "body",
+			"name":     "body",
+			"required": true,
+			"schema":   action.Meta.RequestBody,
+		})
+	}
+
+	responses := action.Meta.Responses
+	if len(responses) == 0 {
+		responses = map[string]any{"200": map[string]any{"description": "Success"}}
+	}
+
+	operation := map[string]any{
+		"summary":    summary,
+		"parameters": parameters,
+		"responses":  responses,
+	}
+	if action.Meta.Description != "" {
+		operation["description"] = action.Meta.Description
+	}
+	if len(action.Meta.Tags) > 0 {
+		operation["tags"] = action.Meta.Tags
+	}
+	return operation
+}
+
+// swaggerPath converts a gin route path to a Swagger path, e.g. "/users/:id" to "/users/{id}"
+func swaggerPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,94 @@
+package apigen
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+)
+
+// adminSecretHeader carries the AdminSecret in requests to the admin endpoint
+const adminSecretHeader = "X-Admin-Secret"
+
+// AdminModel describes a registered model in the response of the admin endpoint
+type AdminModel struct {
+	Model          string       `json:"model"`
+	Resource       string       `json:"resource"`
+	Plural         string       `json:"plural"`
+	Fields         []AdminField `json:"fields"`
+	Routes         []AdminRoute `json:"routes"`
+	ComputedFields []string     `json:"computed_fields"`
+}
+
+// AdminField describes a field of a model exposed by the API
+type AdminField struct {
+	Name     string `json:"name"`
+	JSONName string `json:"json_name"`
+	Type     string `json:"type"`
+	ID       bool   `json:"id"`
+	Optional bool   `json:"optional"`
+	Nullable bool   `json:"nullable"`
+}
+
+// AdminRoute describes an endpoint serving a model
+type AdminRoute struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Verb   string `json:"verb"`
+}
+
+// RegisterAdminEndpoint registers GET path listing the registered models with their
+// fields and routes, for admin UIs and API explorers. Requests must send AdminSecret in
+// the X-Admin-Secret header; every request is refused while AdminSecret is empty.
+func (g *APIGenerator) RegisterAdminEndpoint(path string) {
+	g.Group.GET(path, func(c *gin.Context) {
+		secret := c.GetHeader(adminSecretHeader)
+		if g.AdminSecret == "" || subtle.ConstantTimeCompare([]byte(secret), []byte(g.AdminSecret)) != 1 {
+			g.respondError(c, http.StatusUnauthorized, errors.New("invalid admin secret"))
+			return
+		}
+
+		c.JSON(http.StatusOK, g.adminModels())
+	})
+}
+
+// adminModels describes the registered models, sorted by name
+func (g *APIGenerator) adminModels() []AdminModel {
+	models := make([]AdminModel, 0, len(g.Models))
+	for name, modelInfo := range g.Models {
+		model := AdminModel{
+			Model:          name,
+			Resource:       modelInfo.ResourceName,
+			Plural:         modelInfo.PluralName,
+			Fields:         []AdminField{},
+			Routes:         []AdminRoute{},
+			ComputedFields: []string{},
+		}
+		for _, field := range modelInfo.Fields {
+			model.Fields = append(model.Fields, AdminField{
+				Name:     field.Name,
+				JSONName: field.JSONName,
+				Type:     field.Type.String(),
+				ID:       field.IsID,
+				Optional: field.OmitEmpty,
+				Nullable: field.Nullable,
+			})
+		}
+		for _, route := range g.routes {
+			if route.ModelName == name {
+				model.Routes = append(model.Routes, AdminRoute{Method: route.Method, Path: g.mountPath + route.Path, Verb: route.Verb})
+			}
+		}
+		for _, field := range modelInfo.ComputedFields {
+			model.ComputedFields = append(model.ComputedFields, field.JSONName)
+		}
+		models = append(models, model)
+	}
+
+	sort.Slice(models, func(i, j int) bool {
+		return models[i].Model < models[j].Model
+	})
+	return models
+}
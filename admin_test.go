@@ -0,0 +1,45 @@
+package apigen
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestAdminEndpoint(t *testing.T) {
+	_, router := newTestAPI(t, func(g *APIGenerator) {
+		g.AdminSecret = "s3cret"
+		g.RegisterModelWithOptions(&testUser{})
+		g.RegisterModelWithOptions(&testStory{})
+		g.RegisterAdminEndpoint("/admin/models")
+	}, &testUser{}, &testStory{})
+
+	if w := serve(router, http.MethodGet, "/admin/models", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("without the secret: got %d, want 401", w.Code)
+	}
+	if w := serve(router, http.MethodGet, "/admin/models", "", adminSecretHeader, "guess"); w.Code != http.StatusUnauthorized {
+		t.Errorf("with a wrong secret: got %d, want 401", w.Code)
+	}
+
+	w := serve(router, http.MethodGet, "/admin/models", "", adminSecretHeader, "s3cret")
+	if w.Code != http.StatusOK {
+		t.Fatalf("with the secret: got %d %s", w.Code, w.Body)
+	}
+	models := decode[[]AdminModel](t, w)
+	if len(models) != 2 || models[0].Model != "testStory" || models[1].Model != "testUser" {
+		t.Fatalf("got models %+v", models)
+	}
+	user := models[1]
+	if user.Plural != "test_users" || len(user.Fields) != 3 || user.Fields[1].JSONName != "name" || len(user.Routes) == 0 {
+		t.Errorf("user model: %+v", user)
+	}
+}
+
+func TestAdminEndpointWithoutSecret(t *testing.T) {
+	_, router := newTestAPI(t, func(g *APIGenerator) {
+		g.RegisterAdminEndpoint("/admin/models")
+	}, &testUser{})
+
+	if w := serve(router, http.MethodGet, "/admin/models", "", adminSecretHeader, ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("empty AdminSecret: got %d, want 401", w.Code)
+	}
+}
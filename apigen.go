@@ -4,19 +4,164 @@ import (
 	"fmt"
 	"net/http"
 	"reflect"
+	"slices"
 	"strings"
+	"sync/atomic"
+	"time"
 	"unicode"
 
 	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
 )
 
+// defaultMinQueryLength is the shortest search term accepted by default
+const defaultMinQueryLength = 2
+
 // APIGenerator handles the generation of REST APIs from GORM models
 type APIGenerator struct {
 	DB              *gorm.DB
-	Router          *gin.Engine
+	Router          *gin.Engine // The engine passed to New, nil when mounted on a group
+	Group           gin.IRouter // Where the routes are registered: Router, or the group passed to NewWithGroup
 	Models          map[string]ModelInfo
 	RegisteredPaths map[string]bool // Track registered paths to avoid duplicates
+	MinQueryLength  int             // Minimum length of the search term accepted by search endpoints
+
+	// CSVDisabledModels lists the model names that can never be exported as CSV
+	CSVDisabledModels []string
+
+	// RateLimitStore holds the token buckets of rate limited models
+	RateLimitStore RateLimitStore
+	// RateLimitKey identifies the client of a rate limited request, by the address of
+	// the connection if nil. Behind a proxy, set it to (*gin.Context).ClientIP and tell
+	// the router which proxies to trust with SetTrustedProxies.
+	RateLimitKey func(c *gin.Context) string
+
+	// MaxBulkSize caps the number of records a bulk request may address, 1000 if it
+	// isn't positive
+	MaxBulkSize int
+
+	// MaxBodySize caps the size of request bodies in bytes, 10 MiB by default. Larger
+	// bodies are answered 413. Zero lifts the limit; see WithMaxBodySize.
+	MaxBodySize int64
+
+	// DBTimeout cancels the queries of a request running longer than this, answering
+	// 503 with "query timeout". Zero, the default, lets queries run; see WithDBTimeout.
+	DBTimeout time.Duration
+
+	// IrregularPlurals maps words to plurals that the suffix rules get wrong. New fills
+	// it with DefaultIrregularPlurals; use AddIrregularPlural to extend it.
+	IrregularPlurals map[string]string
+
+	// SwaggerInfo describes the API in the Swagger document
+	SwaggerInfo SwaggerInfo
+
+	// SwaggerFilePath is where GenerateAPI writes the Swagger document, if set
+	SwaggerFilePath string
+
+	// IncludeUntaggedFields exposes fields without a json tag under their gorm column
+	// name, or else their snake_case name. Untagged fields are skipped by default.
+	IncludeUntaggedFields bool
+
+	// TagKey is the struct tag naming the fields of the models registered afterwards,
+	// e.g. "yaml" or "bson" for models without json tags. Responses are still JSON, and
+	// fields are renamed between their encoding/json and tag names. "json" by default.
+	TagKey string
+
+	// ConvertFieldNames renames the fields of the models registered afterwards in
+	// requests, responses, query parameters and the Swagger document, e.g. to camelCase
+	ConvertFieldNames FieldNameConvention
+
+	// EnableLogging logs every request served by the generated endpoints to Logger,
+	// or as JSON to stdout if Logger is nil. It must be set before GenerateAPI.
+	EnableLogging bool
+	Logger        Logger
+
+	// EnableMetrics records Prometheus metrics for every request served by the generated
+	// endpoints, in Metrics or else on the default registry. It must be set before GenerateAPI.
+	EnableMetrics bool
+	Metrics       *Metrics
+
+	// ValidationErrorMapper formats request body errors, DefaultValidationErrorMapper if nil
+	ValidationErrorMapper ValidationErrorMapper
+
+	// ErrorFormatter builds the body of error responses, e.g. RFC7807ErrorFormatter.
+	// DefaultErrorFormatter is used if nil.
+	ErrorFormatter ErrorFormatter
+
+	// ValidationMessages overrides the messages of DefaultValidationMessages by validator tag
+	ValidationMessages map[string]string
+
+	// DryRun runs the write endpoints up to the database call without changing any
+	// record, answering as if the write succeeded. A request can also opt in by
+	// sending X-Dry-Run: true.
+	DryRun bool
+
+	// MultiTenancy isolates the records of its enforced models per tenant. It must be
+	// set before GenerateAPI.
+	MultiTenancy MultiTenancy
+
+	// FileStore saves the files uploaded to fields tagged apigen:"file" in multipart
+	// request bodies. Uploads are rejected if it is nil. The files of failed requests
+	// are deleted if it implements FileDeleter.
+	FileStore FileStore
+
+	// PaginationLinkStyle selects whether paginated list responses report the page in
+	// the response body, in an RFC 5988 Link header, or both
+	PaginationLinkStyle PaginationLinkStyle
+
+	// BaseURL prefixes the links of responses, the HAL links and the pagination Link
+	// header, e.g. "https://api.example.com". They are paths on the host if it is empty.
+	BaseURL string
+
+	// AnalyzeQueryPlan logs the plan of every list query at debug level, for finding
+	// the filters missing an index
+	AnalyzeQueryPlan bool
+
+	// TotalCountHeader reports the number of records matching a list or search query
+	// in the X-Total-Count header, alongside the pagination meta of the body
+	TotalCountHeader bool
+
+	// ResponseCache configures the cache of the models registered with WithCaching
+	ResponseCache CacheConfig
+
+	// Idempotency configures the replay of create requests sent with an Idempotency-Key
+	Idempotency IdempotencyConfig
+
+	// CompressResponses gzips the response bodies of clients sending Accept-Encoding:
+	// gzip. Bodies under 1 KiB and event streams are sent as they are.
+	CompressResponses bool
+
+	// AdminSecret must be sent in the X-Admin-Secret header of requests to the admin
+	// endpoint, see RegisterAdminEndpoint
+	AdminSecret string
+
+	// DBErrorClassifier maps the database errors of writes to responses, e.g. unique
+	// violations to 409. DefaultDBErrorClassifier is used if nil.
+	DBErrorClassifier DBErrorClassifier
+
+	// StrictSchemaValidation rejects request bodies holding fields the model doesn't expose
+	StrictSchemaValidation bool
+
+	// VendorName is the vendor of the media types selecting an API version, e.g.
+	// application/vnd.{VendorName}.v2+json; "api" by default. See RegisterVersion.
+	VendorName string
+
+	// DefaultVersion is the API version serving requests that don't ask for one. The
+	// registered models serve them if it is empty.
+	DefaultVersion string
+
+	tracer      trace.Tracer             // Set by EnableTracing
+	auditLogger AuditLogger              // Set by SetAuditLogger
+	envelope    *Envelope                // Set by WithEnvelope
+	routes      []RouteInfo              // Appended to by handle
+	versions    map[string]*versionedAPI // Added by RegisterVersion
+	mountPath   string                   // Path of the group the API is mounted on, set by NewWithGroup
+
+	circuitBreaker CircuitBreaker // Set by SetCircuitBreaker
+	swaggerJSON    atomic.Value   // []byte of the Swagger document, set by buildSwaggerDocument
 }
 
 // ModelInfo stores metadata about a model
@@ -26,6 +171,141 @@ type ModelInfo struct {
 	ForeignKeys  []ForeignKeyInfo
 	ResourceName string
 	PluralName   string
+
+	// TableName is the database table of the model, as returned by its TableName method
+	// or else derived by the GORM naming strategy
+	TableName string
+
+	// PrimaryKeyFields lists the fields making up the primary key, in declaration order
+	PrimaryKeyFields []FieldInfo
+
+	// PrimaryKeyField is the field addressed by the :id of single record routes, e.g.
+	// UserID for a model tagged `gorm:"primaryKey"` on it. It is empty for composite keys.
+	PrimaryKeyField FieldInfo
+
+	// DisableCount turns off the /count endpoint
+	DisableCount bool
+
+	// SearchableFields lists the JSON names of the fields matched by the search endpoint.
+	// It defaults to every string field and can be overridden after registration.
+	SearchableFields []string
+
+	// RateLimits maps an HTTP method ("*" for any) to the requests per minute allowed per client IP
+	RateLimits map[string]int
+
+	// LockVersion enables optimistic locking through the model's Version field
+	LockVersion bool
+
+	// Hooks are run around the write operations of the model
+	Hooks ModelHooks
+
+	// DisabledVerbs lists the HTTP methods ("GET", "POST", "PUT", "PATCH", "DELETE") whose
+	// routes are not registered, so calling them answers 405 Method Not Allowed
+	DisabledVerbs []string
+
+	// UniqueKey lists the JSON names of the fields the upsert endpoint matches records on.
+	// The primary key is used when it is empty.
+	UniqueKey []string
+
+	// Actions lists the custom endpoints added with RegisterAction
+	Actions []ActionInfo
+
+	// AuthMiddleware authenticates the requests to every endpoint of the model
+	AuthMiddleware []gin.HandlerFunc
+
+	// EnableBulkDelete registers DELETE /api/{plural}/bulk
+	EnableBulkDelete bool
+
+	// EnableBulkPatch registers PATCH /api/{plural}/bulk
+	EnableBulkPatch bool
+
+	// EnableLinkUnlink registers LINK and UNLINK /api/{plural}/{id}/{related}/{related_id},
+	// adding and removing many-to-many associations
+	EnableLinkUnlink bool
+
+	// EnableHATEOAS adds HAL links to the record returned by the get endpoint
+	EnableHATEOAS bool
+
+	// EnableCSVImport registers POST /api/{plural}/import, creating a record per row of
+	// an uploaded CSV file
+	EnableCSVImport bool
+
+	// ReadOnlyFields lists the JSON names of the fields ignored in request bodies
+	ReadOnlyFields []string
+
+	// TransformResponse post-processes the records of every response, if set
+	TransformResponse ResponseTransform
+
+	// TransformRequest rewrites the records bound by create and update requests, if set
+	TransformRequest RequestTransform
+
+	// FieldNames is the APIGenerator's ConvertFieldNames when the model was registered
+	FieldNames FieldNameConvention
+
+	// Cacheable serves the list and get endpoints from the response cache
+	Cacheable bool
+
+	// Scopes are applied to every query run for the model, e.g. a tenant filter
+	Scopes []func(*gorm.DB) *gorm.DB
+
+	// DefaultPreloads lists the associations always loaded by the list and get endpoints
+	DefaultPreloads []string
+
+	// ComputedFields are read-only fields added to every response of the model
+	ComputedFields []ComputedField
+
+	// PreloadAssociations loads every association of the records returned by the list
+	// and get endpoints, with one query per association rather than per record
+	PreloadAssociations bool
+
+	// EnableLastModified sets Last-Modified on the get endpoint from the UpdatedAt
+	// field and answers 304 to If-Modified-Since requests for unchanged records
+	EnableLastModified bool
+
+	// SoftDelete is set when the model has a gorm.DeletedAt field
+	SoftDelete bool
+
+	// EnableRestore registers POST /api/{plural}/{id}/restore for soft deleted models
+	EnableRestore bool
+
+	// EnableHardDelete registers DELETE /api/{plural}/{id}/destroy
+	EnableHardDelete bool
+
+	// IndexHints maps field API names to the index used by queries filtering on them
+	IndexHints map[string]string
+
+	// NestedResources lists the child models served under the model's instance path
+	NestedResources []NestedResource
+
+	// MaxBodySize caps the size of request bodies in bytes, APIGenerator.MaxBodySize if
+	// zero and unlimited if negative
+	MaxBodySize int64
+
+	// DBTimeout cancels the queries of a request after this long, APIGenerator.DBTimeout
+	// if zero and never if negative
+	DBTimeout time.Duration
+
+	// CachePolicy sets the Cache-Control header of the list and get responses, if set
+	CachePolicy *CachePolicy
+
+	// OrderedFields lists the JSON names of the properties listed first in the Swagger
+	// schemas of the model, in that order; the others follow in declaration order
+	OrderedFields []string
+
+	// CircuitBreaker rejects the requests of the model while its database fails,
+	// overriding the one set with SetCircuitBreaker
+	CircuitBreaker CircuitBreaker
+
+	// Policy decides which requests may reach the records of the model, if set
+	Policy ResourcePolicy
+
+	// DeprecatedFields maps the JSON names of the deprecated fields of the model to the
+	// names of the fields replacing them, "" if none does
+	DeprecatedFields map[string]string
+
+	// DefaultValues maps the JSON names of fields to the values set when a create
+	// request leaves them out
+	DefaultValues map[string]any
 }
 
 // FieldInfo stores metadata about a model field
@@ -34,7 +314,14 @@ type FieldInfo struct {
 	JSONName  string
 	Type      reflect.Type
 	IsID      bool
+	IsUUID    bool // Whether the field stores a UUID
 	OmitEmpty bool
+	Untagged  bool   // Whether JSONName was derived because the field has no json tag, or TagKey tag
+	WireName  string // Key of the field in encoding/json documents, when it isn't JSONName
+	Column    string // Database column set in the gorm tag, if any
+	IsFile    bool   // Whether the field stores the URL of a file uploaded as multipart form data
+	Nullable  bool   // Whether the field is a pointer, which a null in the request body clears
+	Binding   string // Validation rules of the binding tag, e.g. "min=18,max=120"
 }
 
 // ForeignKeyInfo stores metadata about a foreign key relationship
@@ -43,63 +330,159 @@ type ForeignKeyInfo struct {
 	RelatedModel   string
 	RelatedField   string
 	RelationshipID string
+
+	// RelationType is RelationPolymorphic for polymorphic associations, and empty otherwise
+	RelationType RelationType
+	// PolymorphicType is the prefix of the owner columns, e.g. "Owner" for owner_id and owner_type
+	PolymorphicType string
+	// PolymorphicValue is the owner_type stored for the parent, its table name if empty
+	PolymorphicValue string
 }
 
 // New creates a new APIGenerator instance
 func New(db *gorm.DB, router *gin.Engine) *APIGenerator {
+	g := newGenerator(db, router)
+	g.Router = router
+	return g
+}
+
+// NewWithGroup creates a new APIGenerator mounting the API under a router group, e.g.
+// router.Group("/v1") serves the users at /v1/api/users
+func NewWithGroup(db *gorm.DB, group *gin.RouterGroup) *APIGenerator {
+	g := newGenerator(db, group)
+	g.mountPath = strings.TrimSuffix(group.BasePath(), "/")
+	g.SwaggerInfo.BasePath = group.BasePath()
+	return g
+}
+
+// MountPath returns the path of the group the API is mounted on, e.g. "/v1", or an
+// empty string when it is served from the engine's root
+func (g *APIGenerator) MountPath() string {
+	return g.mountPath
+}
+
+// newGenerator creates an APIGenerator registering its routes on router
+func newGenerator(db *gorm.DB, router gin.IRouter) *APIGenerator {
 	return &APIGenerator{
-		DB:              db,
-		Router:          router,
-		Models:          make(map[string]ModelInfo),
-		RegisteredPaths: make(map[string]bool),
+		DB:               db,
+		Group:            router,
+		Models:           make(map[string]ModelInfo),
+		RegisteredPaths:  make(map[string]bool),
+		MinQueryLength:   defaultMinQueryLength,
+		MaxBulkSize:      defaultMaxBulkSize,
+		MaxBodySize:      defaultMaxBodySize,
+		RateLimitStore:   NewMemoryRateLimitStore(),
+		TagKey:           defaultTagKey,
+		IrregularPlurals: copyPlurals(DefaultIrregularPlurals),
+	}
+}
+
+// maxMultipartMemory returns the memory limit of multipart form parsing, gin's default
+// when the API is mounted on a group
+func (g *APIGenerator) maxMultipartMemory() int64 {
+	if g.Router != nil {
+		return g.Router.MaxMultipartMemory
 	}
+	return defaultMultipartMemory
 }
 
 // RegisterModel registers a GORM model with the API generator
-func (g *APIGenerator) RegisterModel(model any, resourceName string) error {
+//
+// Deprecated: use RegisterModelWithOptions with WithResourceName.
+func (g *APIGenerator) RegisterModel(model any, resourceName string, opts ...ModelOption) error {
+	return g.RegisterModelWithOptions(model, append([]ModelOption{WithResourceName(resourceName)}, opts...)...)
+}
+
+// RegisterModelWithOptions registers a GORM model with the API generator
+func (g *APIGenerator) RegisterModelWithOptions(model any, opts ...ModelOption) error {
+	modelInfo, err := g.analyzeModel(model, opts...)
+	if err != nil {
+		return err
+	}
+	if err := configError(g.ValidateModelInfo(modelInfo)); err != nil {
+		return err
+	}
+
+	g.Models[modelInfo.Type.Name()] = modelInfo
+	return nil
+}
+
+// analyzeModel builds the ModelInfo of a GORM model with the given options applied
+func (g *APIGenerator) analyzeModel(model any, opts ...ModelOption) (ModelInfo, error) {
 	modelType := reflect.TypeOf(model)
 	if modelType.Kind() == reflect.Ptr {
 		modelType = modelType.Elem()
 	}
 
 	if modelType.Kind() != reflect.Struct {
-		return fmt.Errorf("model must be a struct, got %s", modelType.Kind())
+		return ModelInfo{}, fmt.Errorf("model must be a struct, got %s", modelType.Kind())
 	}
 
-	// If resourceName is not provided, derive it from the model name
-	if resourceName == "" {
-		resourceName = toSnakeCase(modelType.Name())
-	}
-
-	pluralName := pluralize(resourceName)
-
 	modelInfo := ModelInfo{
-		Type:         modelType,
-		ResourceName: resourceName,
-		PluralName:   pluralName,
+		Type:       modelType,
+		SoftDelete: hasSoftDelete(modelType),
+		FieldNames: g.ConvertFieldNames,
 	}
 
 	// Process fields
 	for i := 0; i < modelType.NumField(); i++ {
 		field := modelType.Field(i)
-		jsonTag := field.Tag.Get("json")
-		if jsonTag == "" || jsonTag == "-" {
+		jsonTag := field.Tag.Get(fieldTagKey(g.TagKey))
+		untagged := jsonTag == "" && g.IncludeUntaggedFields && field.IsExported() && !field.Anonymous
+		if (jsonTag == "" && !untagged) || jsonTag == "-" {
 			continue
 		}
 
 		jsonName := strings.Split(jsonTag, ",")[0]
 		omitEmpty := strings.Contains(jsonTag, "omitempty")
+		wireName := ""
+		if untagged {
+			jsonName = derivedJSONName(field)
+			wireName = field.Name
+		} else if fieldTagKey(g.TagKey) != defaultTagKey {
+			wireName = jsonKey(field)
+		}
+		if g.ConvertFieldNames == CamelCase {
+			if wireName == "" {
+				wireName = jsonName
+			}
+			jsonName = toCamelCase(jsonName)
+		}
+		if wireName == jsonName {
+			wireName = ""
+		}
 
 		fieldInfo := FieldInfo{
 			Name:      field.Name,
 			JSONName:  jsonName,
 			Type:      field.Type,
 			IsID:      field.Name == "ID" || strings.HasSuffix(field.Name, "ID"),
+			IsUUID:    isUUIDField(field),
 			OmitEmpty: omitEmpty,
+			Untagged:  untagged,
+			WireName:  wireName,
+			Column:    gormColumn(field),
+			IsFile:    isFileField(field),
+			Nullable:  field.Type.Kind() == reflect.Ptr,
+			Binding:   field.Tag.Get("binding"),
 		}
 
 		modelInfo.Fields = append(modelInfo.Fields, fieldInfo)
 
+		// Check for polymorphic, has-one and many-to-many associations
+		if fkInfo, ok := polymorphicRelation(field); ok {
+			modelInfo.ForeignKeys = append(modelInfo.ForeignKeys, fkInfo)
+			continue
+		}
+		if fkInfo, ok := hasOneRelation(modelType, field); ok {
+			modelInfo.ForeignKeys = append(modelInfo.ForeignKeys, fkInfo)
+			continue
+		}
+		if fkInfo, ok := manyToManyRelation(field); ok {
+			modelInfo.ForeignKeys = append(modelInfo.ForeignKeys, fkInfo)
+			continue
+		}
+
 		// Check for foreign key relationships
 		if field.Type.Kind() == reflect.Struct && !isBasicType(field.Type) {
 			// This could be a foreign key relationship
@@ -113,7 +496,7 @@ func (g *APIGenerator) RegisterModel(model any, resourceName string) error {
 		}
 
 		// Check for foreign key ID fields
-		if strings.HasSuffix(field.Name, "ID") && field.Type.Kind() == reflect.Uint {
+		if strings.HasSuffix(field.Name, "ID") && field.Type.Kind() == reflect.Uint && !isPrimaryKeyField(field) {
 			relatedModel := strings.TrimSuffix(field.Name, "ID")
 			fkInfo := ForeignKeyInfo{
 				FieldName:      field.Name,
@@ -124,69 +507,277 @@ func (g *APIGenerator) RegisterModel(model any, resourceName string) error {
 		}
 	}
 
-	g.Models[modelType.Name()] = modelInfo
-	return nil
+	modelInfo.PrimaryKeyFields = primaryKeyFields(modelType, modelInfo.Fields)
+	modelInfo.PrimaryKeyField = singlePrimaryKey(modelType, modelInfo.PrimaryKeyFields)
+	var namer schema.Namer = schema.NamingStrategy{}
+	if g.DB != nil {
+		namer = g.DB.NamingStrategy
+	}
+	modelInfo.TableName = tableName(modelType, namer)
+
+	// Apply the model options
+	for _, opt := range opts {
+		opt(&modelInfo)
+	}
+
+	// If no resource name was given, take it from the apigen tag or derive it from the model name
+	modelInfo.ResourceName, modelInfo.PluralName = resourceNames(modelType, modelInfo.ResourceName, g.IrregularPlurals)
+
+	if modelInfo.SearchableFields == nil {
+		modelInfo.SearchableFields = searchableFields(modelInfo.Fields)
+	}
+
+	if modelInfo.LockVersion {
+		if err := validateVersionField(modelInfo); err != nil {
+			return ModelInfo{}, err
+		}
+	}
+	if err := validateUniqueKey(modelInfo); err != nil {
+		return ModelInfo{}, err
+	}
+	return modelInfo, nil
 }
 
-// GenerateAPI generates REST API endpoints for all registered models
-func (g *APIGenerator) GenerateAPI(resourceTitle string, resourceVersion string) {
+// GenerateAPI generates REST API endpoints for all registered models and serves their
+// Swagger document at /swagger.json. When SwaggerFilePath is set the document is also
+// written to that file.
+func (g *APIGenerator) GenerateAPI(resourceTitle string, resourceVersion string) error {
+	if g.MultiTenancy.TenantField != "" && g.MultiTenancy.Authorize == nil {
+		log.Warn().Msg("apigen: MultiTenancy.Authorize is not set, so clients can name any tenant in the tenant header")
+	}
+	if err := configError(g.checkRelations()); err != nil {
+		return err
+	}
 	for _, modelInfo := range g.Models {
 		g.generateModelAPI(modelInfo)
 	}
+	if err := g.generateVersions(); err != nil {
+		return err
+	}
 
 	// Generate Swagger docs
-	swaggerGen := NewSwaggerGenerator(g.Models)
-	definitions := swaggerGen.GenerateModelDefinitions()
-	swaggerGen.BuildPathsForAllModels()
-
-	// Serve Swagger JSON
-	g.Router.GET("/swagger.json", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"swagger":     "2.0",
-			"info":        gin.H{"title": resourceTitle, "version": resourceVersion},
-			"paths":       swaggerGen.GenerateAllPaths(),
-			"definitions": definitions,
-		})
-	})
+	if resourceTitle != "" {
+		g.SwaggerInfo.Title = resourceTitle
+	}
+	if resourceVersion != "" {
+		g.SwaggerInfo.Version = resourceVersion
+	}
+	if err := g.buildSwaggerDocument(); err != nil {
+		return fmt.Errorf("failed to build swagger document: %w", err)
+	}
+	g.ServeSwagger("/swagger.json")
+
+	if g.SwaggerFilePath != "" {
+		if err := g.writeSwaggerFile(g.SwaggerFilePath); err != nil {
+			return fmt.Errorf("failed to write swagger file: %w", err)
+		}
+	}
+
+	return nil
 }
 
 // generateModelAPI generates REST API endpoints for a specific model
 func (g *APIGenerator) generateModelAPI(modelInfo ModelInfo) {
 	basePath := fmt.Sprintf("/api/%s", modelInfo.PluralName)
+	instancePath := basePath + modelInfo.instancePath()
+
+	// Register routes (static segments must come before the /:id routes)
+	if modelInfo.verbEnabled(http.MethodGet) {
+		g.handle(modelInfo, VerbList, http.MethodGet, basePath, g.listHandler(modelInfo))
+		g.handle(modelInfo, VerbSearch, http.MethodGet, fmt.Sprintf("%s/search", basePath), g.searchHandler(modelInfo))
+		g.handle(modelInfo, VerbQuery, http.MethodPost, fmt.Sprintf("%s/query", basePath), g.queryHandler(modelInfo))
+		if !modelInfo.DisableCount {
+			g.handle(modelInfo, VerbCount, http.MethodGet, fmt.Sprintf("%s/count", basePath), g.countHandler(modelInfo))
+		}
+		g.handle(modelInfo, VerbGet, http.MethodGet, instancePath, g.getHandler(modelInfo))
+		g.handle(modelInfo, VerbList, http.MethodHead, basePath, g.headListHandler(modelInfo))
+		g.handle(modelInfo, VerbGet, http.MethodHead, instancePath, g.headGetHandler(modelInfo))
+	}
+	if modelInfo.verbEnabled(http.MethodPost) {
+		g.handle(modelInfo, VerbCreate, http.MethodPost, basePath, g.createHandler(modelInfo))
+		if modelInfo.EnableCSVImport {
+			g.handle(modelInfo, VerbImport, http.MethodPost, fmt.Sprintf("%s/import", basePath), g.importCSVHandler(modelInfo))
+		}
+	}
+	if modelInfo.verbEnabled(http.MethodPut) {
+		g.handle(modelInfo, VerbUpsert, http.MethodPut, basePath, g.upsertHandler(modelInfo))
+		g.handle(modelInfo, VerbUpdate, http.MethodPut, instancePath, g.updateHandler(modelInfo))
+	}
+	if modelInfo.verbEnabled(http.MethodPatch) {
+		g.handle(modelInfo, VerbUpdate, http.MethodPatch, instancePath, g.mergePatchHandler(modelInfo))
+	}
+	if modelInfo.verbEnabled(http.MethodPatch) && modelInfo.EnableBulkPatch && !modelInfo.hasCompositePrimaryKey() {
+		g.handle(modelInfo, VerbBulkPatch, http.MethodPatch, fmt.Sprintf("%s/bulk", basePath), g.bulkPatchHandler(modelInfo))
+	}
+	if modelInfo.verbEnabled(http.MethodDelete) {
+		if modelInfo.EnableBulkDelete && !modelInfo.hasCompositePrimaryKey() {
+			g.handle(modelInfo, VerbBulkDelete, http.MethodDelete, fmt.Sprintf("%s/bulk", basePath), g.bulkDeleteHandler(modelInfo))
+		}
+		g.handle(modelInfo, VerbDelete, http.MethodDelete, instancePath, g.deleteHandler(modelInfo))
+		if modelInfo.EnableHardDelete {
+			g.handle(modelInfo, VerbDestroy, http.MethodDelete, fmt.Sprintf("%s/destroy", instancePath), g.destroyHandler(modelInfo))
+		}
+	}
+	if modelInfo.SoftDelete && modelInfo.EnableRestore {
+		g.handle(modelInfo, VerbRestore, http.MethodPost, fmt.Sprintf("%s/restore", instancePath), g.restoreHandler(modelInfo))
+	}
+	g.handleOptions(modelInfo, basePath)
+	g.handleOptions(modelInfo, instancePath)
+	g.handleDisabledVerbs(modelInfo, basePath)
+	g.handleDisabledVerbs(modelInfo, instancePath)
 
-	// Register routes
-	g.Router.GET(basePath, g.listHandler(modelInfo))
-	g.Router.GET(fmt.Sprintf("%s/:id", basePath), g.getHandler(modelInfo))
-	g.Router.POST(basePath, g.createHandler(modelInfo))
-	g.Router.PUT(fmt.Sprintf("%s/:id", basePath), g.updateHandler(modelInfo))
-	g.Router.DELETE(fmt.Sprintf("%s/:id", basePath), g.deleteHandler(modelInfo))
+	// Relationship endpoints address the parent by a single :id
+	if !modelInfo.verbEnabled(http.MethodGet) || modelInfo.hasCompositePrimaryKey() {
+		return
+	}
 
 	// Generate foreign key relationship endpoints
 	for _, fk := range modelInfo.ForeignKeys {
 		if fk.RelatedModel != "" {
-			relatedPath := fmt.Sprintf("%s/:id/%s", basePath, toSnakeCase(fk.RelatedModel))
+			relatedPath := fmt.Sprintf("%s/:id/%s", basePath, fk.routeName())
+
+			// Polymorphic children are looked up through their own model
+			if _, registered := g.Models[fk.RelatedModel]; !registered && fk.RelationType == RelationPolymorphic {
+				log.Warn().Str("model", modelInfo.Type.Name()).Str("related", fk.RelatedModel).Msg("apigen: skipping polymorphic relationship route of unregistered model")
+				continue
+			}
+
+			// A has-one record is read and replaced as a single object
+			if fk.RelationType == RelationHasOne {
+				if !g.RegisteredPaths[relatedPath] {
+					g.handleRelated(modelInfo, fk.RelatedModel, VerbRelated, http.MethodGet, relatedPath, g.relatedOneHandler(modelInfo, fk))
+					if canReplaceRelated(g.Models, modelInfo, fk) {
+						g.handleRelated(modelInfo, fk.RelatedModel, VerbReplaceRelated, http.MethodPut, relatedPath, g.replaceRelatedHandler(modelInfo, fk))
+					}
+					g.RegisteredPaths[relatedPath] = true
+				}
+				continue
+			}
 
 			// Check if this path has already been registered
 			if !g.RegisteredPaths[relatedPath] {
-				g.Router.GET(relatedPath, g.relatedHandler(modelInfo, fk))
+				g.handleRelated(modelInfo, fk.RelatedModel, VerbRelated, http.MethodGet, relatedPath, g.relatedHandler(modelInfo, fk))
+				if canCreateRelated(g.Models, modelInfo, fk) {
+					g.handleRelated(modelInfo, fk.RelatedModel, VerbCreateRelated, http.MethodPost, relatedPath, g.createRelatedHandler(modelInfo, fk))
+				}
+				if canLink(g.Models, modelInfo, fk) {
+					g.handleRelated(modelInfo, fk.RelatedModel, VerbLink, MethodLink, relatedPath+"/:related_id", g.linkHandler(modelInfo, fk, true))
+					g.handleRelated(modelInfo, fk.RelatedModel, VerbUnlink, MethodUnlink, relatedPath+"/:related_id", g.linkHandler(modelInfo, fk, false))
+				}
 				g.RegisteredPaths[relatedPath] = true
 			}
 		}
 	}
 }
 
+// handle registers a model endpoint behind the model's middleware and records it
+// in the generator's routes
+func (g *APIGenerator) handle(modelInfo ModelInfo, verb, method, path string, handler gin.HandlerFunc) {
+	g.handleRelated(modelInfo, "", verb, method, path, handler)
+}
+
+// handleRelated registers a model endpoint like handle, for a route reading or writing
+// the records of the related model as well
+func (g *APIGenerator) handleRelated(modelInfo ModelInfo, relatedModel, verb, method, path string, handler gin.HandlerFunc) {
+	route := RouteInfo{
+		Method:       method,
+		Path:         path,
+		ModelName:    modelInfo.Type.Name(),
+		RelatedModel: relatedModel,
+		Verb:         verb,
+	}
+
+	handlers := []gin.HandlerFunc{g.errorResponder}
+	for _, middleware := range []struct {
+		name    string
+		handler gin.HandlerFunc
+	}{
+		{"versioning", g.versionMiddleware(modelInfo)},
+		{"logging", g.loggingMiddleware(modelInfo)},
+		{"tracing", g.tracingMiddleware(modelInfo, method)},
+		{"metrics", g.metricsMiddleware(modelInfo, method)},
+		{"compression", g.compressionMiddleware(verb)},
+		{"body_limit", g.bodyLimitMiddleware(modelInfo)},
+		{"db_timeout", g.timeoutMiddleware(modelInfo, verb)},
+		{"cache_control", noStoreMiddleware(method)},
+		{"rate_limit", g.rateLimitMiddleware(modelInfo, method)},
+		{"deprecation", g.deprecationMiddleware(modelInfo, method)},
+	} {
+		if middleware.handler != nil {
+			handlers = append(handlers, middleware.handler)
+			route.Middleware = append(route.Middleware, middleware.name)
+		}
+	}
+	for range modelInfo.AuthMiddleware {
+		route.Middleware = append(route.Middleware, "auth")
+	}
+	handlers = append(handlers, modelInfo.AuthMiddleware...)
+	if tenant := g.tenantMiddleware(modelInfo, route); tenant != nil {
+		handlers = append(handlers, tenant)
+		route.Middleware = append(route.Middleware, "tenant")
+	}
+	if policy := g.policyMiddleware(modelInfo, route); policy != nil {
+		handlers = append(handlers, policy)
+		route.Middleware = append(route.Middleware, "policy")
+	}
+	if idempotency := g.idempotencyMiddleware(verb, method); idempotency != nil {
+		handlers = append(handlers, idempotency)
+		route.Middleware = append(route.Middleware, "idempotency")
+	}
+	if cache := g.cacheMiddleware(modelInfo, verb, method); cache != nil {
+		handlers = append(handlers, cache)
+		route.Middleware = append(route.Middleware, "cache")
+	}
+	if breaker := g.circuitBreakerMiddleware(modelInfo, verb); breaker != nil {
+		handlers = append(handlers, breaker)
+		route.Middleware = append(route.Middleware, "circuit_breaker")
+	}
+	handlers = append(handlers, handler)
+
+	g.Group.Handle(method, path, handlers...)
+	g.routes = append(g.routes, route)
+}
+
+// tableName returns the table of a model type, honouring a TableName method
+func tableName(modelType reflect.Type, namer schema.Namer) string {
+	if tabler, ok := reflect.New(modelType).Interface().(schema.Tabler); ok {
+		return tabler.TableName()
+	}
+	return namer.TableName(modelType.Name())
+}
+
+// modelDB returns a database session for the queries a request runs for a model,
+// with the model's scopes applied
+func (g *APIGenerator) modelDB(c *gin.Context, modelInfo ModelInfo) *gorm.DB {
+	return g.dbFromContext(c).Scopes(g.modelScopes(modelInfo)...)
+}
+
+// modelScopes returns the scopes applied to every query of a model: its own scopes,
+// then the tenant filter of models under multi-tenancy
+func (g *APIGenerator) modelScopes(modelInfo ModelInfo) []func(*gorm.DB) *gorm.DB {
+	if !g.MultiTenancy.enforced(modelInfo) {
+		return modelInfo.Scopes
+	}
+	return append(append([]func(*gorm.DB) *gorm.DB(nil), modelInfo.Scopes...), TenantScope(g.MultiTenancy.TenantField))
+}
+
 // Helper functions for converting between naming conventions
+
+// toSnakeCase converts a Go name to snake_case. A run of capitals is one word, which
+// ends before a capital followed by a lowercase letter: UserID becomes user_id and
+// HTTPClient becomes http_client.
 func toSnakeCase(s string) string {
+	runes := []rune(s)
 	var result strings.Builder
-	for i, r := range s {
-		if unicode.IsUpper(r) {
-			if i > 0 {
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 && runes[i-1] != '_' {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
 				result.WriteRune('_')
 			}
-			result.WriteRune(unicode.ToLower(r))
-		} else {
-			result.WriteRune(r)
 		}
+		result.WriteRune(unicode.ToLower(r))
 	}
 	return result.String()
 }
@@ -207,9 +798,25 @@ func toCamelCase(s string) string {
 	return result.String()
 }
 
-func pluralize(s string) string {
+func pluralize(s string, irregular map[string]string) string {
+	// Irregular words are looked up by the last word of snake_case names
+	prefix, word := "", s
+	if i := strings.LastIndex(s, "_"); i >= 0 {
+		prefix, word = s[:i+1], s[i+1:]
+	}
+	if plural, ok := irregular[word]; ok {
+		return prefix + plural
+	}
+
 	// Simple pluralization rules
-	if strings.HasSuffix(s, "y") {
+	if strings.HasSuffix(s, "fe") {
+		return strings.TrimSuffix(s, "fe") + "ves"
+	}
+	if strings.HasSuffix(s, "f") && len(s) > 1 && strings.ContainsRune("aeioul", rune(s[len(s)-2])) {
+		// After a consonant other than l the f is an abbreviation's, e.g. pdf, or doubled
+		return strings.TrimSuffix(s, "f") + "ves"
+	}
+	if strings.HasSuffix(s, "y") && len(s) > 1 && !strings.ContainsRune("aeiou", rune(s[len(s)-2])) {
 		return strings.TrimSuffix(s, "y") + "ies"
 	}
 	if strings.HasSuffix(s, "s") || strings.HasSuffix(s, "x") ||
@@ -220,9 +827,99 @@ func pluralize(s string) string {
 	return s + "s"
 }
 
+// resourceNames resolves the resource and plural names of a model. An explicit
+// resourceName wins over the apigen tag, which wins over the struct name.
+func resourceNames(modelType reflect.Type, resourceName string, irregular map[string]string) (string, string) {
+	tagResource, tagPlural := parseResourceTag(modelType)
+
+	if resourceName == "" {
+		resourceName = tagResource
+	}
+	if resourceName == "" {
+		resourceName = toSnakeCase(modelType.Name())
+	}
+
+	// Only trust the tag's plural when it describes the resource being registered
+	if tagPlural != "" && (tagResource == "" || tagResource == resourceName) {
+		return resourceName, tagPlural
+	}
+	return resourceName, pluralize(resourceName, irregular)
+}
+
+// parseResourceTag reads the resource and plural names from an apigen tag declared
+// on a blank field, e.g. _ struct{} `apigen:"resource:article,plural:articles"`
+func parseResourceTag(modelType reflect.Type) (resource string, plural string) {
+	for i := 0; i < modelType.NumField(); i++ {
+		field := modelType.Field(i)
+		if field.Name != "_" {
+			continue
+		}
+
+		for _, part := range strings.Split(field.Tag.Get("apigen"), ",") {
+			key, value, _ := strings.Cut(strings.TrimSpace(part), ":")
+			switch key {
+			case "resource":
+				resource = value
+			case "plural":
+				plural = value
+			}
+		}
+	}
+	return resource, plural
+}
+
+// searchableFields returns the JSON names of all string fields
+func searchableFields(fields []FieldInfo) []string {
+	var names []string
+	for _, field := range fields {
+		if field.Type.Kind() == reflect.String {
+			names = append(names, field.JSONName)
+		}
+	}
+	return names
+}
+
+// verbEnabled reports whether the routes of an HTTP method are registered for the model
+func (m ModelInfo) verbEnabled(method string) bool {
+	return !slices.ContainsFunc(m.DisabledVerbs, func(verb string) bool {
+		return strings.EqualFold(verb, method)
+	})
+}
+
+// associationByName looks up an association field by its case-insensitive name and
+// returns the name of the struct field
+func (m ModelInfo) associationByName(name string) (string, bool) {
+	for _, fk := range m.ForeignKeys {
+		if fk.RelationshipID == "" && strings.EqualFold(fk.FieldName, name) {
+			return fk.FieldName, true
+		}
+	}
+	return "", false
+}
+
+// fieldByName looks up a field by its Go name
+func (m ModelInfo) fieldByName(name string) (FieldInfo, bool) {
+	for _, field := range m.Fields {
+		if field.Name == name {
+			return field, true
+		}
+	}
+	return FieldInfo{}, false
+}
+
+// fieldByJSONName looks up a field by its JSON name
+func (m ModelInfo) fieldByJSONName(name string) (FieldInfo, bool) {
+	for _, field := range m.Fields {
+		if field.JSONName == name {
+			return field, true
+		}
+	}
+	return FieldInfo{}, false
+}
+
 func isBasicType(t reflect.Type) bool {
-	// Check for time.Time type
-	if t.String() == "time.Time" {
+	// Check for time.Time type, and the soft delete timestamp wrapping one
+	if t.String() == "time.Time" || t == deletedAtType {
 		return true
 	}
 
@@ -234,3 +931,10 @@ func isBasicType(t reflect.Type) bool {
 	}
 	return false
 }
+
+// isJSONObject reports whether a type holds an arbitrary JSON object, i.e. is a
+// map[string]any such as a field backed by a json or jsonb column
+func isJSONObject(t reflect.Type) bool {
+	return t.Kind() == reflect.Map && t.Key().Kind() == reflect.String &&
+		t.Elem().Kind() == reflect.Interface && t.Elem().NumMethod() == 0
+}
@@ -0,0 +1,175 @@
+package apigen
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testUser is the model most tests register
+type testUser struct {
+	ID    uint   `json:"id" gorm:"primaryKey"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// newTestDB opens an in-memory SQLite database private to the test and migrates models
+func newTestDB(t *testing.T, models ...any) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+strings.ReplaceAll(t.Name(), "/", "_")+"?mode=memory&cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	if err := db.AutoMigrate(models...); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+// newTestAPI returns a generator serving the given models on a fresh engine, with
+// setup run between registration and GenerateAPI
+func newTestAPI(t *testing.T, setup func(g *APIGenerator), models ...any) (*APIGenerator, *gin.Engine) {
+	t.Helper()
+	router := gin.New()
+	g := New(newTestDB(t, models...), router)
+	if setup != nil {
+		setup(g)
+	}
+	if len(g.Models) == 0 {
+		for _, model := range models {
+			if err := g.RegisterModelWithOptions(model); err != nil {
+				t.Fatalf("register %T: %v", model, err)
+			}
+		}
+	}
+	if err := g.GenerateAPI("Test API", "1.0.0"); err != nil {
+		t.Fatalf("generate API: %v", err)
+	}
+	return g, router
+}
+
+// serve sends a request to the router and returns the recorded response
+func serve(router http.Handler, method, path, body string, headers ...string) *httptest.ResponseRecorder {
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// decode unmarshals a JSON response body
+func decode[T any](t *testing.T, w *httptest.ResponseRecorder) T {
+	t.Helper()
+	var v T
+	if err := json.Unmarshal(w.Body.Bytes(), &v); err != nil {
+		t.Fatalf("decode %q: %v", w.Body.String(), err)
+	}
+	return v
+}
+
+func TestSearch(t *testing.T) {
+	g, router := newTestAPI(t, nil, &testUser{})
+	g.DB.Create(&[]testUser{{Name: "Alice", Email: "alice@example.com"}, {Name: "Bob", Email: "bob@example.com"}})
+
+	w := serve(router, http.MethodGet, "/api/test_users/search?q=ali", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("search: got %d %s", w.Code, w.Body)
+	}
+	users := decode[[]testUser](t, w)
+	if len(users) != 1 || users[0].Name != "Alice" {
+		t.Errorf("search ali: got %+v", users)
+	}
+
+	if w := serve(router, http.MethodGet, "/api/test_users/search?q=a", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("search term under MinQueryLength: got %d, want 400", w.Code)
+	}
+	// The length is counted in characters, not bytes
+	if w := serve(router, http.MethodGet, "/api/test_users/search?q="+url.QueryEscape("€"), ""); w.Code != http.StatusBadRequest {
+		t.Errorf("one multi-byte character: got %d, want 400", w.Code)
+	}
+
+	// Wildcards in the term are matched literally
+	g.DB.Create(&testUser{Name: "100% cotton_fan!", Email: "fan@example.com"})
+	for term, want := range map[string]int{"%%": 0, "_f": 1, "n!": 1, "0%": 1, "a_i": 0, "__": 0} {
+		users := decode[[]testUser](t, serve(router, http.MethodGet, "/api/test_users/search?q="+url.QueryEscape(term), ""))
+		if len(users) != want {
+			t.Errorf("search %q: got %d users, want %d", term, len(users), want)
+		}
+	}
+}
+
+// testStory names its routes with an apigen tag
+type testStory struct {
+	_     struct{} `apigen:"resource:story,plural:stories"`
+	ID    uint     `json:"id" gorm:"primaryKey"`
+	Title string   `json:"title"`
+}
+
+func TestResourceTag(t *testing.T) {
+	g, router := newTestAPI(t, nil, &testStory{})
+	g.DB.Create(&testStory{Title: "Once upon a time"})
+
+	if w := serve(router, http.MethodGet, "/api/stories/1", ""); w.Code != http.StatusOK {
+		t.Errorf("get: got %d", w.Code)
+	}
+	if info := g.Models["testStory"]; info.ResourceName != "story" || info.PluralName != "stories" {
+		t.Errorf("names: got %s and %s", info.ResourceName, info.PluralName)
+	}
+}
+
+func TestToSnakeCase(t *testing.T) {
+	tests := map[string]string{
+		"User":          "user",
+		"UserName":      "user_name",
+		"UserID":        "user_id",
+		"ID":            "id",
+		"HTTPClient":    "http_client",
+		"XMLParser":     "xml_parser",
+		"APIKey":        "api_key",
+		"UserAPIKey":    "user_api_key",
+		"HTTPServerURL": "http_server_url",
+		"JSONData":      "json_data",
+		"ParseURL":      "parse_url",
+		"OAuth2Token":   "o_auth2_token",
+		"Base64Value":   "base64_value",
+		"UserV2":        "user_v2",
+		"already_done":  "already_done",
+		"Already_Done":  "already_done",
+		"a":             "a",
+		"":              "",
+	}
+	for input, want := range tests {
+		if got := toSnakeCase(input); got != want {
+			t.Errorf("toSnakeCase(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package apigen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// ActorIDKey is the gin context key holding the ID of the user making a request.
+// Authentication middleware should set it for the actor to be recorded in audit entries.
+const ActorIDKey = "apigen.actor_id"
+
+// AuditEntry records a single change made through the API
+type AuditEntry struct {
+	ModelName string
+	RecordID  string
+	Verb      string         // HTTP method of the change
+	Before    map[string]any // Record before the change, nil on create
+	After     map[string]any // Record after the change, nil on delete
+	ActorID   string
+	Timestamp time.Time
+}
+
+// AuditLogger stores the audit trail of changes made through the API
+type AuditLogger interface {
+	Log(ctx context.Context, entry AuditEntry) error
+}
+
+// SetAuditLogger records every create, update and delete in the given audit logger.
+// Entries are logged in the background, so they may arrive out of order.
+func (g *APIGenerator) SetAuditLogger(al AuditLogger) {
+	g.auditLogger = al
+}
+
+// audit sends a change to the audit logger without holding up the response. The
+// instance is the record after the change, or the deleted record on DELETE, and
+// before is its snapshot prior to an update.
+func (g *APIGenerator) audit(c *gin.Context, modelInfo ModelInfo, before map[string]any, instance any) {
+	if g.auditLogger == nil || g.isDryRun(c) {
+		return
+	}
+
+	after := snapshot(instance, modelInfo)
+	if c.Request.Method == http.MethodDelete {
+		before, after = after, nil
+	}
+
+	entry := AuditEntry{
+		ModelName: modelInfo.Type.Name(),
+		RecordID:  recordID(instance, modelInfo),
+		Verb:      c.Request.Method,
+		Before:    before,
+		After:     after,
+		ActorID:   c.GetString(ActorIDKey),
+		Timestamp: time.Now(),
+	}
+
+	// The request context is cancelled once the response is written
+	ctx := context.WithoutCancel(c.Request.Context())
+	go func() {
+		if err := g.auditLogger.Log(ctx, entry); err != nil {
+			log.Error().Err(err).Str("model", entry.ModelName).Str("id", entry.RecordID).Msg("apigen: failed to write audit entry")
+		}
+	}()
+}
+
+// recordID returns the ID of a model instance as a string
+func recordID(instance any, modelInfo ModelInfo) string {
+	id, err := primaryKeyValue(instance, modelInfo)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprint(id)
+}
+
+// snapshot converts a model instance to a map keyed by its API field names
+func snapshot(instance any, modelInfo ModelInfo) map[string]any {
+	values := map[string]any{}
+	data, err := json.Marshal(instance)
+	if err != nil {
+		return values
+	}
+
+	// Keep numbers as json.Number so large IDs survive the round-trip
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	_ = decoder.Decode(&values)
+	modelInfo.renameKeys(values, true)
+	return values
+}
+
+// DefaultInMemoryAuditLogger keeps audit entries in memory, which is mostly useful in tests.
+// The zero value is ready to use.
+type DefaultInMemoryAuditLogger struct {
+	mu      sync.Mutex
+	entries []AuditEntry
+}
+
+// Log appends an entry to the audit trail
+func (l *DefaultInMemoryAuditLogger) Log(_ context.Context, entry AuditEntry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, entry)
+	return nil
+}
+
+// Entries returns a copy of the audit trail in the order it was logged
+func (l *DefaultInMemoryAuditLogger) Entries() []AuditEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]AuditEntry(nil), l.entries...)
+}
@@ -0,0 +1,60 @@
+package apigen
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// waitForEntries polls the in-memory audit logger until it holds n entries
+func waitForEntries(t *testing.T, logger *DefaultInMemoryAuditLogger, n int) []AuditEntry {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		entries := logger.Entries()
+		if len(entries) >= n || time.Now().After(deadline) {
+			if len(entries) != n {
+				t.Fatalf("got %d audit entries, want %d: %+v", len(entries), n, entries)
+			}
+			return entries
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestAuditLogger(t *testing.T) {
+	logger := &DefaultInMemoryAuditLogger{}
+	_, router := newTestAPI(t, func(g *APIGenerator) {
+		g.SetAuditLogger(logger)
+		g.RegisterModelWithOptions(&testUser{}, WithAuthMiddleware(func(c *gin.Context) {
+			c.Set(ActorIDKey, c.GetHeader("X-User"))
+		}))
+	}, &testUser{})
+
+	if w := serve(router, http.MethodPost, "/api/test_users", `{"name":"Ada","email":"ada@example.com"}`, "X-User", "alice"); w.Code != http.StatusCreated {
+		t.Fatalf("create: got %d %s", w.Code, w.Body)
+	}
+	created := waitForEntries(t, logger, 1)[0]
+	if created.ModelName != "testUser" || created.RecordID != "1" || created.Verb != http.MethodPost ||
+		created.ActorID != "alice" || created.Before != nil || created.After["name"] != "Ada" {
+		t.Errorf("create entry: %+v", created)
+	}
+
+	if w := serve(router, http.MethodPatch, "/api/test_users/1", `{"name":"Grace"}`, "X-User", "bob"); w.Code != http.StatusOK {
+		t.Fatalf("patch: got %d %s", w.Code, w.Body)
+	}
+	updated := waitForEntries(t, logger, 2)[1]
+	if updated.Verb != http.MethodPatch || updated.ActorID != "bob" || updated.Before["name"] != "Ada" || updated.After["name"] != "Grace" {
+		t.Errorf("update entry: %+v", updated)
+	}
+
+	if w := serve(router, http.MethodDelete, "/api/test_users/1", "", "X-User", "bob"); w.Code >= http.StatusBadRequest {
+		t.Fatalf("delete: got %d %s", w.Code, w.Body)
+	}
+	deleted := waitForEntries(t, logger, 3)[2]
+	if deleted.Verb != http.MethodDelete || deleted.Before["name"] != "Grace" || deleted.After != nil {
+		t.Errorf("delete entry: %+v", deleted)
+	}
+}
@@ -0,0 +1,64 @@
+package apigen
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHeader carries the API key checked by NewAPIKeyMiddleware
+const APIKeyHeader = "X-API-Key"
+
+// errorResponderKey is the gin context key holding the function answering the errors
+// of a generated endpoint, for middleware built without the generator such as auth
+const errorResponderKey = "apigen.respond_error"
+
+// errorResponder stores the generator's error responder in the context of a request
+func (g *APIGenerator) errorResponder(c *gin.Context) {
+	c.Set(errorResponderKey, g.respondError)
+	c.Next()
+}
+
+// abortWithError answers an error like the generator serving the request would, with
+// its ErrorFormatter and envelope, or with DefaultErrorFormatter outside its endpoints
+func abortWithError(c *gin.Context, status int, err error) {
+	if value, exists := c.Get(errorResponderKey); exists {
+		if respond, ok := value.(func(*gin.Context, int, error)); ok {
+			respond(c, status, err)
+			return
+		}
+	}
+	_ = c.Error(err)
+	c.AbortWithStatusJSON(status, withRequestID(c, DefaultErrorFormatter(c, status, err)))
+}
+
+// NewAPIKeyMiddleware returns a middleware authenticating requests by their X-API-Key
+// header. validate returns the ID of the actor owning a key, which is stored under
+// ActorIDKey for audit entries, or false to reject the request with 401.
+func NewAPIKeyMiddleware(validate func(key string) (actorID string, ok bool)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(APIKeyHeader)
+		if key == "" {
+			abortWithError(c, http.StatusUnauthorized, errors.New("missing API key"))
+			return
+		}
+
+		actorID, ok := validate(key)
+		if !ok {
+			abortWithError(c, http.StatusUnauthorized, errors.New("invalid API key"))
+			return
+		}
+
+		c.Set(ActorIDKey, actorID)
+		c.Next()
+	}
+}
+
+// WithAuthMiddleware runs the given middleware, e.g. NewAPIKeyMiddleware, before every
+// endpoint of a model, including its custom actions
+func WithAuthMiddleware(middleware ...gin.HandlerFunc) ModelOption {
+	return func(info *ModelInfo) {
+		info.AuthMiddleware = append(info.AuthMiddleware, middleware...)
+	}
+}
@@ -0,0 +1,40 @@
+package apigen
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestAPIKeyMiddleware(t *testing.T) {
+	logger := &DefaultInMemoryAuditLogger{}
+	_, router := newTestAPI(t, func(g *APIGenerator) {
+		g.SetAuditLogger(logger)
+		g.RegisterModelWithOptions(&testUser{}, WithAuthMiddleware(NewAPIKeyMiddleware(func(key string) (string, bool) {
+			return "alice", key == "secret"
+		})))
+		g.RegisterModelWithOptions(&testStory{})
+	}, &testUser{}, &testStory{})
+
+	for _, endpoint := range []struct{ method, path, body string }{
+		{http.MethodGet, "/api/test_users", ""},
+		{http.MethodPost, "/api/test_users", `{"name":"Ada"}`},
+		{http.MethodGet, "/api/test_users/1", ""},
+	} {
+		if w := serve(router, endpoint.method, endpoint.path, endpoint.body); w.Code != http.StatusUnauthorized {
+			t.Errorf("%s %s without a key: got %d, want 401", endpoint.method, endpoint.path, w.Code)
+		}
+		if w := serve(router, endpoint.method, endpoint.path, endpoint.body, APIKeyHeader, "wrong"); w.Code != http.StatusUnauthorized {
+			t.Errorf("%s %s with an invalid key: got %d, want 401", endpoint.method, endpoint.path, w.Code)
+		}
+		if w := serve(router, endpoint.method, endpoint.path, endpoint.body, APIKeyHeader, "secret"); w.Code >= http.StatusBadRequest {
+			t.Errorf("%s %s with a valid key: got %d %s", endpoint.method, endpoint.path, w.Code, w.Body)
+		}
+	}
+
+	if w := serve(router, http.MethodGet, "/api/stories", ""); w.Code != http.StatusOK {
+		t.Errorf("model without auth: got %d", w.Code)
+	}
+	if entry := waitForEntries(t, logger, 1)[0]; entry.ActorID != "alice" {
+		t.Errorf("audit entry actor: got %q, want alice", entry.ActorID)
+	}
+}
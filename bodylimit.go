@@ -0,0 +1,83 @@
+package apigen
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultMaxBodySize is the largest request body accepted by default, 10 MiB
+const defaultMaxBodySize = 10 << 20
+
+// WithMaxBodySize limits the request bodies sent to the endpoints of a model to size
+// bytes, overriding APIGenerator.MaxBodySize. A negative size lifts the limit.
+func WithMaxBodySize(size int64) ModelOption {
+	return func(info *ModelInfo) {
+		info.MaxBodySize = size
+	}
+}
+
+// maxBodySize returns the request body limit of a model, 0 when there is none
+func (g *APIGenerator) maxBodySize(modelInfo ModelInfo) int64 {
+	size := modelInfo.MaxBodySize
+	if size == 0 {
+		size = g.MaxBodySize
+	}
+	return max(size, 0)
+}
+
+// bodyLimitMiddleware returns the middleware limiting the size of the request bodies
+// of a model. Bodies announcing a larger Content-Length are refused up front, others
+// fail to bind once they exceed the limit. It returns nil when there is no limit.
+func (g *APIGenerator) bodyLimitMiddleware(modelInfo ModelInfo) gin.HandlerFunc {
+	limit := g.maxBodySize(modelInfo)
+	if limit == 0 {
+		return nil
+	}
+	return func(c *gin.Context) {
+		if c.Request.ContentLength > limit {
+			g.respondError(c, http.StatusRequestEntityTooLarge, bodyTooLargeError(limit))
+			return
+		}
+		if c.Request.Body != nil {
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		}
+		c.Next()
+	}
+}
+
+// bodyTooLargeError reports a request body over its size limit
+func bodyTooLargeError(limit int64) error {
+	return fmt.Errorf("request body is larger than the limit of %d bytes", limit)
+}
+
+// respondBodyError answers a request whose body couldn't be read: 413 when it is over
+// its size limit, 400 with err otherwise
+func (g *APIGenerator) respondBodyError(c *gin.Context, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		g.respondError(c, http.StatusRequestEntityTooLarge, bodyTooLargeError(tooLarge.Limit))
+		return
+	}
+	g.respondError(c, http.StatusBadRequest, err)
+}
+
+// respondBindError answers a request whose body couldn't be bound to a model,
+// reporting the invalid fields
+func (g *APIGenerator) respondBindError(c *gin.Context, modelInfo ModelInfo, err error) {
+	var tooLarge *http.MaxBytesError
+	if !errors.As(err, &tooLarge) {
+		err = g.validationError(modelInfo, err)
+	}
+	g.respondBodyError(c, err)
+}
+
+// errReader fails every read with err, handing a body read error on to the next reader
+type errReader struct{ err error }
+
+// Read returns the error
+func (r errReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
@@ -0,0 +1,45 @@
+package apigen
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// nameBody returns a JSON body setting the name, exactly size bytes long
+func nameBody(size int) string {
+	return `{"name":"` + strings.Repeat("a", size-len(`{"name":""}`)) + `"}`
+}
+
+func TestMaxBodySize(t *testing.T) {
+	_, router := newTestAPI(t, func(g *APIGenerator) {
+		g.MaxBodySize = 64
+		g.RegisterModelWithOptions(&testUser{})
+		g.RegisterModelWithOptions(&testProject{}, WithMaxBodySize(-1))
+	}, &testUser{}, &testProject{})
+
+	if w := serve(router, http.MethodPost, "/api/test_users", nameBody(64)); w.Code != http.StatusCreated {
+		t.Errorf("at the limit: got %d %s", w.Code, w.Body)
+	}
+	if w := serve(router, http.MethodPost, "/api/test_users", nameBody(65)); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("over the limit: got %d, want 413", w.Code)
+	}
+	if w := serve(router, http.MethodPut, "/api/test_users/1", nameBody(65)); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("update over the limit: got %d, want 413", w.Code)
+	}
+
+	// Bodies without a Content-Length are cut off while reading
+	req := httptest.NewRequest(http.MethodPost, "/api/test_users", strings.NewReader(nameBody(65)))
+	req.Header.Set("Content-Type", "application/json")
+	req.ContentLength = -1
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusRequestEntityTooLarge || !strings.Contains(w.Body.String(), "64 bytes") {
+		t.Errorf("streamed body: got %d %s", w.Code, w.Body)
+	}
+
+	if w := serve(router, http.MethodPost, "/api/test_projects", nameBody(1000)); w.Code != http.StatusCreated {
+		t.Errorf("model without a limit: got %d %s", w.Code, w.Body)
+	}
+}
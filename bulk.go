@@ -0,0 +1,288 @@
+package apigen
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// defaultMaxBulkSize is the largest number of records a bulk request may address by default
+const defaultMaxBulkSize = 1000
+
+// WithBulkDelete enables the DELETE /api/{plural}/bulk endpoint of a model
+func WithBulkDelete() ModelOption {
+	return func(info *ModelInfo) {
+		info.EnableBulkDelete = true
+	}
+}
+
+// WithBulkPatch enables the PATCH /api/{plural}/bulk endpoint of a model
+func WithBulkPatch() ModelOption {
+	return func(info *ModelInfo) {
+		info.EnableBulkPatch = true
+	}
+}
+
+// protectedPatchFields are the fields a bulk patch never sets: the primary key and
+// the timestamps maintained by GORM
+var protectedPatchFields = []string{"ID", "CreatedAt", "UpdatedAt", "DeletedAt"}
+
+// bulkKey returns the primary key field of a model as parsed by GORM, which also sees
+// the ID promoted from an embedded gorm.Model
+func (g *APIGenerator) bulkKey(modelInfo ModelInfo) (*schema.Field, error) {
+	stmt := &gorm.Statement{DB: g.DB}
+	if err := stmt.Parse(reflect.New(modelInfo.Type).Interface()); err != nil {
+		return nil, err
+	}
+	if len(stmt.Schema.PrimaryFields) != 1 {
+		return nil, fmt.Errorf("model %s has no single-column primary key", modelInfo.Type.Name())
+	}
+	return stmt.Schema.PrimaryFields[0], nil
+}
+
+// bulkRequest is the body of a bulk request
+type bulkRequest struct {
+	IDs   []any           `json:"ids"`
+	Patch json.RawMessage `json:"patch"` // Fields set by a bulk patch
+}
+
+// decodeBulkRequest reads a bulk request body, keeping numeric IDs exact
+func decodeBulkRequest(c *gin.Context) (bulkRequest, error) {
+	var body bulkRequest
+	decoder := json.NewDecoder(c.Request.Body)
+	decoder.UseNumber()
+	err := decoder.Decode(&body)
+	return body, err
+}
+
+// maxBulkSize returns MaxBulkSize, or defaultMaxBulkSize if it isn't positive, e.g.
+// for an APIGenerator not built by New
+func (g *APIGenerator) maxBulkSize() int {
+	if g.MaxBulkSize <= 0 {
+		return defaultMaxBulkSize
+	}
+	return g.MaxBulkSize
+}
+
+// bulkIDs validates the "ids" list of a bulk request body, converting each ID to the
+// type of the model's primary key
+func (g *APIGenerator) bulkIDs(body bulkRequest, key *schema.Field) ([]any, error) {
+	if len(body.IDs) == 0 {
+		return nil, errors.New("ids must not be empty")
+	}
+	if maxSize := g.maxBulkSize(); len(body.IDs) > maxSize {
+		return nil, fmt.Errorf("at most %d ids can be sent at once", maxSize)
+	}
+
+	ids := make([]any, 0, len(body.IDs))
+	for _, raw := range body.IDs {
+		id, err := parseFilterValue(key.FieldType, fmt.Sprint(raw))
+		if err != nil {
+			return nil, fmt.Errorf("invalid id %v: %w", raw, err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// bulkDeleteHandler returns a handler function for deleting several instances of a model
+// @Summary Delete several model instances
+// @Description Delete the instances with the given IDs. Unknown IDs are reported instead of failing the request.
+// @Tags API
+// @Accept json
+// @Produce json
+// @Param ids body object true "IDs to delete, e.g. {\"ids\": [1, 2, 3]}"
+// @Success 200 {object} map[string]any
+// @Failure 400 {object} map[string]string
+// @Router /api/{model}/bulk [delete]
+func (g *APIGenerator) bulkDeleteHandler(modelInfo ModelInfo) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key, err := g.bulkKey(modelInfo)
+		if err != nil {
+			g.respondError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		body, err := decodeBulkRequest(c)
+		if err != nil {
+			g.respondBodyError(c, err)
+			return
+		}
+		ids, err := g.bulkIDs(body, key)
+		if err != nil {
+			g.respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		if modelInfo.Policy != nil && !bulkAllowed(c, ids, modelInfo.Policy.CanDelete) {
+			g.respondError(c, http.StatusForbidden, errForbidden)
+			return
+		}
+
+		// Create a slice to hold the records being deleted
+		sliceType := reflect.SliceOf(modelInfo.Type)
+		results := reflect.New(sliceType).Interface()
+
+		column := clause.Column{Name: key.DBName}
+		var deleted int64
+		err = g.dbFromContext(c).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Scopes(g.modelScopes(modelInfo)...).Where(clause.IN{Column: column, Values: ids}).Find(results).Error; err != nil {
+				return err
+			}
+			if reflect.ValueOf(results).Elem().Len() == 0 {
+				return nil
+			}
+
+			// Soft deletes are respected for models with a DeletedAt field
+			if g.isDryRun(c) {
+				c.Header(dryRunHeader, "true")
+				deleted = int64(reflect.ValueOf(results).Elem().Len())
+				return tx.Session(&gorm.Session{DryRun: true}).Scopes(g.modelScopes(modelInfo)...).Delete(results).Error
+			}
+			result := tx.Scopes(g.modelScopes(modelInfo)...).Delete(results)
+			deleted = result.RowsAffected
+			return result.Error
+		})
+		if err != nil {
+			g.respondDBError(c, err)
+			return
+		}
+
+		// Report the IDs that didn't match a record
+		records := reflect.ValueOf(results).Elem()
+		found := make(map[string]bool, records.Len())
+		for i := 0; i < records.Len(); i++ {
+			record := records.Index(i).Addr().Interface()
+			found[fmt.Sprint(records.Index(i).FieldByIndex(key.StructField.Index).Interface())] = true
+			g.audit(c, modelInfo, nil, record)
+		}
+		notFound := []any{}
+		for _, id := range ids {
+			if !found[fmt.Sprint(id)] {
+				notFound = append(notFound, id)
+			}
+		}
+
+		c.JSON(http.StatusOK, gin.H{"deleted": deleted, "not_found": notFound})
+	}
+}
+
+// patchProtected reports whether a bulk patch may not set a field: the primary key,
+// GORM's timestamps and the lock version are maintained by the generator, and the
+// read-only fields by the application
+func (m ModelInfo) patchProtected(field FieldInfo) bool {
+	return slices.Contains(protectedPatchFields, field.Name) || m.isReadOnly(field) ||
+		slices.ContainsFunc(m.PrimaryKeyFields, func(pk FieldInfo) bool { return pk.Name == field.Name }) ||
+		(m.LockVersion && field.Name == versionFieldName)
+}
+
+// bulkPatchValues decodes the patch of a bulk request into the columns to update.
+// Only API fields that aren't protected may be patched.
+func (g *APIGenerator) bulkPatchValues(modelInfo ModelInfo, patch json.RawMessage) (map[string]any, error) {
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(patch, &keys); err != nil || len(keys) == 0 {
+		return nil, errors.New("patch must be a non-empty object")
+	}
+
+	// Decode the values into an instance to convert them to the field types
+	instance := reflect.New(modelInfo.Type)
+	if modelInfo.hasRenamedFields() {
+		values := map[string]any{}
+		decoder := json.NewDecoder(bytes.NewReader(patch))
+		decoder.UseNumber()
+		if err := decoder.Decode(&values); err != nil {
+			return nil, err
+		}
+		modelInfo.renameKeys(values, false)
+		data, err := json.Marshal(values)
+		if err != nil {
+			return nil, err
+		}
+		patch = data
+	}
+	if err := json.Unmarshal(patch, instance.Interface()); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]any, len(keys))
+	for key := range keys {
+		field, ok := modelInfo.fieldByJSONName(key)
+		if !ok {
+			return nil, fmt.Errorf("unknown field %q", key)
+		}
+		if modelInfo.patchProtected(field) {
+			return nil, fmt.Errorf("field %q cannot be patched", key)
+		}
+		values[g.columnName(field)] = instance.Elem().FieldByName(field.Name).Interface()
+	}
+	return values, nil
+}
+
+// bulkPatchHandler returns a handler function for updating several instances of a model
+// @Summary Patch several model instances
+// @Description Set the same fields on the instances with the given IDs
+// @Tags API
+// @Accept json
+// @Produce json
+// @Param body body object true "IDs and fields to set, e.g. {\"ids\": [1, 2], \"patch\": {\"status\": \"active\"}}"
+// @Success 200 {object} map[string]int64
+// @Failure 400 {object} map[string]string
+// @Router /api/{model}/bulk [patch]
+func (g *APIGenerator) bulkPatchHandler(modelInfo ModelInfo) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key, err := g.bulkKey(modelInfo)
+		if err != nil {
+			g.respondError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		body, err := decodeBulkRequest(c)
+		if err != nil {
+			g.respondBodyError(c, err)
+			return
+		}
+		ids, err := g.bulkIDs(body, key)
+		if err != nil {
+			g.respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		if modelInfo.Policy != nil && !bulkAllowed(c, ids, modelInfo.Policy.CanUpdate) {
+			g.respondError(c, http.StatusForbidden, errForbidden)
+			return
+		}
+		values, err := g.bulkPatchValues(modelInfo, body.Patch)
+		if err != nil {
+			g.respondError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		// Update the records in one statement
+		column := clause.Column{Name: key.DBName}
+		records := reflect.New(reflect.SliceOf(modelInfo.Type)).Interface()
+		var updated int64
+		err = g.dbFromContext(c).Transaction(func(tx *gorm.DB) error {
+			query := tx.Scopes(g.modelScopes(modelInfo)...).Model(records).Where(clause.IN{Column: column, Values: ids})
+			if g.isDryRun(c) {
+				c.Header(dryRunHeader, "true")
+				return query.Count(&updated).Error
+			}
+			result := query.Updates(values)
+			updated = result.RowsAffected
+			return result.Error
+		})
+		if err != nil {
+			g.respondDBError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"updated": updated})
+	}
+}
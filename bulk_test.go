@@ -0,0 +1,78 @@
+package apigen
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestBulkDelete(t *testing.T) {
+	g, router := newTestAPI(t, func(g *APIGenerator) {
+		if err := g.RegisterModelWithOptions(&testUser{}, WithBulkDelete()); err != nil {
+			t.Fatal(err)
+		}
+	}, &testUser{})
+	g.DB.Create(&[]testUser{{Name: "a"}, {Name: "b"}, {Name: "c"}})
+
+	w := serve(router, http.MethodDelete, "/api/test_users/bulk", `{"ids":[1,2,9]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("bulk delete: got %d %s", w.Code, w.Body)
+	}
+	var count int64
+	g.DB.Model(&testUser{}).Count(&count)
+	if count != 1 {
+		t.Errorf("got %d records left, want 1", count)
+	}
+}
+
+func TestBulkPatch(t *testing.T) {
+	g, router := newTestAPI(t, func(g *APIGenerator) {
+		if err := g.RegisterModelWithOptions(&testUser{}, WithBulkPatch()); err != nil {
+			t.Fatal(err)
+		}
+	}, &testUser{})
+	g.DB.Create(&[]testUser{{Name: "a"}, {Name: "b"}, {Name: "c"}})
+
+	w := serve(router, http.MethodPatch, "/api/test_users/bulk", `{"ids":[1,3],"patch":{"email":"x@example.com"}}`)
+	if body := decode[map[string]int64](t, w); w.Code != http.StatusOK || body["updated"] != 2 {
+		t.Fatalf("bulk patch: got %d %s", w.Code, w.Body)
+	}
+	var users []testUser
+	g.DB.Order("id").Find(&users)
+	if users[0].Email != "x@example.com" || users[1].Email != "" || users[2].Email != "x@example.com" || users[2].Name != "c" {
+		t.Errorf("after bulk patch: %+v", users)
+	}
+
+	for _, body := range []string{
+		`{"ids":[1],"patch":{"id":9}}`,
+		`{"ids":[1],"patch":{"admin":true}}`,
+		`{"ids":[1],"patch":{}}`,
+	} {
+		if w := serve(router, http.MethodPatch, "/api/test_users/bulk", body); w.Code != http.StatusBadRequest {
+			t.Errorf("bulk patch %s: got %d, want 400", body, w.Code)
+		}
+	}
+}
+
+func TestMaxBulkSize(t *testing.T) {
+	ids := `{"ids":[` + strings.TrimSuffix(strings.Repeat("1,", defaultMaxBulkSize+1), ",") + `]}`
+	for _, maxBulkSize := range []int{0, -1, defaultMaxBulkSize} {
+		t.Run(fmt.Sprint(maxBulkSize), func(t *testing.T) {
+			g, router := newTestAPI(t, func(g *APIGenerator) {
+				g.MaxBulkSize = maxBulkSize // As left by a struct literal when 0
+				if err := g.RegisterModelWithOptions(&testUser{}, WithBulkDelete()); err != nil {
+					t.Fatal(err)
+				}
+			}, &testUser{})
+			g.DB.Create(&testUser{Name: "a"})
+
+			if w := serve(router, http.MethodDelete, "/api/test_users/bulk", `{"ids":[1]}`); w.Code != http.StatusOK {
+				t.Errorf("one id: got %d %s", w.Code, w.Body)
+			}
+			if w := serve(router, http.MethodDelete, "/api/test_users/bulk", ids); w.Code != http.StatusBadRequest {
+				t.Errorf("too many ids: got %d, want 400", w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,297 @@
+package apigen
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// cacheHeader tells whether a response was served from the response cache
+	cacheHeader = "X-Cache"
+
+	defaultCacheTTL        = time.Minute
+	defaultCacheMaxEntries = 1000
+)
+
+// Cache stores the responses of cacheable models. Implement it to share the cache
+// across instances, for example in Redis.
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte, ttl time.Duration)
+	Delete(key string)
+	// DeletePrefix removes every entry whose key starts with prefix
+	DeletePrefix(prefix string)
+}
+
+// CacheConfig configures the response cache of the list and get endpoints of the
+// models registered with WithCaching
+type CacheConfig struct {
+	TTL        time.Duration // How long a response is served from the cache, a minute by default
+	MaxEntries int           // Size of the in-memory cache, 1000 by default
+	Store      Cache         // Where responses are cached, NewMemoryCache(MaxEntries) if nil
+}
+
+// WithCaching caches the responses of a model's list and get endpoints, see
+// APIGenerator.ResponseCache. Responses are cached per caller, and any successful
+// write to the model or to a model related to it clears its cache.
+func WithCaching() ModelOption {
+	return func(info *ModelInfo) {
+		info.Cacheable = true
+	}
+}
+
+// memoryCache is an LRU Cache held in process memory
+type memoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	order      *list.List               // Most recently used first
+	entries    map[string]*list.Element // key -> element holding a *memoryCacheEntry
+}
+
+// memoryCacheEntry is a value of memoryCache
+type memoryCacheEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// NewMemoryCache creates an in-memory Cache evicting the least recently used entry
+// once it holds maxEntries
+func NewMemoryCache(maxEntries int) Cache {
+	return &memoryCache{maxEntries: maxEntries, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+// Get implements Cache
+func (m *memoryCache) Get(key string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	element, ok := m.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*memoryCacheEntry)
+	if time.Now().After(entry.expires) {
+		m.remove(element)
+		return nil, false
+	}
+	m.order.MoveToFront(element)
+	return entry.value, true
+}
+
+// Set implements Cache
+func (m *memoryCache) Set(key string, value []byte, ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry := &memoryCacheEntry{key: key, value: value, expires: time.Now().Add(ttl)}
+	if element, ok := m.entries[key]; ok {
+		element.Value = entry
+		m.order.MoveToFront(element)
+		return
+	}
+	m.entries[key] = m.order.PushFront(entry)
+	for m.maxEntries > 0 && m.order.Len() > m.maxEntries {
+		m.remove(m.order.Back())
+	}
+}
+
+// Delete implements Cache
+func (m *memoryCache) Delete(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if element, ok := m.entries[key]; ok {
+		m.remove(element)
+	}
+}
+
+// DeletePrefix implements Cache
+func (m *memoryCache) DeletePrefix(prefix string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key, element := range m.entries {
+		if strings.HasPrefix(key, prefix) {
+			m.remove(element)
+		}
+	}
+}
+
+// remove drops an element, the caller holding the lock
+func (m *memoryCache) remove(element *list.Element) {
+	m.order.Remove(element)
+	delete(m.entries, element.Value.(*memoryCacheEntry).key)
+}
+
+// cachedResponse is a response as stored in the Cache
+type cachedResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+// cacheWriter records the body written to the response
+type cacheWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+// Write implements http.ResponseWriter
+func (w *cacheWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString implements gin.ResponseWriter
+func (w *cacheWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// cacheStore returns the store of the response cache, creating the in-memory one on
+// first use
+func (g *APIGenerator) cacheStore() Cache {
+	if g.ResponseCache.Store == nil {
+		maxEntries := g.ResponseCache.MaxEntries
+		if maxEntries <= 0 {
+			maxEntries = defaultCacheMaxEntries
+		}
+		g.ResponseCache.Store = NewMemoryCache(maxEntries)
+	}
+	return g.ResponseCache.Store
+}
+
+// cacheKeyPrefix is the prefix shared by the cache keys of a model's responses
+func cacheKeyPrefix(modelInfo ModelInfo) string {
+	return modelInfo.Type.Name() + ":"
+}
+
+// cachedModels returns the cacheable models whose responses a write to a model can
+// change: the model itself, and the models related to it, which may preload it
+func (g *APIGenerator) cachedModels(modelInfo ModelInfo) []ModelInfo {
+	name := modelInfo.Type.Name()
+	var models []ModelInfo
+	for _, other := range g.Models {
+		related := other.Type.Name() == name
+		for _, fk := range other.ForeignKeys {
+			related = related || fk.RelatedModel == name
+		}
+		for _, fk := range modelInfo.ForeignKeys {
+			related = related || fk.RelatedModel == other.Type.Name()
+		}
+		if related && other.Cacheable {
+			models = append(models, other)
+		}
+	}
+	if len(models) == 0 && modelInfo.Cacheable {
+		// The model isn't registered, e.g. it is the override of an API version
+		models = append(models, modelInfo)
+	}
+	return models
+}
+
+// invalidateCache drops the cached responses of a model and of the models related to it
+func (g *APIGenerator) invalidateCache(modelInfo ModelInfo) {
+	for _, cached := range g.cachedModels(modelInfo) {
+		g.cacheStore().DeletePrefix(cacheKeyPrefix(cached))
+	}
+}
+
+// cachedHeaders are the response headers kept along with a cached response, those the
+// handlers produce. The others, e.g. CORS headers or the request ID, are set by the
+// middleware for each request, cache hits included.
+var cachedHeaders = []string{
+	"Content-Type", "Content-Disposition", "ETag", "Last-Modified", "Link",
+	totalCountHeader, "Content-Range", "Accept-Ranges", "Warning",
+}
+
+// cacheMiddleware returns the middleware serving the list and get endpoints of a
+// cacheable model from the cache, and clearing the cache after any other write to the
+// model or to the models related to it. It returns nil when no cache is affected and
+// for other read endpoints.
+func (g *APIGenerator) cacheMiddleware(modelInfo ModelInfo, verb, method string) gin.HandlerFunc {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+	default:
+		// Any other method, e.g. LINK, may write, but for the read-only query endpoint
+		if verb == VerbQuery || len(g.cachedModels(modelInfo)) == 0 {
+			return nil
+		}
+		return func(c *gin.Context) {
+			c.Next()
+			if c.Writer.Status() < http.StatusBadRequest && !g.isDryRun(c) {
+				g.invalidateCache(modelInfo)
+			}
+		}
+	}
+	if !modelInfo.Cacheable || (verb != VerbList && verb != VerbGet) {
+		return nil
+	}
+	store := g.cacheStore()
+
+	ttl := g.ResponseCache.TTL
+	if ttl <= 0 {
+		ttl = defaultCacheTTL
+	}
+	return func(c *gin.Context) {
+		// Conditional requests are left to the handler, which may answer 304
+		if c.GetHeader("If-Modified-Since") != "" || c.GetHeader("If-None-Match") != "" {
+			c.Next()
+			return
+		}
+		key := cacheKeyPrefix(modelInfo) + cacheKey(c)
+
+		if data, ok := store.Get(key); ok {
+			var response cachedResponse
+			if err := json.Unmarshal(data, &response); err == nil {
+				for name, values := range response.Header {
+					c.Writer.Header()[name] = values
+				}
+				c.Header(cacheHeader, "HIT")
+				c.Data(response.Status, response.Header.Get("Content-Type"), response.Body)
+				c.Abort()
+				return
+			}
+		}
+
+		writer := &cacheWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Header(cacheHeader, "MISS")
+		c.Next()
+
+		if writer.Status() != http.StatusOK {
+			return
+		}
+		header := http.Header{}
+		for _, name := range cachedHeaders {
+			if values := writer.Header().Values(name); len(values) > 0 {
+				header[name] = slices.Clone(values)
+			}
+		}
+		data, err := json.Marshal(cachedResponse{Status: writer.Status(), Header: header, Body: writer.body.Bytes()})
+		if err == nil {
+			store.Set(key, data, ttl)
+		}
+	}
+}
+
+// cacheKey identifies the response to a request within a model's cache. Responses
+// differ by representation, tenant and caller, whose scopes may filter the records.
+func cacheKey(c *gin.Context) string {
+	credentials := sha256.Sum256([]byte(c.GetHeader("Authorization")))
+	return strings.Join([]string{
+		c.Request.Method, c.Request.URL.RequestURI(), c.GetHeader("Accept"),
+		c.GetString(TenantIDKey), c.GetString(ActorIDKey), hex.EncodeToString(credentials[:]),
+	}, "\n")
+}
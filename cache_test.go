@@ -0,0 +1,128 @@
+package apigen
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// testTag is tagged on testArticles
+type testTag struct {
+	ID   uint   `json:"id" gorm:"primaryKey"`
+	Name string `json:"name"`
+}
+
+// testArticle has a many-to-many association
+type testArticle struct {
+	ID    uint      `json:"id" gorm:"primaryKey"`
+	Title string    `json:"title"`
+	Tags  []testTag `json:"tags" gorm:"many2many:test_article_tags"`
+}
+
+// newCachedArticlesAPI serves cached articles, preloading their tags, and tags
+func newCachedArticlesAPI(t *testing.T, auth ...gin.HandlerFunc) (*APIGenerator, *gin.Engine) {
+	return newTestAPI(t, func(g *APIGenerator) {
+		g.RegisterModelWithOptions(&testArticle{}, WithCaching(), WithLinkUnlink(), WithDefaultPreloads("Tags"), WithAuthMiddleware(auth...))
+		g.RegisterModelWithOptions(&testTag{})
+	}, &testArticle{}, &testTag{})
+}
+
+func TestCacheInvalidation(t *testing.T) {
+	t.Run("link", func(t *testing.T) {
+		g, router := newCachedArticlesAPI(t)
+		g.DB.Create(&testArticle{Title: "Caching"})
+		g.DB.Create(&testTag{Name: "http"})
+
+		serve(router, http.MethodGet, "/api/test_articles/1", "")
+		if w := serve(router, MethodLink, "/api/test_articles/1/tags/1", ""); w.Code >= http.StatusBadRequest {
+			t.Fatalf("link: got %d %s", w.Code, w.Body)
+		}
+		w := serve(router, http.MethodGet, "/api/test_articles/1", "")
+		if article := decode[testArticle](t, w); len(article.Tags) != 1 || w.Header().Get(cacheHeader) != "MISS" {
+			t.Errorf("get after link: %s %+v", w.Header().Get(cacheHeader), article)
+		}
+	})
+
+	t.Run("related", func(t *testing.T) {
+		g, router := newCachedArticlesAPI(t)
+		g.DB.Create(&testArticle{Title: "Caching", Tags: []testTag{{Name: "http"}}})
+
+		serve(router, http.MethodGet, "/api/test_articles/1", "")
+		if w := serve(router, http.MethodPatch, "/api/test_tags/1", `{"name":"cache"}`); w.Code != http.StatusOK {
+			t.Fatalf("patch tag: got %d %s", w.Code, w.Body)
+		}
+		article := decode[testArticle](t, serve(router, http.MethodGet, "/api/test_articles/1", ""))
+		if len(article.Tags) != 1 || article.Tags[0].Name != "cache" {
+			t.Errorf("get after tag update: %+v", article)
+		}
+	})
+}
+
+func TestCacheKey(t *testing.T) {
+	t.Run("caller", func(t *testing.T) {
+		g, router := newCachedArticlesAPI(t, func(c *gin.Context) {
+			c.Set(ActorIDKey, c.GetHeader("X-User"))
+		})
+		g.DB.Create(&testArticle{Title: "Caching"})
+
+		serve(router, http.MethodGet, "/api/test_articles/1", "", "X-User", "alice")
+		if w := serve(router, http.MethodGet, "/api/test_articles/1", "", "X-User", "bob"); w.Header().Get(cacheHeader) != "MISS" {
+			t.Errorf("another caller: got X-Cache %s", w.Header().Get(cacheHeader))
+		}
+		if w := serve(router, http.MethodGet, "/api/test_articles/1", "", "X-User", "alice"); w.Header().Get(cacheHeader) != "HIT" {
+			t.Errorf("same caller: got X-Cache %s", w.Header().Get(cacheHeader))
+		}
+	})
+
+	t.Run("conditional", func(t *testing.T) {
+		g, router := newCachedArticlesAPI(t)
+		g.DB.Create(&testArticle{Title: "Caching"})
+
+		serve(router, http.MethodGet, "/api/test_articles/1", "")
+		if w := serve(router, http.MethodGet, "/api/test_articles/1", "", "If-Modified-Since", "Mon, 02 Jan 2006 15:04:05 GMT"); w.Header().Get(cacheHeader) == "HIT" {
+			t.Error("conditional request served from the cache")
+		}
+	})
+}
+
+func TestCacheHeaders(t *testing.T) {
+	g, router := newTestAPI(t, func(g *APIGenerator) {
+		if err := g.WithCORS(CORSOptions{AllowedOrigins: []string{"https://a.example", "https://b.example"}}); err != nil {
+			t.Fatal(err)
+		}
+		g.Group.Use(RequestIDMiddleware(RequestIDOptions{PropagateToResponse: true}))
+		g.RegisterModelWithOptions(&testArticle{}, WithCaching())
+		g.RegisterModelWithOptions(&testTag{})
+	}, &testArticle{}, &testTag{})
+	g.DB.Create(&testArticle{Title: "Caching"})
+
+	serve(router, http.MethodGet, "/api/test_articles", "", "Origin", "https://a.example", "X-Request-ID", "first")
+	w := serve(router, http.MethodGet, "/api/test_articles", "", "Origin", "https://b.example", "X-Request-ID", "second")
+	if w.Header().Get(cacheHeader) != "HIT" {
+		t.Fatalf("second origin: got X-Cache %s", w.Header().Get(cacheHeader))
+	}
+	// Only the headers of the handler are replayed, those of the middleware are the request's
+	if origin := w.Header().Values("Access-Control-Allow-Origin"); len(origin) != 1 || origin[0] != "https://b.example" {
+		t.Errorf("hit: got Access-Control-Allow-Origin %q", origin)
+	}
+	if id := w.Header().Values("X-Request-ID"); len(id) != 1 || id[0] != "second" {
+		t.Errorf("hit: got X-Request-ID %q", id)
+	}
+	if w.Header().Get("Accept-Ranges") == "" || w.Header().Get("Content-Type") == "" {
+		t.Errorf("hit lost the headers of the handler: %v", w.Header())
+	}
+}
+
+func TestCacheQueryKeepsEntries(t *testing.T) {
+	g, router := newCachedArticlesAPI(t)
+	g.DB.Create(&testArticle{Title: "Caching"})
+
+	serve(router, http.MethodGet, "/api/test_articles/1", "")
+	if w := serve(router, http.MethodPost, "/api/test_articles/query", `{}`); w.Code != http.StatusOK {
+		t.Fatalf("query: got %d %s", w.Code, w.Body)
+	}
+	if w := serve(router, http.MethodGet, "/api/test_articles/1", ""); w.Header().Get(cacheHeader) != "HIT" {
+		t.Errorf("get after query: got X-Cache %s", w.Header().Get(cacheHeader))
+	}
+}
@@ -0,0 +1,80 @@
+package apigen
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Visibilities of a CachePolicy
+const (
+	CachePublic  = "public"   // Browsers and shared caches such as CDNs may store responses
+	CachePrivate = "private"  // Only the client's own cache may store responses
+	CacheNoStore = "no-store" // Responses are never stored
+)
+
+// CachePolicy sets the Cache-Control header of the list and get responses of a model,
+// e.g. "public, max-age=300"
+type CachePolicy struct {
+	MaxAge         time.Duration // How long a response stays fresh
+	Visibility     string        // CachePublic, CachePrivate or CacheNoStore; private if empty
+	MustRevalidate bool          // Whether stale responses must be revalidated before they are used
+}
+
+// Built-in cache policies
+var (
+	// NoCachePolicy keeps responses out of every cache
+	NoCachePolicy = CachePolicy{Visibility: CacheNoStore}
+	// PrivateShortCachePolicy lets the client cache responses for a minute
+	PrivateShortCachePolicy = CachePolicy{MaxAge: time.Minute, Visibility: CachePrivate}
+	// PublicCachePolicy lets browsers and CDNs cache responses for five minutes
+	PublicCachePolicy = CachePolicy{MaxAge: 5 * time.Minute, Visibility: CachePublic}
+)
+
+// WithCachePolicy sets the Cache-Control header of the list and get responses of a
+// model. The responses of the write endpoints are always marked no-store.
+func WithCachePolicy(policy CachePolicy) ModelOption {
+	return func(info *ModelInfo) {
+		info.CachePolicy = &policy
+	}
+}
+
+// header returns the Cache-Control header value of the policy
+func (p CachePolicy) header() string {
+	if p.Visibility == CacheNoStore {
+		return CacheNoStore
+	}
+	visibility := p.Visibility
+	if visibility == "" {
+		visibility = CachePrivate
+	}
+	directives := []string{visibility, fmt.Sprintf("max-age=%d", int64(p.MaxAge/time.Second))}
+	if p.MustRevalidate {
+		directives = append(directives, "must-revalidate")
+	}
+	return strings.Join(directives, ", ")
+}
+
+// setCacheControl sets the Cache-Control header of a read response from the cache
+// policy of the model, if it has one
+func setCacheControl(c *gin.Context, modelInfo ModelInfo) {
+	if modelInfo.CachePolicy != nil {
+		c.Header("Cache-Control", modelInfo.CachePolicy.header())
+	}
+}
+
+// noStoreMiddleware returns the middleware marking the responses of writes no-store,
+// or nil for the methods reading records
+func noStoreMiddleware(method string) gin.HandlerFunc {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return func(c *gin.Context) {
+			c.Header("Cache-Control", CacheNoStore)
+			c.Next()
+		}
+	}
+	return nil
+}
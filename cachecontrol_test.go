@@ -0,0 +1,49 @@
+package apigen
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCachePolicy(t *testing.T) {
+	g, router := newTestAPI(t, func(g *APIGenerator) {
+		g.RegisterModelWithOptions(&testUser{}, WithCachePolicy(PublicCachePolicy))
+		g.RegisterModelWithOptions(&testProject{})
+	}, &testUser{}, &testProject{})
+	g.DB.Create(&testUser{Name: "Ada"})
+	g.DB.Create(&testProject{Name: "Engine"})
+
+	for _, path := range []string{"/api/test_users", "/api/test_users/1"} {
+		if got := serve(router, http.MethodGet, path, "").Header().Get("Cache-Control"); got != "public, max-age=300" {
+			t.Errorf("GET %s: got Cache-Control %q", path, got)
+		}
+	}
+	if got := serve(router, http.MethodPost, "/api/test_users", `{"name":"Grace"}`).Header().Get("Cache-Control"); got != "no-store" {
+		t.Errorf("POST: got Cache-Control %q", got)
+	}
+	if got := serve(router, http.MethodDelete, "/api/test_users/1", "").Header().Get("Cache-Control"); got != "no-store" {
+		t.Errorf("DELETE: got Cache-Control %q", got)
+	}
+	if got := serve(router, http.MethodGet, "/api/test_projects/1", "").Header().Get("Cache-Control"); got != "" {
+		t.Errorf("model without a policy: got Cache-Control %q", got)
+	}
+}
+
+func TestCachePolicyHeader(t *testing.T) {
+	tests := []struct {
+		policy CachePolicy
+		want   string
+	}{
+		{NoCachePolicy, "no-store"},
+		{PrivateShortCachePolicy, "private, max-age=60"},
+		{PublicCachePolicy, "public, max-age=300"},
+		{CachePolicy{MaxAge: 90 * time.Second}, "private, max-age=90"},
+		{CachePolicy{MaxAge: time.Hour, Visibility: CachePublic, MustRevalidate: true}, "public, max-age=3600, must-revalidate"},
+	}
+	for _, test := range tests {
+		if got := test.policy.header(); got != test.want {
+			t.Errorf("%+v: got %q, want %q", test.policy, got, test.want)
+		}
+	}
+}
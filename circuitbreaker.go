@@ -0,0 +1,137 @@
+package apigen
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errCircuitOpen is answered with 503 while the circuit breaker rejects requests
+var errCircuitOpen = errors.New("service temporarily unavailable")
+
+// CircuitBreaker stops requests from reaching a failing database. Allow tells whether a
+// request may proceed, and Record is told whether each allowed request succeeded.
+type CircuitBreaker interface {
+	Allow() bool
+	Record(success bool)
+}
+
+// CircuitState is the state of a DefaultCircuitBreaker
+type CircuitState int
+
+// States of a DefaultCircuitBreaker
+const (
+	CircuitClosed   CircuitState = iota // Requests proceed
+	CircuitOpen                         // Requests are rejected until the recovery timeout passed
+	CircuitHalfOpen                     // A single request probes whether the database recovered
+)
+
+// DefaultCircuitBreaker opens after FailureThreshold consecutive failures and rejects
+// requests for RecoveryTimeout. A probe request is then let through, closing the
+// circuit again if it succeeds and reopening it if it fails.
+type DefaultCircuitBreaker struct {
+	FailureThreshold int
+	RecoveryTimeout  time.Duration
+
+	mu       sync.Mutex
+	state    CircuitState
+	failures int       // Consecutive failures while closed
+	openedAt time.Time // When the circuit last opened
+	probedAt time.Time // When the probe of a half-open circuit was let through, zero if none is pending
+}
+
+// NewCircuitBreaker returns a DefaultCircuitBreaker opening after failureThreshold
+// consecutive failures for recoveryTimeout
+func NewCircuitBreaker(failureThreshold int, recoveryTimeout time.Duration) *DefaultCircuitBreaker {
+	return &DefaultCircuitBreaker{FailureThreshold: failureThreshold, RecoveryTimeout: recoveryTimeout}
+}
+
+// Allow lets requests through while the circuit is closed, and a single probe once the
+// recovery timeout passed. A probe that never recorded its outcome is replaced after
+// another recovery timeout.
+func (b *DefaultCircuitBreaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	switch b.state {
+	case CircuitOpen:
+		if now.Sub(b.openedAt) < b.RecoveryTimeout {
+			return false
+		}
+		b.state = CircuitHalfOpen
+	case CircuitHalfOpen:
+		if !b.probedAt.IsZero() && now.Sub(b.probedAt) < b.RecoveryTimeout {
+			return false
+		}
+	default:
+		return true
+	}
+	b.probedAt = now
+	return true
+}
+
+// Record closes the circuit on success, and counts a failure towards opening it
+func (b *DefaultCircuitBreaker) Record(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if success {
+		b.state, b.failures, b.probedAt = CircuitClosed, 0, time.Time{}
+		return
+	}
+	b.failures++
+	if b.state == CircuitHalfOpen || b.failures >= max(b.FailureThreshold, 1) {
+		b.state, b.failures, b.openedAt, b.probedAt = CircuitOpen, 0, time.Now(), time.Time{}
+	}
+}
+
+// State returns the current state of the circuit
+func (b *DefaultCircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// SetCircuitBreaker guards the endpoints of every model with cb. Call it before
+// GenerateAPI; WithCircuitBreaker overrides it for a model.
+func (g *APIGenerator) SetCircuitBreaker(cb CircuitBreaker) {
+	g.circuitBreaker = cb
+}
+
+// WithCircuitBreaker guards the endpoints of a model with their own circuit breaker,
+// overriding the one set with SetCircuitBreaker
+func WithCircuitBreaker(cb CircuitBreaker) ModelOption {
+	return func(info *ModelInfo) {
+		info.CircuitBreaker = cb
+	}
+}
+
+// circuitBreakerMiddleware returns the middleware answering 503 while the circuit of a
+// model is open, and recording whether the requests let through failed with a 5xx. It
+// returns nil without a circuit breaker, and for event streams, which stay open for as
+// long as the client listens.
+func (g *APIGenerator) circuitBreakerMiddleware(modelInfo ModelInfo, verb string) gin.HandlerFunc {
+	cb := modelInfo.CircuitBreaker
+	if cb == nil {
+		cb = g.circuitBreaker
+	}
+	if cb == nil || verb == VerbStream {
+		return nil
+	}
+	return func(c *gin.Context) {
+		if !cb.Allow() {
+			g.respondError(c, http.StatusServiceUnavailable, errCircuitOpen)
+			return
+		}
+		c.Next()
+
+		// Clients hanging up say nothing about the database
+		if status := c.Writer.Status(); status != statusClientClosedRequest {
+			cb.Record(status < http.StatusInternalServerError)
+		}
+	}
+}
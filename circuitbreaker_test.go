@@ -0,0 +1,86 @@
+package apigen
+
+import (
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	cb := NewCircuitBreaker(3, 50*time.Millisecond)
+	g, router := newTestAPI(t, func(g *APIGenerator) {
+		g.SetCircuitBreaker(cb)
+	}, &testUser{})
+	g.DB.Create(&testUser{Name: "Ada"})
+
+	// Queries fail while the database is down
+	var down atomic.Bool
+	g.DB.Callback().Query().Before("gorm:query").Register("test:database_down", func(db *gorm.DB) {
+		if down.Load() {
+			db.AddError(errors.New("connection refused"))
+		}
+	})
+	defer g.DB.Callback().Query().Remove("test:database_down")
+
+	down.Store(true)
+	for i := 0; i < 3; i++ {
+		if w := serve(router, http.MethodGet, "/api/test_users", ""); w.Code != http.StatusInternalServerError {
+			t.Fatalf("failure %d: got %d, want 500", i+1, w.Code)
+		}
+	}
+	if cb.State() != CircuitOpen {
+		t.Fatalf("got state %d after 3 failures, want open", cb.State())
+	}
+	w := serve(router, http.MethodGet, "/api/test_users", "")
+	if body := decode[map[string]any](t, w); w.Code != http.StatusServiceUnavailable || body["error"] != "service temporarily unavailable" {
+		t.Errorf("open circuit: got %d %v", w.Code, body)
+	}
+
+	// A probe succeeding once the database is back closes the circuit
+	down.Store(false)
+	time.Sleep(60 * time.Millisecond)
+	if w := serve(router, http.MethodGet, "/api/test_users", ""); w.Code != http.StatusOK {
+		t.Errorf("probe: got %d, want 200", w.Code)
+	}
+	if cb.State() != CircuitClosed {
+		t.Errorf("got state %d after the probe, want closed", cb.State())
+	}
+
+	// Client errors don't count as failures
+	for i := 0; i < 5; i++ {
+		serve(router, http.MethodGet, "/api/test_users/99", "")
+	}
+	if cb.State() != CircuitClosed {
+		t.Errorf("got state %d after 404s, want closed", cb.State())
+	}
+}
+
+func TestDefaultCircuitBreaker(t *testing.T) {
+	cb := NewCircuitBreaker(2, 20*time.Millisecond)
+	cb.Record(false)
+	cb.Record(true)
+	cb.Record(false)
+	if !cb.Allow() || cb.State() != CircuitClosed {
+		t.Fatal("a success didn't reset the failure count")
+	}
+	cb.Record(false)
+	if cb.Allow() || cb.State() != CircuitOpen {
+		t.Fatal("the circuit didn't open after 2 consecutive failures")
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if !cb.Allow() || cb.State() != CircuitHalfOpen {
+		t.Fatal("no probe was let through after the recovery timeout")
+	}
+	if cb.Allow() {
+		t.Error("a second probe was let through")
+	}
+	cb.Record(false)
+	if cb.Allow() || cb.State() != CircuitOpen {
+		t.Error("a failed probe didn't reopen the circuit")
+	}
+}
@@ -0,0 +1,128 @@
+// Command apigen-gen generates the registration code of the models of a Go source file,
+// so the API can be set up without listing them by hand:
+//
+//	apigen-gen --input models.go --output api_gen.go --package main
+//
+// Structs annotated with a "// @apigen" comment, or implementing apigen.APIModel, are
+// registered by the generated RegisterAll(db *gorm.DB, r *gin.Engine) function.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"text/template"
+
+	"github.com/Glitchfix/apigen"
+)
+
+// source is the template of the generated file
+var source = template.Must(template.New("api_gen").Parse(`// Code generated by apigen-gen from {{.Source}}; DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"github.com/Glitchfix/apigen"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+{{- if .Import}}
+
+	{{.Qualifier}} "{{.Import}}"
+{{- end}}
+)
+
+// RegisterAll registers the models annotated with @apigen and generates their endpoints
+func RegisterAll(db *gorm.DB, r *gin.Engine) error {
+	apiGen := apigen.New(db, r)
+	models := []any{
+{{- range .Models}}
+		&{{if $.Import}}{{$.Qualifier}}.{{end}}{{.}}{},
+{{- end}}
+	}
+	for _, model := range models {
+		if err := apiGen.RegisterModelWithOptions(model); err != nil {
+			return err
+		}
+	}
+	return apiGen.GenerateAPI({{printf "%q" .Title}}, {{printf "%q" .Version}})
+}
+`))
+
+// config holds the command line flags
+type config struct {
+	Input   string // Go source file declaring the models
+	Output  string // Generated file, standard output if empty
+	Package string // Package of the generated file, the models' package if empty
+	Import  string // Import path of the models, when generating into another package
+	Title   string // Title of the generated API
+	Version string // Version of the generated API
+}
+
+// templateData is passed to the source template
+type templateData struct {
+	config
+	Source    string
+	Qualifier string
+	Models    []string
+}
+
+func main() {
+	var cfg config
+	flag.StringVar(&cfg.Input, "input", "", "Go source file declaring the models (required)")
+	flag.StringVar(&cfg.Output, "output", "", "generated file, standard output if empty")
+	flag.StringVar(&cfg.Package, "package", "", "package of the generated file, the models' package if empty")
+	flag.StringVar(&cfg.Import, "import", "", "import path of the models, when --package differs from their package")
+	flag.StringVar(&cfg.Title, "title", "API", "title of the generated API")
+	flag.StringVar(&cfg.Version, "version", "1.0", "version of the generated API")
+	flag.Parse()
+
+	if err := run(cfg); err != nil {
+		fmt.Fprintln(os.Stderr, "apigen-gen:", err)
+		os.Exit(1)
+	}
+}
+
+// run generates the registration code of the models of cfg.Input
+func run(cfg config) error {
+	if cfg.Input == "" {
+		return errors.New("--input is required")
+	}
+
+	pkgName, models, err := apigen.AnnotatedModels(cfg.Input)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", cfg.Input, err)
+	}
+	if len(models) == 0 {
+		return fmt.Errorf("%s has no struct annotated with // @apigen", cfg.Input)
+	}
+
+	// Models of another package are referenced through their import path
+	if cfg.Package == "" {
+		cfg.Package = pkgName
+	}
+	if cfg.Package == pkgName {
+		cfg.Import = ""
+	} else if cfg.Import == "" {
+		return fmt.Errorf("--import is required to reference the models of package %s from package %s", pkgName, cfg.Package)
+	}
+
+	var buf bytes.Buffer
+	data := templateData{config: cfg, Source: filepath.Base(cfg.Input), Qualifier: pkgName, Models: models}
+	if err := source.Execute(&buf, data); err != nil {
+		return err
+	}
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to format the generated code: %w", err)
+	}
+
+	if cfg.Output == "" {
+		_, err = os.Stdout.Write(formatted)
+		return err
+	}
+	return os.WriteFile(cfg.Output, formatted, 0o644)
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGeneratedCodeBuilds(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the command and its output")
+	}
+
+	// The output is built inside the module, so it compiles against this apigen
+	dir, err := os.MkdirTemp(".", "_generated")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	models, err := os.ReadFile(filepath.Join("testdata", "models.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "models.go"), models, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	binary := filepath.Join(t.TempDir(), "apigen-gen")
+	if out, err := exec.Command("go", "build", "-o", binary, ".").CombinedOutput(); err != nil {
+		t.Fatalf("build apigen-gen: %v\n%s", err, out)
+	}
+	output := filepath.Join(dir, "api_gen.go")
+	if out, err := exec.Command(binary, "--input", filepath.Join(dir, "models.go"), "--output", output).CombinedOutput(); err != nil {
+		t.Fatalf("run apigen-gen: %v\n%s", err, out)
+	}
+
+	generated, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(generated), "&User{}") || strings.Contains(string(generated), "&Note{}") {
+		t.Errorf("generated code registers the wrong models:\n%s", generated)
+	}
+	if !strings.Contains(string(generated), "RegisterModelWithOptions(model)") {
+		t.Errorf("generated code doesn't use RegisterModelWithOptions:\n%s", generated)
+	}
+	if out, err := exec.Command("go", "build", "./"+filepath.ToSlash(dir)).CombinedOutput(); err != nil {
+		t.Fatalf("build generated code: %v\n%s\n%s", err, out, generated)
+	}
+}
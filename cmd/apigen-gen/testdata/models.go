@@ -0,0 +1,14 @@
+package models
+
+// User is registered by the generated code
+// @apigen
+type User struct {
+	ID   uint   `json:"id" gorm:"primaryKey"`
+	Name string `json:"name"`
+}
+
+// Note isn't annotated, so it isn't registered
+type Note struct {
+	ID   uint   `json:"id" gorm:"primaryKey"`
+	Text string `json:"text"`
+}
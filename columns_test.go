@@ -0,0 +1,45 @@
+package apigen
+
+import (
+	"net/http"
+	"testing"
+)
+
+// testLegacyUser relies on gorm column tags instead of json tags
+type testLegacyUser struct {
+	ID       uint   `json:"id" gorm:"primaryKey"`
+	FullName string `gorm:"column:full_name"`
+	Nickname string
+}
+
+func TestUntaggedFields(t *testing.T) {
+	t.Run("included", func(t *testing.T) {
+		g, router := newTestAPI(t, func(g *APIGenerator) {
+			g.IncludeUntaggedFields = true
+		}, &testLegacyUser{})
+
+		if w := serve(router, http.MethodPost, "/api/test_legacy_users", `{"full_name":"Ada Lovelace","nickname":"ada"}`); w.Code != http.StatusCreated {
+			t.Fatalf("create: got %d %s", w.Code, w.Body)
+		}
+		user := decode[map[string]any](t, serve(router, http.MethodGet, "/api/test_legacy_users/1", ""))
+		if user["full_name"] != "Ada Lovelace" || user["nickname"] != "ada" {
+			t.Errorf("get: got %v", user)
+		}
+
+		definitions := NewSwaggerGenerator(g.Models).GenerateModelDefinitions()
+		properties := definitions["testLegacyUser"].(map[string]any)["properties"].(*OrderedProperties)
+		if _, ok := properties.Get("full_name"); !ok {
+			t.Errorf("swagger misses full_name: %v", properties.Keys())
+		}
+	})
+
+	t.Run("excluded", func(t *testing.T) {
+		g, router := newTestAPI(t, nil, &testLegacyUser{})
+		g.DB.Create(&testLegacyUser{FullName: "Ada Lovelace"})
+
+		user := decode[map[string]any](t, serve(router, http.MethodGet, "/api/test_legacy_users/1", ""))
+		if _, ok := user["full_name"]; ok {
+			t.Errorf("untagged field is exposed: %v", user)
+		}
+	})
+}
@@ -0,0 +1,121 @@
+package apigen
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// compressMinSize is the smallest response body worth compressing, in bytes
+const compressMinSize = 1024
+
+// gzipWriters recycles the compressors of finished responses
+var gzipWriters = sync.Pool{
+	New: func() any { return gzip.NewWriter(nil) },
+}
+
+// compressionMiddleware returns the middleware gzipping the responses of clients
+// accepting it, when CompressResponses is set. It returns nil otherwise, and for
+// event streams, which must reach the client as soon as they are written.
+func (g *APIGenerator) compressionMiddleware(verb string) gin.HandlerFunc {
+	if !g.CompressResponses || verb == VerbStream {
+		return nil
+	}
+	return func(c *gin.Context) {
+		c.Writer.Header().Add("Vary", "Accept-Encoding")
+		if c.Request.Method == http.MethodHead || !acceptsGzip(c.GetHeader("Accept-Encoding")) {
+			c.Next()
+			return
+		}
+
+		writer := &gzipWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		defer func() {
+			writer.close()
+			c.Writer = writer.ResponseWriter
+		}()
+		c.Next()
+	}
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows a gzip response
+func acceptsGzip(acceptEncoding string) bool {
+	for _, coding := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(coding, ";")
+		name = strings.TrimSpace(name)
+		if name != "gzip" && name != "*" {
+			continue
+		}
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if weight, err := strconv.ParseFloat(q, 64); err == nil && weight == 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// gzipWriter compresses a response body, unless its first write shows the body is
+// too small, already encoded, or an event stream
+type gzipWriter struct {
+	gin.ResponseWriter
+	compressor *gzip.Writer
+	decided    bool
+}
+
+// Write implements http.ResponseWriter
+func (w *gzipWriter) Write(data []byte) (int, error) {
+	if !w.decided {
+		w.decided = true
+		header := w.Header()
+		if !w.ResponseWriter.Written() && len(data) >= compressMinSize && header.Get("Content-Encoding") == "" &&
+			!strings.HasPrefix(header.Get("Content-Type"), "text/event-stream") {
+			header.Set("Content-Encoding", "gzip")
+			header.Del("Content-Length")
+			w.compressor = gzipWriters.Get().(*gzip.Writer)
+			w.compressor.Reset(w.ResponseWriter)
+		}
+	}
+	if w.compressor == nil {
+		return w.ResponseWriter.Write(data)
+	}
+	return w.compressor.Write(data)
+}
+
+// WriteString implements gin.ResponseWriter
+func (w *gzipWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Flush implements http.Flusher
+func (w *gzipWriter) Flush() {
+	if w.compressor != nil {
+		_ = w.compressor.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// close writes the end of the compressed body
+func (w *gzipWriter) close() {
+	if w.compressor == nil {
+		return
+	}
+	_ = w.compressor.Close()
+	w.compressor.Reset(nil)
+	gzipWriters.Put(w.compressor)
+	w.compressor = nil
+}
+
+// storableHeader returns a copy of the header of a response kept for replay. The
+// body is kept uncompressed, so the encoding of the original response is dropped.
+func storableHeader(header http.Header) http.Header {
+	stored := header.Clone()
+	stored.Del("Content-Encoding")
+	stored.Del("Content-Length")
+	return stored
+}
@@ -0,0 +1,53 @@
+package apigen
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestCompressResponses(t *testing.T) {
+	g, router := newTestAPI(t, func(g *APIGenerator) {
+		g.CompressResponses = true
+	}, &testUser{})
+	users := make([]testUser, 1000)
+	for i := range users {
+		users[i] = testUser{Name: fmt.Sprintf("user %d", i), Email: fmt.Sprintf("user%d@example.com", i)}
+	}
+	g.DB.CreateInBatches(users, 100)
+
+	plain := serve(router, http.MethodGet, "/api/test_users", "")
+	if plain.Header().Get("Content-Encoding") != "" {
+		t.Errorf("without Accept-Encoding: got Content-Encoding %q", plain.Header().Get("Content-Encoding"))
+	}
+	if listed := decode[[]testUser](t, plain); len(listed) != 1000 {
+		t.Fatalf("plain list: got %d users", len(listed))
+	}
+
+	w := serve(router, http.MethodGet, "/api/test_users", "", "Accept-Encoding", "gzip, deflate")
+	if w.Header().Get("Content-Encoding") != "gzip" || w.Header().Get("Vary") != "Accept-Encoding" {
+		t.Fatalf("with Accept-Encoding: got headers %v", w.Header())
+	}
+	if compressed, uncompressed := w.Body.Len(), plain.Body.Len(); compressed*2 > uncompressed {
+		t.Errorf("compressed body is %d bytes, more than half of %d", compressed, uncompressed)
+	}
+	reader, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var listed []testUser
+	if err := json.Unmarshal(data, &listed); err != nil || len(listed) != 1000 {
+		t.Errorf("decompressed list: got %d users, %v", len(listed), err)
+	}
+
+	if w := serve(router, http.MethodGet, "/api/test_users/1", "", "Accept-Encoding", "gzip"); w.Header().Get("Content-Encoding") != "" {
+		t.Errorf("small response was compressed")
+	}
+}
@@ -0,0 +1,65 @@
+package apigen
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ComputedField is a read-only response field computed for every record, e.g. a full
+// name or the number of orders of a customer
+type ComputedField struct {
+	JSONName string
+	// Compute returns the value of the field for an instance of the model. db is bound
+	// to the request context and may be used to query other tables.
+	Compute func(ctx context.Context, db *gorm.DB, instance any) (any, error)
+}
+
+// WithComputedFields adds read-only fields to the responses of a model. They can be
+// selected with the fields query parameter but are never accepted in request bodies.
+func WithComputedFields(fields ...ComputedField) ModelOption {
+	return func(info *ModelInfo) {
+		info.ComputedFields = append(info.ComputedFields, fields...)
+	}
+}
+
+// computeFields returns the computed fields of the records in payload, one map per
+// record in order, or nil when the model has no computed fields
+func (g *APIGenerator) computeFields(c *gin.Context, modelInfo ModelInfo, payload any) ([]map[string]any, error) {
+	if len(modelInfo.ComputedFields) == 0 {
+		return nil, nil
+	}
+
+	var records []any
+	value := reflect.Indirect(reflect.ValueOf(payload))
+	if value.Kind() == reflect.Slice {
+		for i := 0; i < value.Len(); i++ {
+			record := value.Index(i)
+			if record.CanAddr() {
+				record = record.Addr()
+			}
+			records = append(records, record.Interface())
+		}
+	} else {
+		records = append(records, payload)
+	}
+
+	ctx := c.Request.Context()
+	db := g.DB.WithContext(ctx)
+	computed := make([]map[string]any, 0, len(records))
+	for _, record := range records {
+		values := make(map[string]any, len(modelInfo.ComputedFields))
+		for _, field := range modelInfo.ComputedFields {
+			result, err := field.Compute(ctx, db, record)
+			if err != nil {
+				return nil, fmt.Errorf("failed to compute %s: %w", field.JSONName, err)
+			}
+			values[field.JSONName] = result
+		}
+		computed = append(computed, values)
+	}
+	return computed, nil
+}
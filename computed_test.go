@@ -0,0 +1,52 @@
+package apigen
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// testPerson has a computed full_name
+type testPerson struct {
+	ID        uint   `json:"id" gorm:"primaryKey"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+}
+
+func TestComputedFields(t *testing.T) {
+	g, router := newTestAPI(t, func(g *APIGenerator) {
+		g.RegisterModelWithOptions(&testPerson{}, WithComputedFields(ComputedField{
+			JSONName: "full_name",
+			Compute: func(_ context.Context, _ *gorm.DB, instance any) (any, error) {
+				person := instance.(*testPerson)
+				return person.FirstName + " " + person.LastName, nil
+			},
+		}))
+	}, &testPerson{})
+	g.DB.Create(&[]testPerson{{FirstName: "Ada", LastName: "Lovelace"}, {FirstName: "Grace", LastName: "Hopper"}})
+
+	person := decode[map[string]any](t, serve(router, http.MethodGet, "/api/test_people/1", ""))
+	if person["full_name"] != "Ada Lovelace" || person["first_name"] != "Ada" {
+		t.Errorf("get: got %v", person)
+	}
+	people := decode[[]map[string]any](t, serve(router, http.MethodGet, "/api/test_people", ""))
+	if len(people) != 2 || people[1]["full_name"] != "Grace Hopper" {
+		t.Errorf("list: got %v", people)
+	}
+	person = decode[map[string]any](t, serve(router, http.MethodGet, "/api/test_people/1?fields=full_name", ""))
+	if len(person) != 1 || person["full_name"] != "Ada Lovelace" {
+		t.Errorf("selected: got %v", person)
+	}
+
+	swagger := NewSwaggerGenerator(g.Models)
+	request := swagger.GenerateRequestBody(g.Models["testPerson"], true)["properties"].(*OrderedProperties)
+	if _, ok := request.Get("full_name"); ok {
+		t.Errorf("create request schema lists the computed field: %v", request.Keys())
+	}
+	response := swagger.GenerateResponseBody(g.Models["testPerson"])["properties"].(*OrderedProperties)
+	if _, ok := response.Get("full_name"); !ok {
+		t.Errorf("response schema misses the computed field: %v", response.Keys())
+	}
+}
@@ -0,0 +1,155 @@
+package apigen
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// bindingPatterns are the patterns of the binding rules restricting the characters
+// of a string
+var bindingPatterns = map[string]string{
+	"alpha":    "^[a-zA-Z]+$",
+	"alphanum": "^[a-zA-Z0-9]+$",
+	"numeric":  "^[-+]?[0-9]+(?:\\.[0-9]+)?$",
+	"number":   "^[0-9]+$",
+}
+
+// bindingFormats are the Swagger formats of the binding rules validating a string format
+var bindingFormats = map[string]string{
+	"email":    "email",
+	"url":      "uri",
+	"uri":      "uri",
+	"uuid":     "uuid",
+	"uuid4":    "uuid",
+	"datetime": "date-time",
+	"ipv4":     "ipv4",
+	"ipv6":     "ipv6",
+}
+
+// addBindingConstraints adds the JSON Schema keywords matching the binding tag of a
+// field to its schema, e.g. minimum and maximum for binding:"min=18,max=120" on an
+// integer, or minLength and maxLength on a string. Rules without an equivalent, and
+// those after dive, which apply to the elements, are left out.
+func addBindingConstraints(schema map[string]any, field FieldInfo) {
+	if field.Binding == "" {
+		return
+	}
+	t := field.Type
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	// The bound keywords depend on what the rule measures
+	var lower, upper string
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		lower, upper = "minimum", "maximum"
+	case reflect.String:
+		lower, upper = "minLength", "maxLength"
+	case reflect.Slice, reflect.Array:
+		lower, upper = "minItems", "maxItems"
+	}
+	numeric := lower == "minimum"
+
+	for _, rule := range strings.Split(field.Binding, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		if name == "dive" {
+			break
+		}
+		if strings.Contains(rule, "|") {
+			continue // Alternatives can't be expressed as constraints
+		}
+
+		switch name {
+		case "min", "gte":
+			setBound(schema, lower, param, numeric, 0)
+		case "max", "lte":
+			setBound(schema, upper, param, numeric, 0)
+		case "len":
+			setBound(schema, lower, param, numeric, 0)
+			setBound(schema, upper, param, numeric, 0)
+		case "gt":
+			if numeric {
+				setBound(schema, lower, param, true, 0)
+				schema["exclusiveMinimum"] = true
+			} else {
+				setBound(schema, lower, param, false, 1)
+			}
+		case "lt":
+			if numeric {
+				setBound(schema, upper, param, true, 0)
+				schema["exclusiveMaximum"] = true
+			} else {
+				setBound(schema, upper, param, false, -1)
+			}
+		case "oneof":
+			if enum := bindingEnum(param, t); enum != nil {
+				schema["enum"] = enum
+			}
+		default:
+			if pattern, ok := bindingPatterns[name]; ok && t.Kind() == reflect.String {
+				schema["pattern"] = pattern
+			}
+			if format, ok := bindingFormats[name]; ok && t.Kind() == reflect.String {
+				schema["format"] = format
+			}
+		}
+	}
+}
+
+// setBound sets a minimum, maximum or length keyword from a rule parameter shifted by
+// offset, ignoring parameters that aren't numbers
+func setBound(schema map[string]any, keyword, param string, numeric bool, offset int64) {
+	if keyword == "" {
+		return
+	}
+	if numeric {
+		if value, err := strconv.ParseFloat(param, 64); err == nil {
+			schema[keyword] = value
+		}
+		return
+	}
+	if value, err := strconv.ParseInt(param, 10, 64); err == nil {
+		schema[keyword] = max(value+offset, 0)
+	}
+}
+
+// bindingEnum returns the values of a oneof rule, e.g. oneof=red green, as numbers
+// for numeric fields. Values may be quoted to hold spaces: oneof='light blue' red.
+func bindingEnum(param string, t reflect.Type) []any {
+	var values []string
+	for param = strings.TrimSpace(param); param != ""; param = strings.TrimSpace(param) {
+		if strings.HasPrefix(param, "'") {
+			if end := strings.Index(param[1:], "'"); end >= 0 {
+				values = append(values, param[1:end+1])
+				param = param[end+2:]
+				continue
+			}
+		}
+		value, rest, _ := strings.Cut(param, " ")
+		values = append(values, value)
+		param = rest
+	}
+
+	enum := make([]any, 0, len(values))
+	for _, value := range values {
+		switch t.Kind() {
+		case reflect.String:
+			enum = append(enum, value)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			number, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil
+			}
+			enum = append(enum, number)
+		default:
+			return nil
+		}
+	}
+	return enum
+}
@@ -0,0 +1,54 @@
+package apigen
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// testApplicant has binding rules documented as schema constraints
+type testApplicant struct {
+	ID    uint     `json:"id" gorm:"primaryKey"`
+	Age   int      `json:"age" binding:"min=18,max=120"`
+	Name  string   `json:"name" binding:"required,min=2,max=50,alpha"`
+	Color string   `json:"color" binding:"oneof=red 'light blue'"`
+	Score float64  `json:"score" binding:"gt=0"`
+	Email string   `json:"email" binding:"omitempty,email"`
+	Tags  []string `json:"tags" gorm:"serializer:json" binding:"max=3,dive,min=1"`
+}
+
+func TestBindingConstraints(t *testing.T) {
+	g, _ := newTestAPI(t, nil, &testApplicant{})
+
+	data, err := json.Marshal(NewSwaggerGenerator(g.Models).GenerateDocument(SwaggerInfo{Title: "Test API"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var document struct {
+		Definitions map[string]struct {
+			Properties map[string]map[string]any `json:"properties"`
+		} `json:"definitions"`
+	}
+	if err := json.Unmarshal(data, &document); err != nil {
+		t.Fatal(err)
+	}
+	properties := document.Definitions["testApplicant"].Properties
+
+	for field, want := range map[string]map[string]any{
+		"age":   {"type": "integer", "minimum": 18.0, "maximum": 120.0},
+		"name":  {"type": "string", "minLength": 2.0, "maxLength": 50.0, "pattern": "^[a-zA-Z]+$"},
+		"color": {"type": "string", "enum": []any{"red", "light blue"}},
+		"score": {"type": "number", "minimum": 0.0, "exclusiveMinimum": true},
+		"email": {"type": "string", "format": "email"},
+		"tags":  {"type": "array", "maxItems": 3.0},
+	} {
+		for keyword, value := range want {
+			if got := properties[field][keyword]; !reflect.DeepEqual(got, value) {
+				t.Errorf("%s.%s: got %v, want %v", field, keyword, got, value)
+			}
+		}
+	}
+	if _, ok := properties["tags"]["minItems"]; ok {
+		t.Errorf("tags: the rule after dive was applied to the array: %v", properties["tags"])
+	}
+}
@@ -0,0 +1,94 @@
+package apigen
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultCORSMethods are allowed in preflight requests when no methods are configured
+var defaultCORSMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+	http.MethodPatch, http.MethodDelete, http.MethodOptions,
+}
+
+// CORSOptions configures the Cross-Origin Resource Sharing headers of the API
+type CORSOptions struct {
+	AllowedOrigins    []string // Origins allowed to call the API, "*" for any
+	AllowedMethods    []string // Methods allowed in preflight requests, all CRUD methods if empty
+	AllowedHeaders    []string // Headers allowed in preflight requests, the requested ones if empty
+	MaxAge            int      // Seconds a preflight response may be cached, not sent if zero
+	EnableCredentials bool     // Whether browsers may send cookies and credentials
+}
+
+// WithCORS installs a middleware on the router answering preflight requests and setting
+// the Access-Control headers. Call it before GenerateAPI so it applies to every endpoint.
+func (g *APIGenerator) WithCORS(opts CORSOptions) error {
+	if slices.Contains(opts.AllowedOrigins, "*") && opts.EnableCredentials {
+		return errors.New("CORS credentials cannot be enabled when any origin is allowed")
+	}
+
+	g.Group.Use(corsMiddleware(opts))
+	return nil
+}
+
+// corsMiddleware returns the middleware applying the CORS options
+func corsMiddleware(opts CORSOptions) gin.HandlerFunc {
+	anyOrigin := slices.Contains(opts.AllowedOrigins, "*")
+
+	methods := opts.AllowedMethods
+	if len(methods) == 0 {
+		methods = defaultCORSMethods
+	}
+	allowedMethods := strings.ToUpper(strings.Join(methods, ", "))
+	allowedHeaders := strings.Join(opts.AllowedHeaders, ", ")
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+
+		switch {
+		case anyOrigin:
+			c.Header("Access-Control-Allow-Origin", "*")
+		case slices.Contains(opts.AllowedOrigins, origin):
+			c.Header("Access-Control-Allow-Origin", origin)
+			c.Header("Vary", "Origin")
+		case preflight:
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		default:
+			// Let the browser block the response
+			c.Next()
+			return
+		}
+
+		if opts.EnableCredentials {
+			c.Header("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !preflight {
+			c.Next()
+			return
+		}
+
+		c.Header("Access-Control-Allow-Methods", allowedMethods)
+		if allowedHeaders != "" {
+			c.Header("Access-Control-Allow-Headers", allowedHeaders)
+		} else if requested := c.GetHeader("Access-Control-Request-Headers"); requested != "" {
+			c.Header("Access-Control-Allow-Headers", requested)
+		}
+		if opts.MaxAge > 0 {
+			c.Header("Access-Control-Max-Age", strconv.Itoa(opts.MaxAge))
+		}
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
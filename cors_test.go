@@ -0,0 +1,72 @@
+package apigen
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newCORSAPI serves testUsers with the CORS options
+func newCORSAPI(t *testing.T, opts CORSOptions) *gin.Engine {
+	_, router := newTestAPI(t, func(g *APIGenerator) {
+		if err := g.WithCORS(opts); err != nil {
+			t.Fatal(err)
+		}
+	}, &testUser{})
+	return router
+}
+
+func TestCORSPreflight(t *testing.T) {
+	router := newCORSAPI(t, CORSOptions{
+		AllowedOrigins:    []string{"https://app.example.com"},
+		AllowedHeaders:    []string{"Content-Type", "Authorization"},
+		MaxAge:            600,
+		EnableCredentials: true,
+	})
+
+	w := serve(router, http.MethodOptions, "/api/test_users", "",
+		"Origin", "https://app.example.com", "Access-Control-Request-Method", http.MethodPost)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("preflight: got %d %s", w.Code, w.Body)
+	}
+	for header, want := range map[string]string{
+		"Access-Control-Allow-Origin":      "https://app.example.com",
+		"Access-Control-Allow-Methods":     "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS",
+		"Access-Control-Allow-Headers":     "Content-Type, Authorization",
+		"Access-Control-Max-Age":           "600",
+		"Access-Control-Allow-Credentials": "true",
+		"Vary":                             "Origin",
+	} {
+		if got := w.Header().Get(header); got != want {
+			t.Errorf("%s: got %q, want %q", header, got, want)
+		}
+	}
+
+	w = serve(router, http.MethodOptions, "/api/test_users", "",
+		"Origin", "https://evil.example.com", "Access-Control-Request-Method", http.MethodPost)
+	if w.Code != http.StatusForbidden || w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("preflight from another origin: got %d %v", w.Code, w.Header())
+	}
+
+	w = serve(router, http.MethodGet, "/api/test_users", "", "Origin", "https://app.example.com")
+	if w.Code != http.StatusOK || w.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" {
+		t.Errorf("simple request: got %d %v", w.Code, w.Header())
+	}
+}
+
+func TestCORSAnyOrigin(t *testing.T) {
+	router := newCORSAPI(t, CORSOptions{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"get", "post"}})
+
+	w := serve(router, http.MethodOptions, "/api/test_users/1", "",
+		"Origin", "https://app.example.com", "Access-Control-Request-Method", http.MethodGet, "Access-Control-Request-Headers", "X-Custom")
+	if w.Header().Get("Access-Control-Allow-Origin") != "*" || w.Header().Get("Access-Control-Allow-Methods") != "GET, POST" ||
+		w.Header().Get("Access-Control-Allow-Headers") != "X-Custom" {
+		t.Errorf("preflight: got %d %v", w.Code, w.Header())
+	}
+
+	g := New(newTestDB(t), gin.New())
+	if err := g.WithCORS(CORSOptions{AllowedOrigins: []string{"*"}, EnableCredentials: true}); err == nil {
+		t.Error("credentials were enabled for any origin")
+	}
+}
@@ -0,0 +1,111 @@
+package apigen
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// wantsCSV reports whether the client asked for a CSV export of the model
+func (g *APIGenerator) wantsCSV(c *gin.Context, modelInfo ModelInfo) bool {
+	if slices.Contains(g.CSVDisabledModels, modelInfo.Type.Name()) {
+		return false
+	}
+	return strings.Contains(c.GetHeader("Accept"), "text/csv")
+}
+
+// writeCSV writes a slice of model instances, or of maps keyed by the JSON field names,
+// as a CSV attachment. The header row holds the JSON field names; nested structs and
+// slices are encoded as JSON strings.
+func writeCSV(c *gin.Context, modelInfo ModelInfo, results any) error {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	// Write the header row
+	header := make([]string, 0, len(modelInfo.Fields))
+	for _, field := range modelInfo.Fields {
+		header = append(header, field.JSONName)
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	// Write one row per instance
+	rows := reflect.Indirect(reflect.ValueOf(results))
+	for i := 0; i < rows.Len(); i++ {
+		row := reflect.Indirect(rows.Index(i))
+		record := make([]string, 0, len(modelInfo.Fields))
+		for _, field := range modelInfo.Fields {
+			var value reflect.Value
+			if row.Kind() == reflect.Map {
+				value = row.MapIndex(reflect.ValueOf(field.JSONName))
+			} else {
+				value = row.FieldByName(field.Name)
+			}
+			cell, err := csvCell(value)
+			if err != nil {
+				return fmt.Errorf("field %s: %w", field.JSONName, err)
+			}
+			record = append(record, cell)
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.csv"`, modelInfo.PluralName))
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+	return nil
+}
+
+// csvCell formats a single field value for a CSV cell
+func csvCell(v reflect.Value) (string, error) {
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return "", nil
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+
+	// The soft delete timestamp is empty until the record is deleted
+	if deletedAt, ok := v.Interface().(gorm.DeletedAt); ok {
+		if !deletedAt.Valid {
+			return "", nil
+		}
+		v = reflect.ValueOf(deletedAt.Time)
+	}
+
+	// Types such as time.Time and uuid.UUID know their own text representation
+	if marshaler, ok := v.Interface().(encoding.TextMarshaler); ok {
+		text, err := marshaler.MarshalText()
+		return string(text), err
+	}
+
+	if isBasicType(v.Type()) {
+		return fmt.Sprint(v.Interface()), nil
+	}
+
+	// Nested structs, slices and maps are serialised as JSON
+	data, err := json.Marshal(v.Interface())
+	return string(data), err
+}
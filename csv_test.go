@@ -0,0 +1,42 @@
+package apigen
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestCSVExport(t *testing.T) {
+	g, router := newTestAPI(t, nil, &testUser{})
+	g.DB.Create(&[]testUser{{Name: "Alice", Email: "alice@example.com"}, {Name: "Bob, Jr.", Email: "bob@example.com"}})
+
+	w := serve(router, http.MethodGet, "/api/test_users", "", "Accept", "text/csv")
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "text/csv; charset=utf-8" {
+		t.Fatalf("export: got %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+	want := "id,name,email\n1,Alice,alice@example.com\n2,\"Bob, Jr.\",bob@example.com\n"
+	if w.Body.String() != want {
+		t.Errorf("export: got %q, want %q", w.Body, want)
+	}
+
+	g.CSVDisabledModels = []string{"testUser"}
+	if w := serve(router, http.MethodGet, "/api/test_users", "", "Accept", "text/csv"); w.Header().Get("Content-Type") == "text/csv; charset=utf-8" {
+		t.Error("export of a disabled model")
+	}
+}
+
+func TestCSVExportSoftDelete(t *testing.T) {
+	g, router := newTestAPI(t, func(g *APIGenerator) {
+		g.RegisterModelWithOptions(&testComment{}, WithRestore())
+	}, &testComment{})
+	deletedAt := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	g.DB.Create(&[]testComment{{Body: "kept"}, {Body: "gone", DeletedAt: gorm.DeletedAt{Time: deletedAt, Valid: true}}})
+
+	w := serve(router, http.MethodGet, "/api/test_comments?include_deleted=true", "", "Accept", "text/csv")
+	want := "id,body,deleted_at\n1,kept,\n2,gone,2024-05-06T07:08:09Z\n"
+	if w.Code != http.StatusOK || w.Body.String() != want {
+		t.Errorf("export: got %d %q, want %q", w.Code, w.Body, want)
+	}
+}
@@ -0,0 +1,237 @@
+package apigen
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// csvImportBatchSize is the number of rows inserted by each statement of a CSV import
+const csvImportBatchSize = 100
+
+// utf8BOM is the byte order mark some spreadsheets write at the start of a CSV file
+const utf8BOM = "\ufeff"
+
+// CSVImportError describes a row of an imported CSV file that wasn't created
+type CSVImportError struct {
+	Row   int    `json:"row"` // Number of the record in the file, the header being row 1
+	Error string `json:"error"`
+}
+
+// WithCSVImport enables the POST /api/{plural}/import endpoint of a model
+func WithCSVImport() ModelOption {
+	return func(info *ModelInfo) {
+		info.EnableCSVImport = true
+	}
+}
+
+// csvImportRow is a row of an imported CSV file bound to a new instance
+type csvImportRow struct {
+	number   int
+	instance any
+}
+
+// importCSVHandler returns a handler function creating instances of a model from the
+// rows of an uploaded CSV file
+// @Summary Import model instances from CSV
+// @Description Create an instance of a model for each row of a CSV file uploaded in the file field. The header row names the JSON fields of the columns.
+// @Tags API
+// @Accept mpfd
+// @Produce json
+// @Param file formData file true "CSV file with a header row"
+// @Success 200 {object} map[string]any
+// @Failure 400 {object} map[string]string
+// @Failure 413 {object} map[string]string
+// @Router /api/{model}/import [post]
+func (g *APIGenerator) importCSVHandler(modelInfo ModelInfo) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		upload, err := c.FormFile("file")
+		if err != nil {
+			if errors.Is(err, http.ErrMissingFile) {
+				err = errors.New("a CSV file is required in the file field")
+			}
+			g.respondBodyError(c, err)
+			return
+		}
+		file, err := upload.Open()
+		if err != nil {
+			g.respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		defer file.Close()
+
+		// Map the header row to the fields of the model
+		reader := csvReader(file)
+		header, err := reader.Read()
+		if err != nil {
+			if err == io.EOF {
+				err = errors.New("the CSV file is empty")
+			}
+			g.respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		columns, err := g.csvColumns(modelInfo, header)
+		if err != nil {
+			g.respondError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		// Bind every row, noting those that are invalid
+		importErrors := []CSVImportError{}
+		var rows []csvImportRow
+		for number := 2; ; number++ {
+			record, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				importErrors = append(importErrors, CSVImportError{Row: number, Error: parseErr.Err.Error()})
+				continue
+			}
+			if err != nil {
+				g.respondBodyError(c, err)
+				return
+			}
+
+			instance, err := g.bindCSVRow(c, modelInfo, columns, record)
+			if err != nil {
+				importErrors = append(importErrors, CSVImportError{Row: number, Error: err.Error()})
+				continue
+			}
+			rows = append(rows, csvImportRow{number: number, instance: instance})
+		}
+
+		// Create the rows in batches, retrying the rows of a failed batch one by one
+		// to tell which of them the database refused
+		imported := 0
+		for start := 0; start < len(rows); start += csvImportBatchSize {
+			batch := rows[start:min(start+csvImportBatchSize, len(rows))]
+			instances := reflect.MakeSlice(reflect.SliceOf(reflect.PointerTo(modelInfo.Type)), 0, len(batch))
+			for _, row := range batch {
+				instances = reflect.Append(instances, reflect.ValueOf(row.instance))
+			}
+
+			created := batch
+			if err := g.writeDB(c, modelInfo).CreateInBatches(instances.Interface(), csvImportBatchSize).Error; err != nil {
+				created = nil
+				for _, row := range batch {
+					if err := g.writeDB(c, modelInfo).Create(row.instance).Error; err != nil {
+						importErrors = append(importErrors, CSVImportError{Row: row.number, Error: g.dbErrorMessage(err)})
+						continue
+					}
+					created = append(created, row)
+				}
+			}
+
+			for _, row := range created {
+				if err := runHook(modelInfo.Hooks.AfterCreate, c, row.instance); err != nil {
+					importErrors = append(importErrors, CSVImportError{Row: row.number, Error: err.Error()})
+				}
+				g.audit(c, modelInfo, nil, row.instance)
+			}
+			imported += len(created)
+		}
+		if imported > 0 && !g.isDryRun(c) {
+			g.invalidateCache(modelInfo)
+		}
+
+		sort.SliceStable(importErrors, func(i, j int) bool { return importErrors[i].Row < importErrors[j].Row })
+		c.JSON(http.StatusOK, gin.H{"imported": imported, "errors": importErrors})
+	}
+}
+
+// csvReader returns a reader of the CSV records of a file, skipping its byte order
+// mark. Quoted fields and \r\n line endings are handled by encoding/csv.
+func csvReader(file io.Reader) *csv.Reader {
+	buffered := bufio.NewReader(file)
+	if bom, err := buffered.Peek(len(utf8BOM)); err == nil && string(bom) == utf8BOM {
+		_, _ = buffered.Discard(len(utf8BOM))
+	}
+	reader := csv.NewReader(buffered)
+	reader.FieldsPerRecord = -1 // Rows of the wrong length are reported by bindCSVRow
+	return reader
+}
+
+// csvColumns maps the columns of a CSV header row to the fields of a model, matching
+// JSON field names regardless of case. Unknown and read-only columns map to an empty
+// FieldInfo and are ignored, unless StrictSchemaValidation rejects the unknown ones.
+func (g *APIGenerator) csvColumns(modelInfo ModelInfo, header []string) ([]FieldInfo, error) {
+	columns := make([]FieldInfo, len(header))
+	seen := make(map[string]bool, len(header))
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		var field FieldInfo
+		found := false
+		for _, candidate := range modelInfo.Fields {
+			if strings.EqualFold(candidate.JSONName, name) {
+				field, found = candidate, true
+				break
+			}
+		}
+		if !found {
+			if g.StrictSchemaValidation {
+				return nil, fmt.Errorf("column %q is not a field of %s", name, modelInfo.ResourceName)
+			}
+			continue
+		}
+		if seen[field.JSONName] {
+			return nil, fmt.Errorf("column %q appears more than once", name)
+		}
+		seen[field.JSONName] = true
+		if !modelInfo.isReadOnly(field) {
+			columns[i] = field
+		}
+	}
+	return columns, nil
+}
+
+// bindCSVRow binds a CSV record to a new instance of a model, ready to be created
+func (g *APIGenerator) bindCSVRow(c *gin.Context, modelInfo ModelInfo, columns []FieldInfo, record []string) (any, error) {
+	if len(record) != len(columns) {
+		return nil, fmt.Errorf("row has %d columns, the header has %d", len(record), len(columns))
+	}
+
+	values := make(map[string]any, len(columns))
+	for i, field := range columns {
+		if field.Name != "" {
+			values[field.JSONName] = []string{record[i]}
+		}
+	}
+	modelInfo.renameKeys(values, false)
+	form := make(map[string][]string, len(values))
+	for key, value := range values {
+		form[key] = value.([]string)
+	}
+
+	instance := reflect.New(modelInfo.Type).Interface()
+	if err := binding.MapFormWithTag(instance, form, "json"); err != nil {
+		return nil, g.validationError(modelInfo, err)
+	}
+	if err := binding.Validator.ValidateStruct(instance); err != nil {
+		return nil, g.validationError(modelInfo, err)
+	}
+	if err := g.transformRequest(c, modelInfo, instance); err != nil {
+		return nil, err
+	}
+
+	if modelInfo.LockVersion {
+		setVersion(instance, 1)
+	}
+	if err := g.setTenant(c, modelInfo, instance); err != nil {
+		return nil, err
+	}
+	if err := runHook(modelInfo.Hooks.BeforeCreate, c, instance); err != nil {
+		return nil, err
+	}
+	return instance, nil
+}
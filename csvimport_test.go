@@ -0,0 +1,64 @@
+package apigen
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// uploadCSV posts a CSV file to the import endpoint of test accounts
+func uploadCSV(router http.Handler, content string) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	file, _ := form.CreateFormFile("file", "accounts.csv")
+	file.Write([]byte(content))
+	form.Close()
+	return serve(router, http.MethodPost, "/api/test_accounts/import", body.String(), "Content-Type", form.FormDataContentType())
+}
+
+func TestImportCSV(t *testing.T) {
+	g, router := newTestAPI(t, func(g *APIGenerator) {
+		g.RegisterModelWithOptions(&testAccount{}, WithCSVImport())
+	}, &testAccount{})
+
+	content := utf8BOM + "Email,PASSWORD,plan,age\r\n" +
+		"ada@example.com,\"secret, really\",free,36\r\n" +
+		"grace@example.com,password,pro,85\r\n" +
+		"not-an-email,password,free,1\r\n" +
+		"linus@example.com,password,free,54\r\n" +
+		"\"ken@example.com\",password,pro,81\r\n" +
+		"dennis@example.com,password,free,70\r\n"
+	w := uploadCSV(router, content)
+	if w.Code != http.StatusOK {
+		t.Fatalf("got %d %s", w.Code, w.Body)
+	}
+	result := decode[struct {
+		Imported int              `json:"imported"`
+		Errors   []CSVImportError `json:"errors"`
+	}](t, w)
+	if result.Imported != 5 || len(result.Errors) != 1 || result.Errors[0].Row != 4 {
+		t.Errorf("got %+v", result)
+	}
+
+	var accounts []testAccount
+	g.DB.Order("id").Find(&accounts)
+	if len(accounts) != 5 || accounts[0].Email != "ada@example.com" || accounts[0].Password != "secret, really" || accounts[0].Age != 36 {
+		t.Errorf("stored %+v", accounts)
+	}
+
+	if w := uploadCSV(router, "email,Email\r\nada@example.com,ada@example.org\r\n"); w.Code != http.StatusBadRequest {
+		t.Errorf("duplicate column: got %d, want 400", w.Code)
+	}
+	if w := serve(router, http.MethodPost, "/api/test_accounts/import", `{}`); w.Code != http.StatusBadRequest {
+		t.Errorf("missing file: got %d, want 400", w.Code)
+	}
+}
+
+func TestImportCSVDisabled(t *testing.T) {
+	_, router := newTestAPI(t, nil, &testAccount{})
+	if w := uploadCSV(router, "email\r\nada@example.com\r\n"); w.Code != http.StatusNotFound && w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("got %d, want the endpoint to be missing", w.Code)
+	}
+}
@@ -0,0 +1,27 @@
+package apigen
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// TransactionKey is the gin context key of a *gorm.DB transaction the queries of a
+// request run in, e.g. set by a middleware committing the transaction once the
+// request succeeded
+const TransactionKey = "apigen.tx"
+
+// statusClientClosedRequest answers requests whose client went away before their
+// queries finished, as nginx logs them
+const statusClientClosedRequest = 499
+
+// dbFromContext returns a database session bound to the context of a request, so
+// its queries are cancelled with the request, traced, and given its DB timeout.
+// It runs in the request's transaction, if TransactionKey holds one.
+func (g *APIGenerator) dbFromContext(c *gin.Context) *gorm.DB {
+	if value, exists := c.Get(TransactionKey); exists {
+		if tx, ok := value.(*gorm.DB); ok && tx != nil {
+			return tx.WithContext(c.Request.Context())
+		}
+	}
+	return g.DB.WithContext(c.Request.Context())
+}
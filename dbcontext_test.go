@@ -0,0 +1,55 @@
+package apigen
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCancelledRequest(t *testing.T) {
+	g, router := newTestAPI(t, func(g *APIGenerator) {
+		g.RegisterModelWithOptions(&testUser{}, WithScopes(slowScope))
+	}, &testUser{})
+	g.DB.Create(&testUser{Name: "Ada"})
+
+	// The client goes away while the query runs
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	req := httptest.NewRequest(http.MethodGet, "/api/test_users", nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+	start := time.Now()
+	router.ServeHTTP(w, req)
+
+	if w.Code != statusClientClosedRequest {
+		t.Errorf("got %d %s, want 499", w.Code, w.Body)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("the query ran on for %s after the request was cancelled", elapsed)
+	}
+}
+
+func TestTransactionFromContext(t *testing.T) {
+	g, router := newTestAPI(t, func(g *APIGenerator) {
+		// Run every request in a transaction that is rolled back
+		g.Group.Use(func(c *gin.Context) {
+			tx := g.DB.Begin()
+			c.Set(TransactionKey, tx)
+			c.Next()
+			tx.Rollback()
+		})
+	}, &testUser{})
+
+	w := serve(router, http.MethodPost, "/api/test_users", `{"name":"Ada"}`)
+	if user := decode[testUser](t, w); w.Code != http.StatusCreated || user.ID == 0 {
+		t.Fatalf("create: got %d %s", w.Code, w.Body)
+	}
+	var count int64
+	g.DB.Model(&testUser{}).Count(&count)
+	if count != 0 {
+		t.Errorf("got %d users after the rollback, want 0", count)
+	}
+}
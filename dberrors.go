@@ -0,0 +1,125 @@
+package apigen
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// DBErrorClassifier maps an error returned by the database to the status code and
+// message of the response. A zero status code leaves the error a 500.
+type DBErrorClassifier func(err error) (statusCode int, userMessage string)
+
+const (
+	uniqueViolationMessage     = "a record with the same unique fields already exists"
+	foreignKeyViolationMessage = "the record references a missing record, or is still referenced"
+	checkViolationMessage      = "the record breaks a check constraint"
+	notNullViolationMessage    = "a required field is missing"
+)
+
+// sqlStateClasses maps the SQLSTATE codes reported by Postgres drivers to responses
+var sqlStateClasses = map[string]struct {
+	status  int
+	message string
+}{
+	"23505": {http.StatusConflict, uniqueViolationMessage},
+	"23503": {http.StatusUnprocessableEntity, foreignKeyViolationMessage},
+	"23514": {http.StatusUnprocessableEntity, checkViolationMessage},
+	"23502": {http.StatusUnprocessableEntity, notNullViolationMessage},
+}
+
+// DefaultDBErrorClassifier recognizes constraint violations: unique ones answer 409
+// Conflict, foreign key, check and not null ones 422 Unprocessable Entity. It knows
+// GORM's translated errors, Postgres drivers reporting a SQLSTATE, and the messages
+// of MySQL and SQLite.
+func DefaultDBErrorClassifier(err error) (int, string) {
+	var state interface{ SQLState() string }
+	switch {
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return http.StatusConflict, uniqueViolationMessage
+	case errors.Is(err, gorm.ErrForeignKeyViolated):
+		return http.StatusUnprocessableEntity, foreignKeyViolationMessage
+	case errors.Is(err, gorm.ErrCheckConstraintViolated):
+		return http.StatusUnprocessableEntity, checkViolationMessage
+	case errors.As(err, &state):
+		if class, ok := sqlStateClasses[state.SQLState()]; ok {
+			return class.status, class.message
+		}
+		return 0, ""
+	}
+
+	message := err.Error()
+	switch {
+	case strings.Contains(message, "UNIQUE constraint failed"):
+		if columns := sqliteConstraintColumns(message, "UNIQUE constraint failed"); columns != "" {
+			return http.StatusConflict, fmt.Sprintf("a record with the same %s already exists", columns)
+		}
+		return http.StatusConflict, uniqueViolationMessage
+	case strings.Contains(message, "NOT NULL constraint failed"):
+		if columns := sqliteConstraintColumns(message, "NOT NULL constraint failed"); columns != "" {
+			return http.StatusUnprocessableEntity, fmt.Sprintf("%s is required", columns)
+		}
+		return http.StatusUnprocessableEntity, notNullViolationMessage
+	case strings.Contains(message, "Error 1062"):
+		return http.StatusConflict, uniqueViolationMessage
+	case strings.Contains(message, "FOREIGN KEY constraint failed"),
+		strings.Contains(message, "Error 1451"), strings.Contains(message, "Error 1452"):
+		return http.StatusUnprocessableEntity, foreignKeyViolationMessage
+	case strings.Contains(message, "CHECK constraint failed"), strings.Contains(message, "Error 3819"):
+		return http.StatusUnprocessableEntity, checkViolationMessage
+	case strings.Contains(message, "Error 1048"):
+		return http.StatusUnprocessableEntity, notNullViolationMessage
+	}
+	return 0, ""
+}
+
+// sqliteConstraintColumns returns the columns named by a SQLite constraint error, e.g.
+// "email" for "UNIQUE constraint failed: users.email"
+func sqliteConstraintColumns(message, prefix string) string {
+	_, list, ok := strings.Cut(message, prefix+": ")
+	if !ok {
+		return ""
+	}
+	var columns []string
+	for _, column := range strings.Split(list, ", ") {
+		_, name, found := strings.Cut(strings.TrimSpace(column), ".")
+		if !found {
+			name = column
+		}
+		columns = append(columns, name)
+	}
+	return strings.Join(columns, ", ")
+}
+
+// classifyDBError classifies a database error with DBErrorClassifier, or else
+// DefaultDBErrorClassifier
+func (g *APIGenerator) classifyDBError(err error) (int, string) {
+	if g.DBErrorClassifier != nil {
+		return g.DBErrorClassifier(err)
+	}
+	return DefaultDBErrorClassifier(err)
+}
+
+// dbErrorMessage returns the message reported for a failed write, e.g. in the errors
+// of a CSV import
+func (g *APIGenerator) dbErrorMessage(err error) string {
+	if status, message := g.classifyDBError(err); status != 0 {
+		return message
+	}
+	return err.Error()
+}
+
+// respondDBError answers a failed write, with the status and message of the
+// classified database errors and 500 for the others
+func (g *APIGenerator) respondDBError(c *gin.Context, err error) {
+	if status, message := g.classifyDBError(err); status != 0 {
+		_ = c.Error(err) // The driver error is kept for the request logger
+		g.respondError(c, status, errors.New(message))
+		return
+	}
+	g.respondError(c, http.StatusInternalServerError, err)
+}
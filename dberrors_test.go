@@ -0,0 +1,57 @@
+package apigen
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+// testMember has a unique email
+type testMember struct {
+	ID    uint   `json:"id" gorm:"primaryKey"`
+	Email string `json:"email" gorm:"uniqueIndex"`
+	Age   int    `json:"age" gorm:"check:age >= 0"`
+}
+
+func TestDBErrorClassification(t *testing.T) {
+	_, router := newTestAPI(t, nil, &testMember{})
+	serve(router, http.MethodPost, "/api/test_members", `{"email":"ada@example.com"}`)
+
+	w := serve(router, http.MethodPost, "/api/test_members", `{"email":"ada@example.com"}`)
+	if body := decode[map[string]string](t, w); w.Code != http.StatusConflict || body["error"] != "a record with the same email already exists" {
+		t.Errorf("unique violation: got %d %v", w.Code, body)
+	}
+	w = serve(router, http.MethodPost, "/api/test_members", `{"email":"grace@example.com","age":-1}`)
+	if body := decode[map[string]string](t, w); w.Code != http.StatusUnprocessableEntity || body["error"] != checkViolationMessage {
+		t.Errorf("check violation: got %d %v", w.Code, body)
+	}
+}
+
+func TestCustomDBErrorClassifier(t *testing.T) {
+	_, router := newTestAPI(t, func(g *APIGenerator) {
+		g.DBErrorClassifier = func(err error) (int, string) {
+			return http.StatusTeapot, "custom: " + err.Error()
+		}
+	}, &testMember{})
+	serve(router, http.MethodPost, "/api/test_members", `{"email":"ada@example.com"}`)
+
+	if w := serve(router, http.MethodPost, "/api/test_members", `{"email":"ada@example.com"}`); w.Code != http.StatusTeapot {
+		t.Errorf("classified error: got %d %s", w.Code, w.Body)
+	}
+}
+
+func TestDefaultDBErrorClassifier(t *testing.T) {
+	for _, tc := range []struct {
+		message string
+		status  int
+	}{
+		{"Error 1062 (23000): Duplicate entry 'a' for key 'email'", http.StatusConflict},
+		{"Error 1452 (23000): Cannot add or update a child row", http.StatusUnprocessableEntity},
+		{"NOT NULL constraint failed: users.name", http.StatusUnprocessableEntity},
+		{"connection refused", 0},
+	} {
+		if status, _ := DefaultDBErrorClassifier(errors.New(tc.message)); status != tc.status {
+			t.Errorf("%q: got %d, want %d", tc.message, status, tc.status)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package apigen
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// DefaultNow is the default value of a time field resolving to the time of the request
+const DefaultNow = "$now"
+
+// WithDefaultValues sets the values of the fields a create request leaves out, by JSON
+// name, e.g. {"status": "pending", "joined_at": apigen.DefaultNow}. Unlike GORM's
+// default tag, the response and the BeforeCreate hook see them.
+func WithDefaultValues(values map[string]any) ModelOption {
+	return func(info *ModelInfo) {
+		if info.DefaultValues == nil {
+			info.DefaultValues = make(map[string]any, len(values))
+		}
+		for name, value := range values {
+			info.DefaultValues[name] = value
+		}
+	}
+}
+
+// applyDefaults sets the default values of the fields missing from the request keys
+func applyDefaults(modelInfo ModelInfo, instance any, keys map[string]bool) error {
+	v := reflect.ValueOf(instance).Elem()
+	for name, value := range modelInfo.DefaultValues {
+		field, ok := modelInfo.fieldByJSONName(name)
+		if !ok || keys[name] {
+			continue
+		}
+		resolved, err := defaultValue(field.Type, value)
+		if err != nil {
+			return fmt.Errorf("default value of %s: %w", name, err)
+		}
+		v.FieldByName(field.Name).Set(resolved)
+	}
+	return nil
+}
+
+// defaultValue converts a default value to the type of its field
+func defaultValue(t reflect.Type, value any) (reflect.Value, error) {
+	base := t
+	if base.Kind() == reflect.Ptr {
+		base = base.Elem()
+	}
+
+	var v reflect.Value
+	switch {
+	case value == DefaultNow && base == reflect.TypeOf(time.Time{}):
+		v = reflect.ValueOf(time.Now())
+	case value == nil:
+		return reflect.Zero(t), nil
+	default:
+		v = reflect.ValueOf(value)
+		switch {
+		case v.Type().AssignableTo(base):
+		case isNumericKind(v.Kind()) && isNumericKind(base.Kind()), v.Kind() == base.Kind() && v.CanConvert(base):
+			v = v.Convert(base)
+		default:
+			return reflect.Value{}, fmt.Errorf("%T is not assignable to %s", value, t)
+		}
+	}
+
+	if t.Kind() == reflect.Ptr {
+		ptr := reflect.New(base)
+		ptr.Elem().Set(v)
+		return ptr, nil
+	}
+	return v, nil
+}
+
+// isNumericKind tells whether values of a kind are integers or floats
+func isNumericKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
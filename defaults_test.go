@@ -0,0 +1,62 @@
+package apigen
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+type testTicket struct {
+	ID       uint       `json:"id" gorm:"primaryKey"`
+	Title    string     `json:"title"`
+	Status   string     `json:"status"`
+	Priority int        `json:"priority"`
+	OpenedAt time.Time  `json:"opened_at"`
+	ClosedAt *time.Time `json:"closed_at"`
+}
+
+func TestDefaultValues(t *testing.T) {
+	g, router := newTestAPI(t, func(g *APIGenerator) {
+		g.RegisterModelWithOptions(&testTicket{}, WithDefaultValues(map[string]any{
+			"status":    "pending",
+			"priority":  3,
+			"opened_at": DefaultNow,
+		}))
+	}, &testTicket{})
+
+	before := time.Now()
+	w := serve(router, http.MethodPost, "/api/test_tickets", `{"title":"Broken"}`)
+	if ticket := decode[testTicket](t, w); w.Code != http.StatusCreated || ticket.Status != "pending" || ticket.Priority != 3 {
+		t.Fatalf("create: got %d %s", w.Code, w.Body)
+	}
+	var stored testTicket
+	g.DB.First(&stored, 1)
+	if stored.Status != "pending" || stored.Priority != 3 || stored.OpenedAt.Before(before.Add(-time.Second)) || stored.ClosedAt != nil {
+		t.Errorf("stored %+v", stored)
+	}
+
+	// Values sent by the client win, even zero values
+	w = serve(router, http.MethodPost, "/api/test_tickets", `{"title":"Fixed","status":"closed","priority":0}`)
+	if ticket := decode[testTicket](t, w); w.Code != http.StatusCreated || ticket.Status != "closed" || ticket.Priority != 0 {
+		t.Errorf("create with values: got %d %s", w.Code, w.Body)
+	}
+
+	// Updates leave the fields alone
+	w = serve(router, http.MethodPatch, "/api/test_tickets/2", `{"title":"Fixed again"}`)
+	if ticket := decode[testTicket](t, w); w.Code != http.StatusOK || ticket.Status != "closed" {
+		t.Errorf("patch: got %d %s", w.Code, w.Body)
+	}
+}
+
+func TestInvalidDefaultValues(t *testing.T) {
+	g := New(nil, nil)
+	info, err := g.analyzeModel(&testTicket{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	WithDefaultValues(map[string]any{"priority": "high", "owner": "me"})(&info)
+	problems := g.ValidateModelInfo(info)
+	if len(problems) != 2 || problems[0].Field != "owner" || problems[1].Field != "priority" {
+		t.Errorf("got %v", problems)
+	}
+}
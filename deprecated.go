@@ -0,0 +1,116 @@
+package apigen
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// WithDeprecatedFields marks fields of a model as deprecated, mapping their JSON names
+// to the names of the fields replacing them, or to "" if none does. They are flagged
+// in the Swagger document, requests sending them get a Warning header, and responses
+// holding them are logged.
+func WithDeprecatedFields(fields map[string]string) ModelOption {
+	return func(info *ModelInfo) {
+		if info.DeprecatedFields == nil {
+			info.DeprecatedFields = make(map[string]string, len(fields))
+		}
+		for field, replacement := range fields {
+			info.DeprecatedFields[field] = replacement
+		}
+	}
+}
+
+// deprecationMessage tells that a field is deprecated, and what to use instead
+func deprecationMessage(field, replacement string) string {
+	if replacement == "" {
+		return fmt.Sprintf("field '%s' is deprecated", field)
+	}
+	return fmt.Sprintf("field '%s' is deprecated; use '%s' instead", field, replacement)
+}
+
+// deprecatedFieldNames returns the sorted JSON names of the deprecated fields of a model
+func deprecatedFieldNames(modelInfo ModelInfo) []string {
+	names := make([]string, 0, len(modelInfo.DeprecatedFields))
+	for name := range modelInfo.DeprecatedFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// markDeprecated flags the properties of the deprecated fields of a model
+func markDeprecated(properties *OrderedProperties, modelInfo ModelInfo) {
+	for name, replacement := range modelInfo.DeprecatedFields {
+		schema, ok := properties.Get(name)
+		if !ok {
+			continue
+		}
+		if schema, ok := schema.(map[string]any); ok {
+			schema["deprecated"] = true
+			schema["description"] = deprecationMessage(name, replacement)
+		}
+	}
+}
+
+// deprecationMiddleware returns the middleware adding an RFC 7234 Warning header for
+// every deprecated field a request body sends, or nil for the methods without a body
+// and the models without deprecated fields
+func (g *APIGenerator) deprecationMiddleware(modelInfo ModelInfo, method string) gin.HandlerFunc {
+	if len(modelInfo.DeprecatedFields) == 0 {
+		return nil
+	}
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+	default:
+		return nil
+	}
+
+	names := deprecatedFieldNames(modelInfo)
+	return func(c *gin.Context) {
+		keys := g.requestKeys(c)
+		for _, name := range names {
+			if keys[name] {
+				c.Writer.Header().Add("Warning", fmt.Sprintf("199 - %q", deprecationMessage(name, modelInfo.DeprecatedFields[name])))
+			}
+		}
+		c.Next()
+	}
+}
+
+// logDeprecatedFields logs a warning for every deprecated field set in the records of
+// a response, leaving out those fields doesn't keep when it isn't nil
+func logDeprecatedFields(modelInfo ModelInfo, payload any, fields []string) {
+	if len(modelInfo.DeprecatedFields) == 0 {
+		return
+	}
+
+	var records []reflect.Value
+	value := reflect.Indirect(reflect.ValueOf(payload))
+	switch value.Kind() {
+	case reflect.Slice:
+		for i := 0; i < value.Len(); i++ {
+			records = append(records, reflect.Indirect(value.Index(i)))
+		}
+	case reflect.Struct:
+		records = append(records, value)
+	}
+
+	for _, field := range modelInfo.Fields {
+		replacement, deprecated := modelInfo.DeprecatedFields[field.JSONName]
+		if !deprecated || (fields != nil && !slices.Contains(fields, field.JSONName)) {
+			continue
+		}
+		for _, record := range records {
+			if record.Kind() == reflect.Struct && record.Type() == modelInfo.Type && !record.FieldByName(field.Name).IsZero() {
+				log.Warn().Str("model", modelInfo.Type.Name()).Str("field", field.JSONName).Msg(deprecationMessage(field.JSONName, replacement))
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package apigen
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// testListing renamed its name field to title, and dropped its code
+type testListing struct {
+	ID    uint   `json:"id" gorm:"primaryKey"`
+	Title string `json:"title"`
+	Name  string `json:"name"`
+	Code  string `json:"code"`
+}
+
+func TestDeprecatedFields(t *testing.T) {
+	var output bytes.Buffer
+	logger := log.Logger
+	log.Logger = zerolog.New(&output).Level(zerolog.WarnLevel)
+	t.Cleanup(func() { log.Logger = logger })
+
+	g, router := newTestAPI(t, func(g *APIGenerator) {
+		g.RegisterModelWithOptions(&testListing{}, WithDeprecatedFields(map[string]string{"name": "title", "code": ""}))
+	}, &testListing{})
+
+	definitions := NewSwaggerGenerator(g.Models).GenerateModelDefinitions()
+	properties := definitions["testListing"].(map[string]any)["properties"].(*OrderedProperties)
+	name, _ := properties.Get("name")
+	if name := name.(map[string]any); name["deprecated"] != true || name["description"] != "field 'name' is deprecated; use 'title' instead" {
+		t.Errorf("name schema: got %v", name)
+	}
+	if title, _ := properties.Get("title"); title.(map[string]any)["deprecated"] != nil {
+		t.Errorf("title schema: got %v", title)
+	}
+
+	w := serve(router, http.MethodPost, "/api/test_listings", `{"title":"Flat","name":"Flat","code":"F1"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: got %d %s", w.Code, w.Body)
+	}
+	warnings := w.Header().Values("Warning")
+	want := []string{`199 - "field 'code' is deprecated"`, `199 - "field 'name' is deprecated; use 'title' instead"`}
+	if len(warnings) != 2 || warnings[0] != want[0] || warnings[1] != want[1] {
+		t.Errorf("create warnings: got %q, want %q", warnings, want)
+	}
+	if w := serve(router, http.MethodPatch, "/api/test_listings/1", `{"title":"House"}`); len(w.Header().Values("Warning")) != 0 {
+		t.Errorf("patch without deprecated fields: got warnings %q", w.Header().Values("Warning"))
+	}
+
+	output.Reset()
+	serve(router, http.MethodGet, "/api/test_listings/1", "")
+	if !strings.Contains(output.String(), "field 'name' is deprecated; use 'title' instead") {
+		t.Errorf("the deprecated field in the response wasn't logged: %s", output.String())
+	}
+	output.Reset()
+	serve(router, http.MethodGet, "/api/test_listings/1?fields=id,title", "")
+	if output.Len() != 0 {
+		t.Errorf("fields left out of the response were logged: %s", output.String())
+	}
+}
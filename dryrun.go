@@ -0,0 +1,33 @@
+package apigen
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// dryRunHeader switches a single request to dry-run mode, and marks dry-run responses
+const dryRunHeader = "X-Dry-Run"
+
+// isDryRun reports whether the writes of a request must be skipped, either because the
+// generator runs in DryRun mode or because the request sent X-Dry-Run: true
+func (g *APIGenerator) isDryRun(c *gin.Context) bool {
+	if g.DryRun {
+		return true
+	}
+	dryRun, _ := strconv.ParseBool(c.GetHeader(dryRunHeader))
+	return dryRun
+}
+
+// writeDB returns a database session for the writes of a request. In dry-run mode
+// statements are built, and GORM hooks run, but nothing is sent to the database.
+func (g *APIGenerator) writeDB(c *gin.Context, modelInfo ModelInfo) *gorm.DB {
+	db := g.modelDB(c, modelInfo)
+	if !g.isDryRun(c) {
+		return db
+	}
+
+	c.Header(dryRunHeader, "true")
+	return db.Session(&gorm.Session{DryRun: true})
+}
@@ -0,0 +1,48 @@
+package apigen
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestDryRun(t *testing.T) {
+	t.Run("header", func(t *testing.T) {
+		g, router := newTestAPI(t, nil, &testUser{})
+		g.DB.Create(&testUser{Name: "Ada"})
+
+		w := serve(router, http.MethodPost, "/api/test_users", `{"name":"Grace"}`, dryRunHeader, "true")
+		if user := decode[testUser](t, w); w.Code != http.StatusCreated || user.Name != "Grace" || w.Header().Get(dryRunHeader) != "true" {
+			t.Errorf("create: got %d %+v %v", w.Code, user, w.Header())
+		}
+		if w := serve(router, http.MethodPatch, "/api/test_users/1", `{"name":"Grace"}`, dryRunHeader, "true"); w.Code != http.StatusOK {
+			t.Errorf("patch: got %d %s", w.Code, w.Body)
+		}
+		if w := serve(router, http.MethodDelete, "/api/test_users/1", "", dryRunHeader, "true"); w.Code >= http.StatusBadRequest {
+			t.Errorf("delete: got %d %s", w.Code, w.Body)
+		}
+
+		var users []testUser
+		g.DB.Find(&users)
+		if len(users) != 1 || users[0].Name != "Ada" {
+			t.Errorf("dry-run requests changed the table: %+v", users)
+		}
+		if w := serve(router, http.MethodGet, "/api/test_users/1", "", dryRunHeader, "true"); w.Code != http.StatusOK {
+			t.Errorf("get: got %d", w.Code)
+		}
+	})
+
+	t.Run("generator", func(t *testing.T) {
+		g, router := newTestAPI(t, func(g *APIGenerator) {
+			g.DryRun = true
+		}, &testUser{})
+
+		if w := serve(router, http.MethodPost, "/api/test_users", `{"name":"Grace"}`); w.Code != http.StatusCreated {
+			t.Errorf("create: got %d %s", w.Code, w.Body)
+		}
+		var count int64
+		g.DB.Model(&testUser{}).Count(&count)
+		if count != 0 {
+			t.Errorf("got %d rows after a dry-run create, want 0", count)
+		}
+	})
+}
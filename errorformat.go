@@ -0,0 +1,49 @@
+package apigen
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProblemJSONMediaType is the media type of RFC 7807 problem details
+const ProblemJSONMediaType = "application/problem+json"
+
+// ErrorFormatter builds the JSON body of an error response, so errors can match the
+// contract of an existing API or gateway
+type ErrorFormatter func(c *gin.Context, status int, err error) any
+
+// DefaultErrorFormatter answers {"error": "..."}, or {"errors": [...]} listing the
+// invalid fields of a validation error
+func DefaultErrorFormatter(_ *gin.Context, _ int, err error) any {
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return gin.H{"errors": validationErr.Errors}
+	}
+	return gin.H{"error": err.Error()}
+}
+
+// RFC7807ErrorFormatter answers RFC 7807 problem details such as
+// {"type": "about:blank", "title": "Bad Request", "status": 400, "detail": "..."},
+// with an "errors" member listing the invalid fields of a validation error
+func RFC7807ErrorFormatter(c *gin.Context, status int, err error) any {
+	c.Header("Content-Type", ProblemJSONMediaType)
+
+	problem := gin.H{
+		"type":   "about:blank",
+		"title":  http.StatusText(status),
+		"status": status,
+		"detail": err.Error(),
+	}
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		problem["errors"] = validationErr.Errors
+	}
+	return problem
+}
+
+// AWSErrorFormatter answers {"message": "..."} like AWS API Gateway
+func AWSErrorFormatter(_ *gin.Context, _ int, err error) any {
+	return gin.H{"message": err.Error()}
+}
@@ -0,0 +1,52 @@
+package apigen
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestErrorFormatters(t *testing.T) {
+	invalid := `{"email":"nope","password":"12345678","plan":"free"}`
+
+	t.Run("default", func(t *testing.T) {
+		_, router := newTestAPI(t, nil, &testAccount{})
+
+		body := decode[map[string]any](t, serve(router, http.MethodPost, "/api/test_accounts", invalid))
+		if errs, ok := body["errors"].([]any); !ok || len(errs) != 1 {
+			t.Errorf("validation error: got %v", body)
+		}
+		body = decode[map[string]any](t, serve(router, http.MethodGet, "/api/test_accounts/1", ""))
+		if _, ok := body["error"].(string); !ok {
+			t.Errorf("not found: got %v", body)
+		}
+	})
+
+	t.Run("rfc7807", func(t *testing.T) {
+		_, router := newTestAPI(t, func(g *APIGenerator) {
+			g.ErrorFormatter = RFC7807ErrorFormatter
+		}, &testAccount{})
+
+		w := serve(router, http.MethodPost, "/api/test_accounts", invalid)
+		if !strings.HasPrefix(w.Header().Get("Content-Type"), ProblemJSONMediaType) {
+			t.Errorf("Content-Type: got %q", w.Header().Get("Content-Type"))
+		}
+		body := decode[map[string]any](t, w)
+		if body["type"] != "about:blank" || body["title"] != "Bad Request" || body["status"] != float64(http.StatusBadRequest) ||
+			body["detail"] == nil || body["errors"] == nil {
+			t.Errorf("validation error: got %v", body)
+		}
+	})
+
+	t.Run("aws", func(t *testing.T) {
+		_, router := newTestAPI(t, func(g *APIGenerator) {
+			g.ErrorFormatter = AWSErrorFormatter
+		}, &testAccount{})
+
+		w := serve(router, http.MethodPost, "/api/test_accounts", invalid)
+		body := decode[map[string]any](t, w)
+		if _, ok := body["message"].(string); w.Code != http.StatusBadRequest || !ok || len(body) != 1 {
+			t.Errorf("validation error: got %d %v", w.Code, body)
+		}
+	})
+}
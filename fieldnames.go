@@ -0,0 +1,176 @@
+package apigen
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// FieldNameConvention selects how the API names of fields are derived from their
+// json tags
+type FieldNameConvention int
+
+const (
+	// KeepFieldNames exposes fields under their json tag, the default
+	KeepFieldNames FieldNameConvention = iota
+	// CamelCase exposes fields under the camelCase form of their json tag, e.g.
+	// createdAt for created_at
+	CamelCase
+)
+
+// camelCaseKeys converts the keys of the objects in a decoded JSON value to camelCase
+func camelCaseKeys(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		converted := make(map[string]any, len(value))
+		for key, item := range value {
+			converted[toCamelCase(key)] = camelCaseKeys(item)
+		}
+		return converted
+	case []any:
+		for i, item := range value {
+			value[i] = camelCaseKeys(item)
+		}
+		return value
+	}
+	return value
+}
+
+// isAssociationType reports whether a field type holds associated records: a struct
+// other than the basic ones, or a slice or pointer of them
+func isAssociationType(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && !isBasicType(t)
+}
+
+// gormColumn returns the column set in the gorm tag of a field, if any
+func gormColumn(field reflect.StructField) string {
+	return gormTagValue(field, "column")
+}
+
+// derivedJSONName returns the API name of a field without a json tag: the column set
+// in its gorm tag, or else the snake_case field name
+func derivedJSONName(field reflect.StructField) string {
+	if column := gormColumn(field); column != "" {
+		return column
+	}
+	return toSnakeCase(field.Name)
+}
+
+// defaultTagKey is the struct tag naming the fields of models unless TagKey is set
+const defaultTagKey = "json"
+
+// fieldTagKey returns the struct tag naming the fields of models, json if key is empty
+func fieldTagKey(key string) string {
+	if key == "" {
+		return defaultTagKey
+	}
+	return key
+}
+
+// jsonKey returns the key of a field in encoding/json documents
+func jsonKey(field reflect.StructField) string {
+	if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name != "" && name != "-" {
+		return name
+	}
+	return field.Name
+}
+
+// hasRenamedFields reports whether any field is exposed under a derived name that
+// encoding/json doesn't know about
+func (m ModelInfo) hasRenamedFields() bool {
+	if m.FieldNames == CamelCase {
+		return true // Nested associations are renamed too
+	}
+	for _, field := range m.Fields {
+		if field.WireName != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// renameKeys moves the values of renamed fields between their encoding/json and API
+// names. Under CamelCase the keys of nested associations are converted as well.
+func (m ModelInfo) renameKeys(values map[string]any, toAPI bool) {
+	for _, field := range m.Fields {
+		if field.WireName == "" {
+			continue
+		}
+
+		from, to := field.JSONName, field.WireName
+		if toAPI {
+			from, to = to, from
+		}
+		if value, ok := values[from]; ok {
+			delete(values, from)
+			values[to] = value
+		}
+	}
+
+	if m.FieldNames != CamelCase || !toAPI {
+		return
+	}
+	for _, field := range m.Fields {
+		if value, ok := values[field.JSONName]; ok && isAssociationType(field.Type) {
+			values[field.JSONName] = camelCaseKeys(value)
+		}
+	}
+}
+
+// apiPayload returns the response body of an instance or slice of instances, with
+// untagged fields renamed to their API names
+func apiPayload(payload any, modelInfo ModelInfo) any {
+	if !modelInfo.hasRenamedFields() {
+		return payload
+	}
+
+	value := reflect.Indirect(reflect.ValueOf(payload))
+	if value.Kind() == reflect.Slice {
+		items := make([]map[string]any, 0, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			items = append(items, snapshot(value.Index(i).Interface(), modelInfo))
+		}
+		return items
+	}
+	return snapshot(payload, modelInfo)
+}
+
+// bindJSON binds the JSON request body to an instance, accepting untagged fields
+// under their API names. With StrictSchemaValidation, unknown fields are rejected.
+func (g *APIGenerator) bindJSON(c *gin.Context, modelInfo ModelInfo, instance any) error {
+	if !modelInfo.hasRenamedFields() && !g.StrictSchemaValidation {
+		return c.ShouldBindJSON(instance)
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return err
+	}
+
+	values := map[string]any{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&values); err != nil {
+		return err
+	}
+	if g.StrictSchemaValidation {
+		if err := unknownFields(values, modelInfo); err != nil {
+			return err
+		}
+	}
+	modelInfo.renameKeys(values, false)
+
+	data, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+	return binding.JSON.BindBody(data, instance)
+}
@@ -0,0 +1,94 @@
+package apigen
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+)
+
+type testContact struct {
+	ID        uint   `json:"id" gorm:"primaryKey"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+}
+
+func TestCamelCaseFieldNames(t *testing.T) {
+	g, router := newTestAPI(t, func(g *APIGenerator) {
+		g.ConvertFieldNames = CamelCase
+		g.RegisterModelWithOptions(&testContact{})
+	}, &testContact{})
+
+	for _, body := range []string{`{"firstName":"Ada","lastName":"Lovelace"}`, `{"firstName":"Grace","lastName":"Hopper"}`} {
+		if w := serve(router, http.MethodPost, "/api/test_contacts", body); w.Code != http.StatusCreated {
+			t.Fatalf("create: got %d %s", w.Code, w.Body)
+		}
+	}
+	var stored testContact
+	g.DB.First(&stored, 1)
+	if stored.FirstName != "Ada" || stored.LastName != "Lovelace" {
+		t.Errorf("camelCase body stored %+v", stored)
+	}
+
+	w := serve(router, http.MethodGet, "/api/test_contacts?lastName=Hopper", "")
+	contacts := decode[[]map[string]any](t, w)
+	if w.Code != http.StatusOK || len(contacts) != 1 || contacts[0]["firstName"] != "Grace" {
+		t.Fatalf("filter: got %d %v", w.Code, contacts)
+	}
+	if _, ok := contacts[0]["first_name"]; ok {
+		t.Errorf("response kept the snake_case key: %v", contacts[0])
+	}
+
+	w = serve(router, http.MethodGet, "/api/test_contacts?sort=-firstName&fields=firstName", "")
+	contacts = decode[[]map[string]any](t, w)
+	if w.Code != http.StatusOK || len(contacts) != 2 || contacts[0]["firstName"] != "Grace" || len(contacts[0]) != 1 {
+		t.Errorf("sort and fields: got %d %v", w.Code, contacts)
+	}
+	if w := serve(router, http.MethodGet, "/api/test_contacts?sort=first_name", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("snake_case sort: got %d, want 400", w.Code)
+	}
+
+	definitions := NewSwaggerGenerator(g.Models).GenerateModelDefinitions()
+	properties := definitions["testContact"].(map[string]any)["properties"].(*OrderedProperties)
+	if _, ok := properties.Get("firstName"); !ok {
+		t.Errorf("swagger properties: got %v", properties.Keys())
+	}
+}
+
+// testRecipe names its fields with yaml tags only
+type testRecipe struct {
+	ID       uint   `yaml:"id" gorm:"primaryKey"`
+	Title    string `yaml:"recipe_title"`
+	Servings int    `yaml:"serves,omitempty"`
+	Notes    string `yaml:"-"`
+}
+
+func TestTagKey(t *testing.T) {
+	g, router := newTestAPI(t, func(g *APIGenerator) {
+		g.TagKey = "yaml"
+		g.RegisterModelWithOptions(&testRecipe{})
+	}, &testRecipe{})
+
+	definitions := NewSwaggerGenerator(g.Models).GenerateModelDefinitions()
+	keys := definitions["testRecipe"].(map[string]any)["properties"].(*OrderedProperties).Keys()
+	if !slices.Equal(keys, []string{"id", "recipe_title", "serves"}) {
+		t.Errorf("swagger properties: got %v", keys)
+	}
+
+	w := serve(router, http.MethodPost, "/api/test_recipes", `{"recipe_title":"Soup","serves":4}`)
+	if recipe := decode[map[string]any](t, w); w.Code != http.StatusCreated || recipe["recipe_title"] != "Soup" || recipe["serves"] != float64(4) {
+		t.Fatalf("create: got %d %s", w.Code, w.Body)
+	}
+	var stored testRecipe
+	g.DB.First(&stored, 1)
+	if stored.Title != "Soup" || stored.Servings != 4 {
+		t.Errorf("stored %+v", stored)
+	}
+
+	recipes := decode[[]map[string]any](t, serve(router, http.MethodGet, "/api/test_recipes?recipe_title=Soup", ""))
+	if len(recipes) != 1 || recipes[0]["recipe_title"] != "Soup" {
+		t.Errorf("list: got %v", recipes)
+	}
+	if _, ok := recipes[0]["Title"]; ok {
+		t.Errorf("the response kept the Go field name: %v", recipes[0])
+	}
+}
@@ -0,0 +1,162 @@
+package apigen
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// apiFieldNames returns the set of names a model's fields have in the API, including
+// fields promoted from embedded structs such as gorm.Model
+func (m ModelInfo) apiFieldNames() map[string]bool {
+	names := map[string]bool{}
+	for name := range snapshot(reflect.New(m.Type).Interface(), m) {
+		names[name] = true
+	}
+	for _, field := range m.Fields {
+		names[field.JSONName] = true
+	}
+	return names
+}
+
+// parseFields reads the fields the client wants in the response (e.g. "?fields=id,name").
+// It returns nil when every field is wanted, and an error on unknown fields.
+func parseFields(c *gin.Context, modelInfo ModelInfo) ([]string, error) {
+	if c.Query("fields") == "" {
+		return nil, nil
+	}
+
+	known := modelInfo.apiFieldNames()
+	for _, field := range modelInfo.ComputedFields {
+		known[field.JSONName] = true
+	}
+	var fields []string
+	for _, name := range strings.Split(c.Query("fields"), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !known[name] {
+			return nil, fmt.Errorf("unknown field %q", name)
+		}
+		fields = append(fields, name)
+	}
+	return fields, nil
+}
+
+// fieldMaskScope makes a query fetch only the columns of the given fields, along with
+// the primary and foreign keys that responses and preloads rely on. Every column is
+// fetched when fields is nil, the model has computed fields, which may read any of
+// them, or a field isn't found in the model's schema.
+func (g *APIGenerator) fieldMaskScope(modelInfo ModelInfo, fields []string) func(*gorm.DB) *gorm.DB {
+	all := func(db *gorm.DB) *gorm.DB { return db }
+	if fields == nil || len(modelInfo.ComputedFields) > 0 {
+		return all
+	}
+	stmt := &gorm.Statement{DB: g.DB}
+	if err := stmt.Parse(reflect.New(modelInfo.Type).Interface()); err != nil {
+		return all
+	}
+
+	var columns []string
+	add := func(column string) {
+		if column != "" && !slices.Contains(columns, column) {
+			columns = append(columns, column)
+		}
+	}
+	for _, field := range stmt.Schema.PrimaryFields {
+		add(field.DBName)
+	}
+	for _, field := range modelInfo.Fields {
+		if field.IsID {
+			if schemaField := stmt.Schema.LookUpField(field.Name); schemaField != nil {
+				add(schemaField.DBName)
+			}
+		}
+	}
+	for _, name := range fields {
+		// Fields promoted from embedded structs go by their Go name
+		goName := name
+		if field, ok := modelInfo.fieldByJSONName(name); ok {
+			goName = field.Name
+		}
+		schemaField := stmt.Schema.LookUpField(goName)
+		if schemaField == nil {
+			return all
+		}
+		// Relations have no column of their own
+		add(schemaField.DBName)
+	}
+
+	selected := make([]clause.Column, 0, len(columns))
+	for _, column := range columns {
+		selected = append(selected, clause.Column{Table: clause.CurrentTable, Name: column})
+	}
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Clauses(clause.Select{Columns: selected})
+	}
+}
+
+// selectFields returns a response body holding an instance, or each instance of a
+// slice, as a map with its computed fields added. Only the given fields are kept when
+// fields isn't nil.
+func selectFields(payload any, modelInfo ModelInfo, computed []map[string]any, fields []string) any {
+	record := func(i int, instance any) map[string]any {
+		values := snapshot(instance, modelInfo)
+		if computed != nil {
+			maps.Copy(values, computed[i])
+		}
+		if fields != nil {
+			values = keepKeys(values, fields)
+		}
+		return values
+	}
+
+	value := reflect.Indirect(reflect.ValueOf(payload))
+	if value.Kind() == reflect.Slice {
+		items := make([]map[string]any, 0, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			items = append(items, record(i, value.Index(i).Interface()))
+		}
+		return items
+	}
+	return record(0, payload)
+}
+
+// selectAttributes adds the computed fields to the attributes of the resources of a
+// JSON:API document, then drops the attributes not in fields when fields isn't nil
+func selectAttributes(document gin.H, computed []map[string]any, fields []string) {
+	resources, ok := document["data"].([]map[string]any)
+	if !ok {
+		resources = []map[string]any{document["data"].(map[string]any)}
+	}
+	for i, resource := range resources {
+		attributes, ok := resource["attributes"].(map[string]any)
+		if !ok {
+			continue
+		}
+		if computed != nil {
+			maps.Copy(attributes, computed[i])
+		}
+		if fields != nil {
+			resource["attributes"] = keepKeys(attributes, fields)
+		}
+	}
+}
+
+// keepKeys returns the entries of values whose key is in keys
+func keepKeys(values map[string]any, keys []string) map[string]any {
+	kept := make(map[string]any, len(keys))
+	for _, key := range keys {
+		if value, ok := values[key]; ok {
+			kept[key] = value
+		}
+	}
+	return kept
+}
@@ -0,0 +1,77 @@
+package apigen
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestFieldSelection(t *testing.T) {
+	g, router := newTestAPI(t, nil, &testUser{})
+	g.DB.Create(&testUser{Name: "Ada", Email: "ada@example.com"})
+
+	user := decode[map[string]any](t, serve(router, http.MethodGet, "/api/test_users/1?fields=id,name", ""))
+	if len(user) != 2 || user["name"] != "Ada" || user["id"] == nil {
+		t.Errorf("get: got %v", user)
+	}
+	users := decode[[]map[string]any](t, serve(router, http.MethodGet, "/api/test_users?fields=email", ""))
+	if len(users) != 1 || len(users[0]) != 1 || users[0]["email"] != "ada@example.com" {
+		t.Errorf("list: got %v", users)
+	}
+	if w := serve(router, http.MethodGet, "/api/test_users?fields=name,password", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("unknown field: got %d, want 400", w.Code)
+	}
+
+	swagger := NewSwaggerGenerator(g.Models)
+	swagger.BuildPathsForAllModels()
+	list := swagger.GenerateAllPaths()["/api/test_users"].(map[string]any)["get"].(map[string]any)
+	found := false
+	for _, parameter := range list["parameters"].([]map[string]any) {
+		found = found || parameter["name"] == "fields"
+	}
+	if !found {
+		t.Errorf("swagger misses the fields parameter: %v", list["parameters"])
+	}
+}
+
+func TestFieldMask(t *testing.T) {
+	g, router := newTestAPI(t, func(g *APIGenerator) {
+		g.IncludeUntaggedFields = true
+		g.RegisterModelWithOptions(&testUser{})
+		g.RegisterModelWithOptions(&testLegacyUser{})
+	}, &testUser{}, &testLegacyUser{})
+	g.DB.Create(&testUser{Name: "Ada", Email: "ada@example.com"})
+
+	dryRun := g.DB.Session(&gorm.Session{DryRun: true})
+	sql := func(model string, fields []string, dest any) string {
+		return dryRun.Scopes(g.fieldMaskScope(g.Models[model], fields)).Find(dest).Statement.SQL.String()
+	}
+	if got := sql("testUser", []string{"email"}, &[]testUser{}); got != "SELECT `test_users`.`id`,`test_users`.`email` FROM `test_users`" {
+		t.Errorf("email: got %q", got)
+	}
+	if got := sql("testLegacyUser", []string{"full_name"}, &[]testLegacyUser{}); got != "SELECT `test_legacy_users`.`id`,`test_legacy_users`.`full_name` FROM `test_legacy_users`" {
+		t.Errorf("tagged column: got %q", got)
+	}
+	if got := sql("testUser", nil, &[]testUser{}); got != "SELECT * FROM `test_users`" {
+		t.Errorf("no fields: got %q", got)
+	}
+
+	// The list endpoint runs the masked query
+	var queries []string
+	g.DB.Callback().Query().After("gorm:query").Register("test:record_sql", func(db *gorm.DB) {
+		queries = append(queries, db.Statement.SQL.String())
+	})
+	defer g.DB.Callback().Query().Remove("test:record_sql")
+	users := decode[[]map[string]any](t, serve(router, http.MethodGet, "/api/test_users?fields=name", ""))
+	if len(users) != 1 || users[0]["name"] != "Ada" || len(users[0]) != 1 {
+		t.Errorf("list: got %v", users)
+	}
+	if !slices.ContainsFunc(queries, func(query string) bool {
+		return strings.HasPrefix(query, "SELECT `test_users`.`id`,`test_users`.`name` FROM")
+	}) {
+		t.Errorf("queries: got %q", queries)
+	}
+}
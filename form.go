@@ -0,0 +1,166 @@
+package apigen
+
+import (
+	"errors"
+	"mime/multipart"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/rs/zerolog/log"
+)
+
+// defaultMultipartMemory is gin's default memory limit of multipart form parsing
+const defaultMultipartMemory = 32 << 20
+
+// FileStore stores the files uploaded to the fields tagged apigen:"file"
+type FileStore interface {
+	// Save stores an uploaded file and returns the URL it is served from
+	Save(fh *multipart.FileHeader) (string, error)
+}
+
+// FileDeleter is implemented by the FileStores able to delete a saved file. The files
+// saved for a request are deleted when it fails, e.g. on a database error.
+type FileDeleter interface {
+	Delete(url string) error
+}
+
+// uploadedFilesKey is the gin context key holding the URLs of the files saved for a request
+const uploadedFilesKey = "apigen.uploaded_files"
+
+// bindBody binds the request body to an instance according to its content type.
+// Form-encoded and multipart bodies are bound by field API names, anything else is
+// bound as JSON.
+func (g *APIGenerator) bindBody(c *gin.Context, modelInfo ModelInfo, instance any) error {
+	defer modelInfo.preserveReadOnly(instance)()
+
+	switch c.ContentType() {
+	case binding.MIMEPOSTForm, binding.MIMEMultipartPOSTForm:
+		return g.bindForm(c, modelInfo, instance)
+	default:
+		return g.bindJSON(c, modelInfo, instance)
+	}
+}
+
+// bindForm binds a form-encoded or multipart body to an instance. The files uploaded
+// to file fields are saved in FileStore and the fields set to their URLs.
+func (g *APIGenerator) bindForm(c *gin.Context, modelInfo ModelInfo, instance any) error {
+	var files map[string][]*multipart.FileHeader
+	if c.ContentType() == binding.MIMEMultipartPOSTForm {
+		if err := c.Request.ParseMultipartForm(g.maxMultipartMemory()); err != nil {
+			return err
+		}
+		files = c.Request.MultipartForm.File
+	} else if err := c.Request.ParseForm(); err != nil {
+		return err
+	}
+
+	values := make(map[string]any, len(c.Request.PostForm)+len(files))
+	for key, value := range c.Request.PostForm {
+		values[key] = value
+	}
+	for key := range files {
+		values[key] = nil
+	}
+	if g.StrictSchemaValidation {
+		if err := unknownFields(values, modelInfo); err != nil {
+			return err
+		}
+	}
+
+	modelInfo.renameKeys(values, false)
+	if err := binding.MapFormWithTag(instance, formValues(values), "json"); err != nil {
+		return err
+	}
+
+	var uploadFields []FieldInfo
+	for _, field := range modelInfo.Fields {
+		if field.IsFile && len(files[field.JSONName]) > 0 {
+			uploadFields = append(uploadFields, field)
+		}
+	}
+	if len(uploadFields) == 0 {
+		return binding.Validator.ValidateStruct(instance)
+	}
+	if g.FileStore == nil {
+		return errors.New("file uploads are not supported")
+	}
+
+	// Validate the other fields before saving any file, so invalid requests store nothing
+	if validate, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		names := make([]string, 0, len(uploadFields))
+		for _, field := range uploadFields {
+			names = append(names, field.Name)
+		}
+		if err := validate.StructExcept(instance, names...); err != nil {
+			return err
+		}
+	}
+
+	// Replace the uploaded files by their URLs
+	urls := make(map[string]any, len(uploadFields))
+	for _, field := range uploadFields {
+		url, err := g.FileStore.Save(files[field.JSONName][0])
+		if err != nil {
+			g.deleteUploads(c)
+			return err
+		}
+		c.Set(uploadedFilesKey, append(c.GetStringSlice(uploadedFilesKey), url))
+		urls[field.JSONName] = []string{url}
+	}
+	modelInfo.renameKeys(urls, false)
+	if err := binding.MapFormWithTag(instance, formValues(urls), "json"); err != nil {
+		g.deleteUploads(c)
+		return err
+	}
+	if err := binding.Validator.ValidateStruct(instance); err != nil {
+		g.deleteUploads(c)
+		return err
+	}
+	return nil
+}
+
+// formValues keeps the form values of a map of field values. Fields without a json tag
+// are mapped by their Go name, like encoding/json does.
+func formValues(values map[string]any) map[string][]string {
+	form := make(map[string][]string, len(values))
+	for key, value := range values {
+		if value, ok := value.([]string); ok {
+			form[key] = value
+		}
+	}
+	return form
+}
+
+// deleteUploads deletes the files saved for a request, when FileStore can delete them
+func (g *APIGenerator) deleteUploads(c *gin.Context) {
+	urls := c.GetStringSlice(uploadedFilesKey)
+	if len(urls) == 0 {
+		return
+	}
+	c.Set(uploadedFilesKey, []string(nil))
+
+	deleter, ok := g.FileStore.(FileDeleter)
+	if !ok {
+		log.Warn().Strs("files", urls).Msg("apigen: FileStore can't delete the files of a failed request")
+		return
+	}
+	for _, url := range urls {
+		if err := deleter.Delete(url); err != nil {
+			log.Error().Err(err).Str("file", url).Msg("apigen: failed to delete the file of a failed request")
+		}
+	}
+}
+
+// isFileField reports whether a field holds the URL of a file uploaded as
+// multipart form data, i.e. is tagged apigen:"file"
+func isFileField(field reflect.StructField) bool {
+	for _, option := range strings.Split(field.Tag.Get("apigen"), ",") {
+		if strings.TrimSpace(option) == "file" {
+			return true
+		}
+	}
+	return false
+}
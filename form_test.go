@@ -0,0 +1,95 @@
+package apigen
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// testDocument has a file field
+type testDocument struct {
+	ID    uint   `json:"id" gorm:"primaryKey"`
+	Title string `json:"title" binding:"required"`
+	File  string `json:"file" apigen:"file"`
+}
+
+// testFileStore records the files saved and deleted
+type testFileStore struct {
+	saved, deleted []string
+}
+
+func (s *testFileStore) Save(fh *multipart.FileHeader) (string, error) {
+	url := "/files/" + fh.Filename
+	s.saved = append(s.saved, url)
+	return url, nil
+}
+
+func (s *testFileStore) Delete(url string) error {
+	s.deleted = append(s.deleted, url)
+	return nil
+}
+
+// uploadDocument posts a multipart form with a title and a file
+func uploadDocument(router http.Handler, title string) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	if title != "" {
+		form.WriteField("title", title)
+	}
+	file, _ := form.CreateFormFile("file", "report.pdf")
+	file.Write([]byte("%PDF"))
+	form.Close()
+	return serve(router, http.MethodPost, "/api/test_documents", body.String(), "Content-Type", form.FormDataContentType())
+}
+
+func TestUploadValidation(t *testing.T) {
+	t.Run("invalid", func(t *testing.T) {
+		store := &testFileStore{}
+		_, router := newTestAPI(t, func(g *APIGenerator) { g.FileStore = store }, &testDocument{})
+
+		if w := uploadDocument(router, ""); w.Code != http.StatusBadRequest {
+			t.Fatalf("upload without title: got %d %s", w.Code, w.Body)
+		}
+		if len(store.saved) != 0 {
+			t.Errorf("invalid upload saved %v", store.saved)
+		}
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		store := &testFileStore{}
+		_, router := newTestAPI(t, func(g *APIGenerator) {
+			g.FileStore = store
+			g.RegisterModelWithOptions(&testDocument{}, WithHooks(ModelHooks{
+				BeforeCreate: func(c *gin.Context, instance any) error { return errors.New("rejected") },
+			}))
+		}, &testDocument{})
+
+		if w := uploadDocument(router, "Report"); w.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("rejected upload: got %d %s", w.Code, w.Body)
+		}
+		if len(store.saved) != 1 || len(store.deleted) != 1 || store.deleted[0] != store.saved[0] {
+			t.Errorf("rejected upload: saved %v, deleted %v", store.saved, store.deleted)
+		}
+	})
+
+	t.Run("created", func(t *testing.T) {
+		store := &testFileStore{}
+		_, router := newTestAPI(t, func(g *APIGenerator) { g.FileStore = store }, &testDocument{})
+
+		w := uploadDocument(router, "Report")
+		if w.Code != http.StatusCreated {
+			t.Fatalf("upload: got %d %s", w.Code, w.Body)
+		}
+		if document := decode[testDocument](t, w); document.File != "/files/report.pdf" {
+			t.Errorf("upload: file %q", document.File)
+		}
+		if len(store.deleted) != 0 {
+			t.Errorf("upload deleted %v", store.deleted)
+		}
+	})
+}
@@ -0,0 +1,48 @@
+package apigen
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestNewWithGroup(t *testing.T) {
+	router := gin.New()
+	g := NewWithGroup(newTestDB(t, &testArticle{}, &testTag{}), router.Group("/v1"))
+	if g.Router != nil {
+		t.Errorf("Router: got %v, want nil for a group", g.Router)
+	}
+	g.RegisterModelWithOptions(&testArticle{})
+	g.RegisterModelWithOptions(&testTag{})
+	ok := func(c *gin.Context) { c.Status(http.StatusNoContent) }
+	if err := g.RegisterAction("testArticle", http.MethodPost, "/:id/publish", ok); err != nil {
+		t.Fatalf("register action: %v", err)
+	}
+	if err := g.GenerateAPI("Test API", "1.0.0"); err != nil {
+		t.Fatalf("generate API: %v", err)
+	}
+	g.DB.Create(&testArticle{Title: "Mounted"})
+
+	if w := serve(router, http.MethodGet, "/v1/api/test_articles/1", ""); w.Code != http.StatusOK {
+		t.Errorf("get: got %d", w.Code)
+	}
+	if path := g.Models["testArticle"].Actions[0].Path; path != "/v1/api/test_articles/:id/publish" {
+		t.Errorf("action path: got %s", path)
+	}
+	if w := serve(router, http.MethodPost, "/v1/api/test_articles/1/publish", ""); w.Code != http.StatusNoContent {
+		t.Errorf("action: got %d", w.Code)
+	}
+
+	w := serve(router, http.MethodGet, "/v1/api/test_articles/1", "", "Accept", JSONAPIMediaType)
+	document := decode[struct {
+		Data struct {
+			Relationships map[string]struct {
+				Links map[string]string `json:"links"`
+			} `json:"relationships"`
+		} `json:"data"`
+	}](t, w)
+	if related := document.Data.Relationships["tags"].Links["related"]; related != "/v1/api/test_articles/1/tags" {
+		t.Errorf("JSON:API related link: got %q", related)
+	}
+}
@@ -1,36 +1,251 @@
 package apigen
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"reflect"
 	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // listHandler returns a handler function for listing all instances of a model
 // @Summary List all instances of a model
 // @Description Get all instances of a model
 // @Tags API
-// @Produce json
+// @Produce json,text/csv,application/vnd.api+json
+// @Param field query string false "Filter by field value, e.g. age__gte=18"
+// @Param page query int false "Page number, starting at 1"
+// @Param limit query int false "Number of records per page"
+// @Param sort query string false "Comma separated fields to sort by, prefix with - for descending"
+// @Param preload query string false "Comma separated associations to load, e.g. User"
+// @Param fields query string false "Comma separated fields to include, e.g. id,name"
+// @Param include_deleted query bool false "Include soft deleted records, when restore is enabled"
+// @Param Range header string false "Records to return when page and limit are absent, e.g. records=0-24"
 // @Success 200 {array} any
+// @Success 206 {array} any
+// @Header 200 {integer} X-Total-Count "Number of matching records, when TotalCountHeader is set"
+// @Header 206 {string} Content-Range "Records returned and matching, e.g. records 0-24/100"
+// @Failure 400 {object} map[string]string
+// @Failure 416 {object} map[string]string
 // @Router /api/{model} [get]
 func (g *APIGenerator) listHandler(modelInfo ModelInfo) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		// Parse filtering, pagination and sorting parameters
+		filters, err := g.parseFilters(c, modelInfo)
+		if err != nil {
+			g.respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		page, err := parsePagination(c)
+		if err != nil {
+			g.respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		c.Header("Accept-Ranges", rangeUnit)
+		if !page.Active {
+			if ranged, ok := parseRecordRange(c); ok {
+				page = ranged
+			}
+		}
+		sort, err := g.parseSort(c, modelInfo)
+		if err != nil {
+			g.respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		preloads, err := g.parsePreloads(c, modelInfo)
+		if err != nil {
+			g.respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		fields, err := parseFields(c, modelInfo)
+		if err != nil {
+			g.respondError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		// Create a slice to hold the results
+		sliceType := reflect.SliceOf(modelInfo.Type)
+		results := reflect.New(sliceType).Interface()
+
+		// A Range must start within the matching records
+		deleted := deletedScope(c, modelInfo)
+		var total int64
+		if page.Ranged {
+			if err := g.modelDB(c, modelInfo).Model(reflect.New(modelInfo.Type).Interface()).Scopes(deleted, filters).Count(&total).Error; err != nil {
+				g.respondError(c, http.StatusInternalServerError, err)
+				return
+			}
+			if int64(page.Start) >= total && (page.Start > 0 || total > 0) {
+				g.rangeNotSatisfiable(c, page, total)
+				return
+			}
+		}
+
+		// Query the database, fetching only the columns of the selected fields. CSV exports
+		// hold every field.
+		exportCSV := g.wantsCSV(c, modelInfo)
+		if exportCSV {
+			fields = nil
+		}
+		mask := g.fieldMaskScope(modelInfo, fields)
+		g.logQueryPlan(g.modelDB(c, modelInfo), modelInfo, func(tx *gorm.DB) *gorm.DB {
+			return tx.Scopes(deleted, filters, sort, page.Scope(), mask).Find(reflect.New(sliceType).Interface())
+		})
+		if err := g.modelDB(c, modelInfo).Scopes(deleted, filters, sort, page.Scope(), preloads, mask).Find(results).Error; err != nil {
+			g.respondError(c, http.StatusInternalServerError, err)
+			return
+		}
+		setCacheControl(c, modelInfo)
+
+		// Export as CSV when requested
+		if exportCSV {
+			var rows any = results
+			if modelInfo.TransformResponse != nil {
+				if rows, err = g.transformRecords(c, modelInfo, selectFields(results, modelInfo, nil, nil).([]map[string]any), false); err != nil {
+					g.respondError(c, http.StatusInternalServerError, err)
+					return
+				}
+			}
+			if err := writeCSV(c, modelInfo, rows); err != nil {
+				g.respondError(c, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		// A Range is answered with the positions of the returned records
+		if page.Ranged {
+			if g.TotalCountHeader {
+				c.Header(totalCountHeader, strconv.FormatInt(total, 10))
+			}
+			status := contentRange(c, page, reflect.ValueOf(results).Elem().Len(), total)
+			g.respondWithFields(c, status, modelInfo, results, nil, fields)
+			return
+		}
+
+		meta, err := g.paginate(c, page, g.modelDB(c, modelInfo).Model(reflect.New(modelInfo.Type).Interface()).Scopes(deleted, filters))
+		if err != nil {
+			g.respondError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		// Return the results, trimmed to the selected fields
+		g.respondWithFields(c, http.StatusOK, modelInfo, results, meta, fields)
+	}
+}
+
+// countHandler returns a handler function for counting instances of a model
+// @Summary Count instances of a model
+// @Description Count the instances of a model matching the filter parameters
+// @Tags API
+// @Produce json
+// @Param field query string false "Filter by field value, e.g. age__gte=18"
+// @Success 200 {object} map[string]int64
+// @Failure 400 {object} map[string]string
+// @Router /api/{model}/count [get]
+func (g *APIGenerator) countHandler(modelInfo ModelInfo) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filters, err := g.parseFilters(c, modelInfo)
+		if err != nil {
+			g.respondError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		// Count the matching records
+		instance := reflect.New(modelInfo.Type).Interface()
+		var count int64
+		if err := g.modelDB(c, modelInfo).Model(instance).Scopes(filters).Count(&count).Error; err != nil {
+			g.respondError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"count": count})
+	}
+}
+
+// likeEscaper escapes the wildcards of LIKE patterns, and the escape character itself.
+// The escape character is ! rather than a backslash, which MySQL string literals treat
+// as an escape of their own.
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+// searchHandler returns a handler function for searching instances of a model
+// @Summary Search instances of a model
+// @Description Match the search term against all searchable string fields
+// @Tags API
+// @Produce json,application/vnd.api+json
+// @Param q query string true "Search term"
+// @Param page query int false "Page number, starting at 1"
+// @Param limit query int false "Number of records per page"
+// @Param sort query string false "Comma separated fields to sort by, prefix with - for descending"
+// @Success 200 {array} any
+// @Header 200 {integer} X-Total-Count "Number of matching records, when TotalCountHeader is set"
+// @Failure 400 {object} map[string]string
+// @Router /api/{model}/search [get]
+func (g *APIGenerator) searchHandler(modelInfo ModelInfo) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		term := strings.TrimSpace(c.Query("q"))
+		if utf8.RuneCountInString(term) < g.MinQueryLength {
+			g.respondError(c, http.StatusBadRequest, fmt.Errorf("Search term must be at least %d characters", g.MinQueryLength))
+			return
+		}
+
+		// Parse pagination and sorting parameters
+		page, err := parsePagination(c)
+		if err != nil {
+			g.respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		sort, err := g.parseSort(c, modelInfo)
+		if err != nil {
+			g.respondError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		// Build an OR chain of LIKE clauses across the searchable fields, matching the
+		// term literally
+		pattern := "%" + likeEscaper.Replace(term) + "%"
+		var conditions *gorm.DB
+		for _, name := range modelInfo.SearchableFields {
+			field, ok := modelInfo.fieldByJSONName(name)
+			if !ok || field.Type.Kind() != reflect.String {
+				continue
+			}
+
+			column := clause.Column{Name: g.columnName(field)}
+			if conditions == nil {
+				conditions = g.DB.Where("? LIKE ? ESCAPE '!'", column, pattern)
+			} else {
+				conditions = conditions.Or("? LIKE ? ESCAPE '!'", column, pattern)
+			}
+		}
+		if conditions == nil {
+			g.respondError(c, http.StatusBadRequest, errors.New("Model has no searchable fields"))
+			return
+		}
+
 		// Create a slice to hold the results
 		sliceType := reflect.SliceOf(modelInfo.Type)
 		results := reflect.New(sliceType).Interface()
 
 		// Query the database
-		if err := g.DB.Find(results).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		if err := g.modelDB(c, modelInfo).Where(conditions).Scopes(sort, page.Scope()).Find(results).Error; err != nil {
+			g.respondError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		meta, err := g.paginate(c, page, g.modelDB(c, modelInfo).Model(reflect.New(modelInfo.Type).Interface()).Where(conditions))
+		if err != nil {
+			g.respondError(c, http.StatusInternalServerError, err)
 			return
 		}
 
 		// Return the results
-		c.JSON(http.StatusOK, results)
+		g.respondWithMeta(c, http.StatusOK, modelInfo, results, meta)
 	}
 }
 
@@ -38,78 +253,147 @@ func (g *APIGenerator) listHandler(modelInfo ModelInfo) gin.HandlerFunc {
 // @Summary Get a model instance by ID
 // @Description Get a single instance of a model by ID
 // @Tags API
-// @Produce json
+// @Produce json,application/vnd.api+json
 // @Param id path string true "ID of the model instance"
+// @Param preload query string false "Comma separated associations to load, e.g. User"
+// @Param fields query string false "Comma separated fields to include, e.g. id,name"
+// @Param include_deleted query bool false "Include soft deleted records, when restore is enabled"
+// @Param If-Modified-Since header string false "Only return the instance if it changed since this time"
 // @Success 200 {object} any
+// @Success 304
 // @Failure 404 {object} map[string]string
 // @Router /api/{model}/{id} [get]
 func (g *APIGenerator) getHandler(modelInfo ModelInfo) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		id := c.Param("id")
-		if id == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "ID is required"})
+		preloads, err := g.parsePreloads(c, modelInfo)
+		if err != nil {
+			g.respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		fields, err := parseFields(c, modelInfo)
+		if err != nil {
+			g.respondError(c, http.StatusBadRequest, err)
 			return
 		}
-
-		// Create a new instance of the model
-		instance := reflect.New(modelInfo.Type).Interface()
 
 		// Query the database
-		idField, _ := modelInfo.Type.FieldByName("ID")
-		if idField.Type.Kind() == reflect.String {
-			if err := g.DB.Where("id = ?", id).First(instance).Error; err != nil {
-				if err == gorm.ErrRecordNotFound {
-					c.JSON(http.StatusNotFound, gin.H{"error": "Record not found"})
-					return
-				}
-				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-				return
-			}
-		} else {
-			if err := g.DB.First(instance, id).Error; err != nil {
-				if err == gorm.ErrRecordNotFound {
-					c.JSON(http.StatusNotFound, gin.H{"error": "Record not found"})
-					return
-				}
-				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-				return
-			}
+		instance, ok := g.loadInstance(c, modelInfo, deletedScope(c, modelInfo), preloads)
+		if !ok {
+			return
 		}
+		setCacheControl(c, modelInfo)
 
-		// Return the result
-		c.JSON(http.StatusOK, instance)
+		// Let the client reuse its cached copy when the record hasn't changed
+		if notModified(c, instance, modelInfo) {
+			c.Status(http.StatusNotModified)
+			return
+		}
+
+		// Return the result, trimmed to the selected fields, with its links
+		var links map[string]any
+		if modelInfo.EnableHATEOAS {
+			links = g.resourceLinks(modelInfo, instance)
+		}
+		g.respondWithLinks(c, http.StatusOK, modelInfo, instance, nil, fields, links)
 	}
 }
 
+// headListHandler returns a handler function answering HEAD requests for the list endpoint
+// @Summary Check the list of model instances
+// @Description Same as listing all instances of a model, without the response body
+// @Tags API
+// @Produce json
+// @Success 200
+// @Router /api/{model} [head]
+func (g *APIGenerator) headListHandler(modelInfo ModelInfo) gin.HandlerFunc {
+	return headOnly(g.listHandler(modelInfo))
+}
+
+// headGetHandler returns a handler function answering HEAD requests for a single instance
+// @Summary Check a model instance by ID
+// @Description Same as getting a single instance of a model, without the response body
+// @Tags API
+// @Produce json
+// @Param id path string true "ID of the model instance"
+// @Success 200
+// @Failure 404
+// @Router /api/{model}/{id} [head]
+func (g *APIGenerator) headGetHandler(modelInfo ModelInfo) gin.HandlerFunc {
+	return headOnly(g.getHandler(modelInfo))
+}
+
 // createHandler returns a handler function for creating a new instance of a model
 // @Summary Create a new model instance
 // @Description Create a new instance of a model
 // @Tags API
-// @Accept json
-// @Produce json
+// @Accept json,x-www-form-urlencoded,mpfd
+// @Produce json,application/vnd.api+json
 // @Param model body any true "Model instance"
+// @Param Idempotency-Key header string false "Replays the first response to requests repeating this key"
 // @Success 201 {object} any
 // @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 413 {object} map[string]string
+// @Failure 422 {object} map[string]string
 // @Router /api/{model} [post]
 func (g *APIGenerator) createHandler(modelInfo ModelInfo) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Create a new instance of the model
 		instance := reflect.New(modelInfo.Type).Interface()
 
+		// Note the fields the request sets, before its body is consumed
+		var keys map[string]bool
+		if len(modelInfo.DefaultValues) > 0 {
+			keys = g.requestKeys(c)
+		}
+
 		// Bind the request body to the model
-		if err := c.ShouldBindJSON(instance); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		if err := g.bindBody(c, modelInfo, instance); err != nil {
+			g.respondBindError(c, modelInfo, err)
+			return
+		}
+
+		// Fill in the defaults of the fields left out
+		if err := applyDefaults(modelInfo, instance, keys); err != nil {
+			g.respondError(c, http.StatusInternalServerError, err)
+			return
+		}
+		if err := g.transformRequest(c, modelInfo, instance); err != nil {
+			g.respondError(c, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		// New records always start at the first version
+		if modelInfo.LockVersion {
+			setVersion(instance, 1)
+		}
+		if err := g.setTenant(c, modelInfo, instance); err != nil {
+			g.respondError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := runHook(modelInfo.Hooks.BeforeCreate, c, instance); err != nil {
+			g.respondError(c, http.StatusUnprocessableEntity, err)
 			return
 		}
 
 		// Create the record in the database
-		if err := g.DB.Create(instance).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		if err := g.writeDB(c, modelInfo).Create(instance).Error; err != nil {
+			g.respondDBError(c, err)
+			return
+		}
+
+		if err := runHook(modelInfo.Hooks.AfterCreate, c, instance); err != nil {
+			g.respondError(c, http.StatusInternalServerError, err)
 			return
 		}
+		g.audit(c, modelInfo, nil, instance)
 
-		// Return the created instance
-		c.JSON(http.StatusCreated, instance)
+		// Return the created instance along with its URL
+		if !g.isDryRun(c) {
+			setLocation(c, instance, modelInfo)
+		}
+		g.respond(c, http.StatusCreated, modelInfo, instance)
 	}
 }
 
@@ -117,61 +401,103 @@ func (g *APIGenerator) createHandler(modelInfo ModelInfo) gin.HandlerFunc {
 // @Summary Update a model instance
 // @Description Update an instance of a model
 // @Tags API
-// @Accept json
-// @Produce json
+// @Accept json,x-www-form-urlencoded,mpfd
+// @Produce json,application/vnd.api+json
 // @Param id path string true "ID of the model instance"
 // @Param model body any true "Model instance"
 // @Success 200 {object} any
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 413 {object} map[string]string
+// @Failure 422 {object} map[string]string
 // @Router /api/{model}/{id} [put]
 func (g *APIGenerator) updateHandler(modelInfo ModelInfo) gin.HandlerFunc {
+	return g.modifyHandler(modelInfo, func(c *gin.Context, instance any) (map[string]bool, error) {
+		keys := g.requestKeys(c)
+		if modelInfo.LockVersion {
+			return keys, g.bindVersioned(c, modelInfo, instance)
+		}
+		return keys, g.bindBody(c, modelInfo, instance)
+	})
+}
+
+// modifyHandler returns a handler function saving the changes bind makes to a loaded
+// instance. bind returns the API fields set by the request.
+func (g *APIGenerator) modifyHandler(modelInfo ModelInfo, bind func(c *gin.Context, instance any) (map[string]bool, error)) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		id := c.Param("id")
-		if id == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "ID is required"})
+		// First check if the record exists
+		instance, ok := g.loadInstance(c, modelInfo)
+		if !ok {
 			return
 		}
 
-		// Create a new instance of the model
-		instance := reflect.New(modelInfo.Type).Interface()
+		// Keep the original record for the audit trail
+		var before map[string]any
+		if g.auditLogger != nil {
+			before = snapshot(instance, modelInfo)
+		}
+		stored := int64(0)
+		if modelInfo.LockVersion {
+			stored = getVersion(instance)
+		}
 
-		// First check if the record exists
-		idField, _ := modelInfo.Type.FieldByName("ID")
-		if idField.Type.Kind() == reflect.String {
-			if err := g.DB.Where("id = ?", id).First(instance).Error; err != nil {
-				if err == gorm.ErrRecordNotFound {
-					c.JSON(http.StatusNotFound, gin.H{"error": "Record not found"})
-					return
-				}
-				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-				return
-			}
-		} else {
-			if err := g.DB.First(instance, id).Error; err != nil {
-				if err == gorm.ErrRecordNotFound {
-					c.JSON(http.StatusNotFound, gin.H{"error": "Record not found"})
-					return
-				}
-				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-				return
-			}
+		// Bind the request body to the model, noting the fields it sets
+		key := primaryKey(instance, modelInfo)
+		original := cloneInstance(instance)
+		keys, err := bind(c, instance)
+		if err != nil {
+			g.respondBindError(c, modelInfo, err)
+			return
+		}
+		if err := g.transformRequest(c, modelInfo, instance); err != nil {
+			g.respondError(c, http.StatusUnprocessableEntity, err)
+			return
 		}
 
-		// Bind the request body to the model
-		if err := c.ShouldBindJSON(instance); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		// The body may repeat the primary key but must not change it
+		if !reflect.DeepEqual(primaryKey(instance, modelInfo), key) {
+			g.respondError(c, http.StatusConflict, errors.New("ID in the request body does not match the URL"))
+			return
+		}
+		if err := g.setTenant(c, modelInfo, instance); err != nil {
+			g.respondError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := runHook(modelInfo.Hooks.BeforeUpdate, c, instance); err != nil {
+			g.respondError(c, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		// Update the columns of the fields sent or changed, guarding against lost updates
+		// when the model is versioned
+		columns := g.updateColumns(modelInfo, keys, original, instance)
+		switch {
+		case modelInfo.LockVersion && g.isDryRun(c):
+			err = dryRunVersion(instance, stored)
+		case modelInfo.LockVersion:
+			err = g.saveWithVersion(c, modelInfo, instance, columns)
+		case len(columns) > 0:
+			err = g.writeDB(c, modelInfo).Select(columns).Updates(instance).Error
+		}
+		if err != nil {
+			if err == errVersionConflict {
+				g.respondError(c, http.StatusConflict, err)
+				return
+			}
+			g.respondDBError(c, err)
 			return
 		}
 
-		// Update the record in the database
-		if err := g.DB.Save(instance).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		if err := runHook(modelInfo.Hooks.AfterUpdate, c, instance); err != nil {
+			g.respondError(c, http.StatusInternalServerError, err)
 			return
 		}
+		g.audit(c, modelInfo, before, instance)
 
 		// Return the updated instance
-		c.JSON(http.StatusOK, instance)
+		g.respond(c, http.StatusOK, modelInfo, instance)
 	}
 }
 
@@ -183,83 +509,134 @@ func (g *APIGenerator) updateHandler(modelInfo ModelInfo) gin.HandlerFunc {
 // @Param id path string true "ID of the model instance"
 // @Success 204 {object} nil
 // @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]string
 // @Router /api/{model}/{id} [delete]
 func (g *APIGenerator) deleteHandler(modelInfo ModelInfo) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		id := c.Param("id")
-		if id == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "ID is required"})
+		// First check if the record exists
+		instance, ok := g.loadInstance(c, modelInfo)
+		if !ok {
 			return
 		}
 
-		// Create a new instance of the model
-		instance := reflect.New(modelInfo.Type).Interface()
-
-		// First check if the record exists
-		idField, _ := modelInfo.Type.FieldByName("ID")
-		if idField.Type.Kind() == reflect.String {
-			if err := g.DB.Where("id = ?", id).First(instance).Error; err != nil {
-				if err == gorm.ErrRecordNotFound {
-					c.JSON(http.StatusNotFound, gin.H{"error": "Record not found"})
-					return
-				}
-				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-				return
-			}
-		} else {
-			if err := g.DB.First(instance, id).Error; err != nil {
-				if err == gorm.ErrRecordNotFound {
-					c.JSON(http.StatusNotFound, gin.H{"error": "Record not found"})
-					return
-				}
-				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-				return
-			}
+		if err := runHook(modelInfo.Hooks.BeforeDelete, c, instance); err != nil {
+			g.respondError(c, http.StatusUnprocessableEntity, err)
+			return
 		}
 
 		// Delete the record from the database
-		if err := g.DB.Delete(instance).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		if err := g.writeDB(c, modelInfo).Delete(instance).Error; err != nil {
+			g.respondDBError(c, err)
+			return
+		}
+
+		if err := runHook(modelInfo.Hooks.AfterDelete, c, instance); err != nil {
+			g.respondError(c, http.StatusInternalServerError, err)
 			return
 		}
+		g.audit(c, modelInfo, nil, instance)
 
 		// Return no content
 		c.Status(http.StatusNoContent)
 	}
 }
 
+// loadInstance fetches the record addressed by the :id path parameter, applying the given scopes.
+// It writes the error response and returns false when the record cannot be loaded.
+func (g *APIGenerator) loadInstance(c *gin.Context, modelInfo ModelInfo, scopes ...func(*gorm.DB) *gorm.DB) (any, bool) {
+	if modelInfo.hasCompositePrimaryKey() {
+		return g.loadCompositeInstance(c, modelInfo, scopes...)
+	}
+
+	id := c.Param("id")
+	if id == "" {
+		g.respondError(c, http.StatusBadRequest, errors.New("ID is required"))
+		return nil, false
+	}
+
+	// Create a new instance of the model
+	instance := reflect.New(modelInfo.Type).Interface()
+
+	// Match the declared primary key field, whatever its name and type
+	keyScope, err := g.primaryKeyScope(modelInfo, id)
+	if err != nil {
+		g.respondError(c, http.StatusBadRequest, err)
+		return nil, false
+	}
+
+	if err := g.modelDB(c, modelInfo).Scopes(scopes...).Scopes(keyScope).First(instance).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			g.respondError(c, http.StatusNotFound, errors.New("Record not found"))
+			return nil, false
+		}
+		g.respondError(c, http.StatusInternalServerError, err)
+		return nil, false
+	}
+
+	return instance, true
+}
+
+// loadCompositeInstance fetches the record addressed by the primary key path parameters
+// of a model with a composite primary key
+func (g *APIGenerator) loadCompositeInstance(c *gin.Context, modelInfo ModelInfo, scopes ...func(*gorm.DB) *gorm.DB) (any, bool) {
+	key, err := g.compositeKeyScope(c, modelInfo)
+	if err != nil {
+		g.respondError(c, http.StatusBadRequest, err)
+		return nil, false
+	}
+
+	// Create a new instance of the model
+	instance := reflect.New(modelInfo.Type).Interface()
+
+	if err := g.modelDB(c, modelInfo).Scopes(scopes...).Scopes(key).First(instance).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			g.respondError(c, http.StatusNotFound, errors.New("Record not found"))
+			return nil, false
+		}
+		g.respondError(c, http.StatusInternalServerError, err)
+		return nil, false
+	}
+
+	return instance, true
+}
+
 // relatedHandler returns a handler function for getting related models
 // @Summary Get related models
 // @Description Get models related to the specified model
 // @Tags API
-// @Produce json
+// @Produce json,application/vnd.api+json
 // @Param id path string true "ID of the parent model instance"
+// @Param page query int false "Page number, starting at 1"
+// @Param limit query int false "Number of records per page"
 // @Success 200 {array} any
+// @Header 200 {integer} X-Total-Count "Number of related records, when TotalCountHeader is set"
+// @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
 // @Router /api/{model}/{id}/{related} [get]
 func (g *APIGenerator) relatedHandler(modelInfo ModelInfo, fk ForeignKeyInfo) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		id := c.Param("id")
-		if id == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "ID is required"})
+		page, err := parsePagination(c)
+		if err != nil {
+			g.respondError(c, http.StatusBadRequest, err)
 			return
 		}
 
 		// Check if the parent record exists
-		parentInstance := reflect.New(modelInfo.Type).Interface()
-		if err := g.DB.First(parentInstance, id).Error; err != nil {
-			if err == gorm.ErrRecordNotFound {
-				c.JSON(http.StatusNotFound, gin.H{"error": "Parent record not found"})
-				return
-			}
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		parentInstance, ok := g.loadParent(c, modelInfo)
+		if !ok {
+			return
+		}
+		// Match on the stored primary key rather than the URL, which may spell it differently
+		parentID, err := primaryKeyValue(parentInstance, modelInfo)
+		if err != nil {
+			g.respondError(c, http.StatusInternalServerError, err)
 			return
 		}
 
 		// Get the related model info
 		relatedModelInfo, exists := g.Models[fk.RelatedModel]
 		if !exists {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Related model %s not registered", fk.RelatedModel)})
+			g.respondError(c, http.StatusInternalServerError, fmt.Errorf("Related model %s not registered", fk.RelatedModel))
 			return
 		}
 
@@ -267,27 +644,164 @@ func (g *APIGenerator) relatedHandler(modelInfo ModelInfo, fk ForeignKeyInfo) gi
 		sliceType := reflect.SliceOf(relatedModelInfo.Type)
 		results := reflect.New(sliceType).Interface()
 
-		// Query the database for related records
-		query := g.DB
-		if fk.RelationshipID != "" {
-			// If we have a direct foreign key ID field
-			idVal, err := strconv.ParseUint(id, 10, 64)
+		// Many-to-many records are found through the join table
+		if fk.RelationType == RelationManyToMany {
+			association := func(scopes ...func(*gorm.DB) *gorm.DB) *gorm.Association {
+				db := g.dbFromContext(c).Model(parentInstance).Scopes(g.modelScopes(relatedModelInfo)...)
+				return db.Scopes(scopes...).Association(fk.FieldName)
+			}
+			if err := association(page.Scope()).Find(results); err != nil {
+				g.respondError(c, http.StatusInternalServerError, err)
+				return
+			}
+			meta, err := g.paginateCount(c, page, func() (int64, error) {
+				association := association()
+				return association.Count(), association.Error
+			})
 			if err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+				g.respondError(c, http.StatusInternalServerError, err)
 				return
 			}
-			query = query.Where(fk.RelationshipID, idVal)
-		} else {
-			// Otherwise, use the relationship name
-			query = query.Where(fmt.Sprintf("%sID = ?", modelInfo.ResourceName), id)
+			g.respondWithMeta(c, http.StatusOK, relatedModelInfo, results, meta)
+			return
 		}
 
-		if err := query.Find(results).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		// Select the related records, qualifying columns with the related table
+		var related func(*gorm.DB) *gorm.DB
+		switch {
+		case fk.RelationType == RelationPolymorphic:
+			// Polymorphic children store the parent's ID and type
+			related = g.polymorphicScope(modelInfo, relatedModelInfo, fk, parentID)
+		case fk.RelationshipID != "":
+			// If we have a direct foreign key ID field, it holds the ID of the related record
+			fkValue := reflect.Indirect(reflect.ValueOf(parentInstance)).FieldByName(fk.RelationshipID).Interface()
+			column := clause.Column{Table: relatedModelInfo.TableName, Name: g.primaryKeyColumn(relatedModelInfo)}
+			related = func(db *gorm.DB) *gorm.DB {
+				return db.Where(clause.Eq{Column: column, Value: fkValue})
+			}
+		default:
+			// Otherwise, the related records point back to the parent by its model name
+			column := clause.Column{Table: relatedModelInfo.TableName, Name: g.DB.NamingStrategy.ColumnName("", modelInfo.Type.Name()+"ID")}
+			related = func(db *gorm.DB) *gorm.DB {
+				return db.Where(clause.Eq{Column: column, Value: parentID})
+			}
+		}
+
+		// Query the database for a page of related records
+		if err := g.modelDB(c, relatedModelInfo).Table(relatedModelInfo.TableName).Scopes(related, page.Scope()).Find(results).Error; err != nil {
+			g.respondError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		meta, err := g.paginate(c, page, g.modelDB(c, relatedModelInfo).Table(relatedModelInfo.TableName).Scopes(related))
+		if err != nil {
+			g.respondError(c, http.StatusInternalServerError, err)
 			return
 		}
 
 		// Return the results
-		c.JSON(http.StatusOK, results)
+		g.respondWithMeta(c, http.StatusOK, relatedModelInfo, results, meta)
+	}
+}
+
+// createRelatedHandler returns a handler function for creating a model related to a parent
+// @Summary Create a related model instance
+// @Description Create an instance of a related model pointing back at the specified model
+// @Tags API
+// @Accept json,x-www-form-urlencoded,mpfd
+// @Produce json,application/vnd.api+json
+// @Param id path string true "ID of the parent model instance"
+// @Param model body any true "Related model instance"
+// @Param Idempotency-Key header string false "Replays the first response to requests repeating this key"
+// @Success 201 {object} any
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 413 {object} map[string]string
+// @Router /api/{model}/{id}/{related} [post]
+func (g *APIGenerator) createRelatedHandler(modelInfo ModelInfo, fk ForeignKeyInfo) gin.HandlerFunc {
+	relatedModelInfo := g.Models[fk.RelatedModel]
+	return func(c *gin.Context) {
+		// Check if the parent record exists
+		parentInstance, ok := g.loadParent(c, modelInfo)
+		if !ok {
+			return
+		}
+
+		// Bind the request body to a new related instance
+		instance := reflect.New(relatedModelInfo.Type).Interface()
+		if err := g.bindBody(c, relatedModelInfo, instance); err != nil {
+			g.respondBindError(c, relatedModelInfo, err)
+			return
+		}
+		if err := g.transformRequest(c, relatedModelInfo, instance); err != nil {
+			g.respondError(c, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		// Point the new record at its parent, overriding the body
+		if err := fk.setBackReference(modelInfo, relatedModelInfo, instance, parentInstance); err != nil {
+			g.respondError(c, http.StatusInternalServerError, err)
+			return
+		}
+		if relatedModelInfo.LockVersion {
+			setVersion(instance, 1)
+		}
+		if err := g.setTenant(c, relatedModelInfo, instance); err != nil {
+			g.respondError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := runHook(relatedModelInfo.Hooks.BeforeCreate, c, instance); err != nil {
+			g.respondError(c, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		// Create the record in the database
+		if err := g.writeDB(c, relatedModelInfo).Create(instance).Error; err != nil {
+			g.respondDBError(c, err)
+			return
+		}
+
+		if err := runHook(relatedModelInfo.Hooks.AfterCreate, c, instance); err != nil {
+			g.respondError(c, http.StatusInternalServerError, err)
+			return
+		}
+		g.audit(c, relatedModelInfo, nil, instance)
+
+		// Return the created instance along with its own URL
+		if !g.isDryRun(c) {
+			g.invalidateCache(relatedModelInfo)
+			if location := resourceLocation(g.mountPath+"/api/"+relatedModelInfo.PluralName, instance, relatedModelInfo); location != "" {
+				c.Header("Location", location)
+			}
+		}
+		g.respond(c, http.StatusCreated, relatedModelInfo, instance)
+	}
+}
+
+// loadParent loads the parent record addressed by the :id of a relationship route,
+// answering 404 when it doesn't exist
+func (g *APIGenerator) loadParent(c *gin.Context, modelInfo ModelInfo) (any, bool) {
+	id := c.Param("id")
+	if id == "" {
+		g.respondError(c, http.StatusBadRequest, errors.New("ID is required"))
+		return nil, false
+	}
+
+	keyScope, err := g.primaryKeyScope(modelInfo, id)
+	if err != nil {
+		g.respondError(c, http.StatusBadRequest, err)
+		return nil, false
+	}
+
+	parentInstance := reflect.New(modelInfo.Type).Interface()
+	if err := g.modelDB(c, modelInfo).Scopes(keyScope).First(parentInstance).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			g.respondError(c, http.StatusNotFound, errors.New("Parent record not found"))
+			return nil, false
+		}
+		g.respondError(c, http.StatusInternalServerError, err)
+		return nil, false
 	}
+	return parentInstance, true
 }
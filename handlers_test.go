@@ -0,0 +1,18 @@
+package apigen
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestCount(t *testing.T) {
+	g, router := newTestAPI(t, nil, &testUser{})
+	g.DB.Create(&[]testUser{{Name: "Alice"}, {Name: "Bob"}, {Name: "Alice"}})
+
+	for path, want := range map[string]int64{"/api/test_users/count": 3, "/api/test_users/count?name=Alice": 2} {
+		w := serve(router, http.MethodGet, path, "")
+		if got := decode[map[string]int64](t, w)["count"]; w.Code != http.StatusOK || got != want {
+			t.Errorf("%s: got %d %d, want %d", path, w.Code, got, want)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package apigen
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// canReplaceRelated reports whether the has-one record of a parent can be created or
+// replaced through the relationship route: the related model must be registered,
+// accept PUT and point back at the parent
+func canReplaceRelated(models map[string]ModelInfo, modelInfo ModelInfo, fk ForeignKeyInfo) bool {
+	relatedModelInfo, ok := models[fk.RelatedModel]
+	if !ok || !relatedModelInfo.verbEnabled(http.MethodPut) {
+		return false
+	}
+	_, _, ok = fk.backReference(modelInfo, relatedModelInfo)
+	return ok
+}
+
+// hasOneScope matches the has-one record of a parent, e.g. profiles.user_id = 1
+func (g *APIGenerator) hasOneScope(modelInfo, relatedModelInfo ModelInfo, fk ForeignKeyInfo, parent any) (func(*gorm.DB) *gorm.DB, error) {
+	parentID, err := primaryKeyValue(parent, modelInfo)
+	if err != nil {
+		return nil, err
+	}
+	column := clause.Column{Table: relatedModelInfo.TableName, Name: g.DB.NamingStrategy.ColumnName("", fk.RelatedField)}
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Table(relatedModelInfo.TableName).Where(clause.Eq{Column: column, Value: parentID})
+	}, nil
+}
+
+// relatedOneHandler returns a handler function for getting the has-one record of a model
+// @Summary Get a has-one related model
+// @Description Get the single model instance related to the specified model, e.g. the profile of a user
+// @Tags API
+// @Produce json,application/vnd.api+json
+// @Param id path string true "ID of the parent model instance"
+// @Success 200 {object} any
+// @Failure 404 {object} map[string]string
+// @Router /api/{model}/{id}/{related} [get]
+func (g *APIGenerator) relatedOneHandler(modelInfo ModelInfo, fk ForeignKeyInfo) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Check if the parent record exists
+		parentInstance, ok := g.loadParent(c, modelInfo)
+		if !ok {
+			return
+		}
+
+		// Get the related model info
+		relatedModelInfo, exists := g.Models[fk.RelatedModel]
+		if !exists {
+			g.respondError(c, http.StatusInternalServerError, fmt.Errorf("Related model %s not registered", fk.RelatedModel))
+			return
+		}
+
+		// Query the database for the record pointing back at the parent
+		instance := reflect.New(relatedModelInfo.Type).Interface()
+		scope, err := g.hasOneScope(modelInfo, relatedModelInfo, fk, parentInstance)
+		if err != nil {
+			g.respondError(c, http.StatusInternalServerError, err)
+			return
+		}
+		if err := g.modelDB(c, relatedModelInfo).Scopes(scope).First(instance).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				g.respondError(c, http.StatusNotFound, fmt.Errorf("%s has no %s", modelInfo.ResourceName, fk.routeName()))
+				return
+			}
+			g.respondError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		// Return the instance
+		g.respond(c, http.StatusOK, relatedModelInfo, instance)
+	}
+}
+
+// replaceRelatedHandler returns a handler function for creating or replacing the has-one
+// record of a model
+// @Summary Create or replace a has-one related model
+// @Description Create the single model instance related to the specified model, or update the existing one
+// @Tags API
+// @Accept json,x-www-form-urlencoded,mpfd
+// @Produce json,application/vnd.api+json
+// @Param id path string true "ID of the parent model instance"
+// @Param model body any true "Related model instance"
+// @Success 200 {object} any
+// @Success 201 {object} any
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 413 {object} map[string]string
+// @Router /api/{model}/{id}/{related} [put]
+func (g *APIGenerator) replaceRelatedHandler(modelInfo ModelInfo, fk ForeignKeyInfo) gin.HandlerFunc {
+	relatedModelInfo := g.Models[fk.RelatedModel]
+	return func(c *gin.Context) {
+		// Check if the parent record exists
+		parentInstance, ok := g.loadParent(c, modelInfo)
+		if !ok {
+			return
+		}
+
+		// Load the current related record, if any, which the body updates
+		instance := reflect.New(relatedModelInfo.Type).Interface()
+		scope, err := g.hasOneScope(modelInfo, relatedModelInfo, fk, parentInstance)
+		if err != nil {
+			g.respondError(c, http.StatusInternalServerError, err)
+			return
+		}
+		err = g.modelDB(c, relatedModelInfo).Scopes(scope).First(instance).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			g.respondError(c, http.StatusInternalServerError, err)
+			return
+		}
+		replace := err == nil
+		if policy := relatedModelInfo.Policy; policy != nil {
+			allowed := policy.CanCreate(c)
+			if replace {
+				allowed = policy.CanUpdate(c, recordID(instance, relatedModelInfo))
+			}
+			if !allowed {
+				g.respondError(c, http.StatusForbidden, errForbidden)
+				return
+			}
+		}
+		var before map[string]any
+		if replace && g.auditLogger != nil {
+			before = snapshot(instance, relatedModelInfo)
+		}
+
+		// Bind the request body to the related record
+		key := primaryKey(instance, relatedModelInfo)
+		if err := g.bindBody(c, relatedModelInfo, instance); err != nil {
+			g.respondBindError(c, relatedModelInfo, err)
+			return
+		}
+		if err := g.transformRequest(c, relatedModelInfo, instance); err != nil {
+			g.respondError(c, http.StatusUnprocessableEntity, err)
+			return
+		}
+		if replace && !reflect.DeepEqual(primaryKey(instance, relatedModelInfo), key) {
+			g.respondError(c, http.StatusConflict, errors.New("ID in the request body does not match the related record"))
+			return
+		}
+
+		// Point the record at its parent, overriding the body
+		if err := fk.setBackReference(modelInfo, relatedModelInfo, instance, parentInstance); err != nil {
+			g.respondError(c, http.StatusInternalServerError, err)
+			return
+		}
+		if !replace && relatedModelInfo.LockVersion {
+			setVersion(instance, 1)
+		}
+		if err := g.setTenant(c, relatedModelInfo, instance); err != nil {
+			g.respondError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		beforeHook, afterHook := relatedModelInfo.Hooks.BeforeCreate, relatedModelInfo.Hooks.AfterCreate
+		if replace {
+			beforeHook, afterHook = relatedModelInfo.Hooks.BeforeUpdate, relatedModelInfo.Hooks.AfterUpdate
+		}
+		if err := runHook(beforeHook, c, instance); err != nil {
+			g.respondError(c, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		// Save the record in the database
+		if replace {
+			err = g.writeDB(c, relatedModelInfo).Save(instance).Error
+		} else {
+			err = g.writeDB(c, relatedModelInfo).Create(instance).Error
+		}
+		if err != nil {
+			g.respondDBError(c, err)
+			return
+		}
+
+		if err := runHook(afterHook, c, instance); err != nil {
+			g.respondError(c, http.StatusInternalServerError, err)
+			return
+		}
+		g.audit(c, relatedModelInfo, before, instance)
+
+		// Return the saved instance, along with its own URL when it was created
+		status := http.StatusOK
+		if !replace {
+			status = http.StatusCreated
+		}
+		if !g.isDryRun(c) {
+			g.invalidateCache(relatedModelInfo)
+			if location := resourceLocation(g.mountPath+"/api/"+relatedModelInfo.PluralName, instance, relatedModelInfo); location != "" && !replace {
+				c.Header("Location", location)
+			}
+		}
+		g.respond(c, status, relatedModelInfo, instance)
+	}
+}
@@ -0,0 +1,51 @@
+package apigen
+
+import (
+	"net/http"
+	"testing"
+)
+
+type testCitizen struct {
+	ID       uint          `json:"id" gorm:"primaryKey"`
+	Name     string        `json:"name"`
+	Passport *testPassport `json:"passport,omitempty" gorm:"foreignKey:CitizenID"`
+}
+
+type testPassport struct {
+	ID        uint   `json:"id" gorm:"primaryKey"`
+	CitizenID uint   `json:"citizen_id"`
+	Number    string `json:"number"`
+}
+
+func TestHasOneRelation(t *testing.T) {
+	g, router := newTestAPI(t, nil, &testCitizen{}, &testPassport{})
+	g.DB.Create(&[]testCitizen{{Name: "Ada"}, {Name: "Grace"}})
+	g.DB.Create(&testPassport{CitizenID: 1, Number: "A1"})
+
+	w := serve(router, http.MethodGet, "/api/test_citizens/1/passport", "")
+	if passport := decode[map[string]any](t, w); w.Code != http.StatusOK || passport["number"] != "A1" {
+		t.Errorf("get: got %d %s", w.Code, w.Body)
+	}
+	if w := serve(router, http.MethodGet, "/api/test_citizens/2/passport", ""); w.Code != http.StatusNotFound {
+		t.Errorf("missing passport: got %d, want 404", w.Code)
+	}
+	if w := serve(router, http.MethodGet, "/api/test_citizens/3/passport", ""); w.Code != http.StatusNotFound {
+		t.Errorf("missing citizen: got %d, want 404", w.Code)
+	}
+
+	// PUT creates the record, then replaces it, pointing it at the parent
+	w = serve(router, http.MethodPut, "/api/test_citizens/2/passport", `{"number":"B2","citizen_id":1}`)
+	if passport := decode[testPassport](t, w); w.Code != http.StatusCreated || passport.CitizenID != 2 || w.Header().Get("Location") == "" {
+		t.Errorf("create: got %d %+v %v", w.Code, passport, w.Header())
+	}
+	w = serve(router, http.MethodPut, "/api/test_citizens/2/passport", `{"number":"B3"}`)
+	if passport := decode[testPassport](t, w); w.Code != http.StatusOK || passport.ID != 2 || passport.Number != "B3" {
+		t.Errorf("replace: got %d %+v", w.Code, passport)
+	}
+
+	var count int64
+	g.DB.Model(&testPassport{}).Where("citizen_id = ?", 2).Count(&count)
+	if count != 1 {
+		t.Errorf("got %d passports for citizen 2, want 1", count)
+	}
+}
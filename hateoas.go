@@ -0,0 +1,59 @@
+package apigen
+
+// halLinksKey is the key of the HAL links added to the records of models with HATEOAS
+const halLinksKey = "_links"
+
+// WithHATEOAS adds HAL links to the record returned by GET /api/{plural}/{id}, pointing
+// at the record itself, its collection and its relationship endpoints, e.g.
+// {"_links": {"self": {"href": "/api/users/1"}, "posts": {...}}}. The links are prefixed
+// with APIGenerator.BaseURL.
+func WithHATEOAS() ModelOption {
+	return func(info *ModelInfo) {
+		info.EnableHATEOAS = true
+	}
+}
+
+// resourceLinks returns the HAL links of a record: self, collection, and one per
+// relationship endpoint of the model, named after its route
+func (g *APIGenerator) resourceLinks(modelInfo ModelInfo, instance any) map[string]any {
+	collection := g.mountPath + "/api/" + modelInfo.PluralName
+	self := resourceLocation(collection, instance, modelInfo)
+	href := func(path string) map[string]any {
+		return map[string]any{"href": g.BaseURL + path}
+	}
+
+	links := map[string]any{
+		"self":       href(self),
+		"collection": href(collection),
+	}
+	if modelInfo.hasCompositePrimaryKey() {
+		return links // Relationship endpoints address the parent by a single :id
+	}
+	for _, fk := range modelInfo.ForeignKeys {
+		if fk.RelatedModel == "" {
+			continue
+		}
+		if _, registered := g.Models[fk.RelatedModel]; !registered && fk.RelationType == RelationPolymorphic {
+			continue
+		}
+		links[fk.routeName()] = href(self + "/" + fk.routeName())
+	}
+	return links
+}
+
+// addLinksProperty adds the HAL links of a model with HATEOAS as a read-only property
+func addLinksProperty(properties *OrderedProperties, modelInfo ModelInfo) {
+	if !modelInfo.EnableHATEOAS {
+		return
+	}
+	properties.Set(halLinksKey, map[string]any{
+		"type":     "object",
+		"readOnly": true,
+		"additionalProperties": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"href": map[string]any{"type": "string", "format": "uri-reference"},
+			},
+		},
+	})
+}
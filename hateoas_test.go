@@ -0,0 +1,41 @@
+package apigen
+
+import (
+	"net/http"
+	"testing"
+)
+
+// testHALRecord holds the links of a HAL record
+type testHALRecord struct {
+	Links map[string]struct {
+		Href string `json:"href"`
+	} `json:"_links"`
+}
+
+func TestHATEOASLinks(t *testing.T) {
+	t.Run("relative", func(t *testing.T) {
+		g, router := newTestAPI(t, func(g *APIGenerator) {
+			g.RegisterModelWithOptions(&testUser{}, WithHATEOAS())
+		}, &testUser{})
+		g.DB.Create(&testUser{Name: "Alice"})
+
+		w := serve(router, http.MethodGet, "/api/test_users/1", "", "Host", "evil.example", "X-Forwarded-Proto", "javascript")
+		user := decode[testHALRecord](t, w)
+		if href := user.Links["self"].Href; href != "/api/test_users/1" {
+			t.Errorf("self link: got %q", href)
+		}
+	})
+
+	t.Run("base URL", func(t *testing.T) {
+		g, router := newTestAPI(t, func(g *APIGenerator) {
+			g.BaseURL = "https://api.example.com"
+			g.RegisterModelWithOptions(&testUser{}, WithHATEOAS())
+		}, &testUser{})
+		g.DB.Create(&testUser{Name: "Alice"})
+
+		user := decode[testHALRecord](t, serve(router, http.MethodGet, "/api/test_users/1", "", "Host", "evil.example"))
+		if href := user.Links["collection"].Href; href != "https://api.example.com/api/test_users" {
+			t.Errorf("collection link: got %q", href)
+		}
+	})
+}
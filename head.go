@@ -0,0 +1,40 @@
+package apigen
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// headResponseWriter discards the response body while counting its length, so that
+// HEAD handlers can report the Content-Length of the equivalent GET response
+type headResponseWriter struct {
+	gin.ResponseWriter
+	length int
+}
+
+// Write counts the bytes instead of sending them to the client
+func (w *headResponseWriter) Write(data []byte) (int, error) {
+	w.length += len(data)
+	return len(data), nil
+}
+
+// WriteString counts the bytes instead of sending them to the client
+func (w *headResponseWriter) WriteString(s string) (int, error) {
+	w.length += len(s)
+	return len(s), nil
+}
+
+// headOnly turns a GET handler into a HEAD handler. The GET handler runs unchanged,
+// so status code and headers are identical, but the body is never written.
+func headOnly(get gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		writer := &headResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		get(c)
+		c.Writer = writer.ResponseWriter
+
+		c.Header("Content-Length", strconv.Itoa(writer.length))
+		c.Writer.WriteHeaderNow()
+	}
+}
@@ -0,0 +1,26 @@
+package apigen
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+func TestHead(t *testing.T) {
+	g, router := newTestAPI(t, nil, &testUser{})
+	g.DB.Create(&testUser{Name: "Alice"})
+
+	for _, path := range []string{"/api/test_users", "/api/test_users/1"} {
+		get := serve(router, http.MethodGet, path, "")
+		head := serve(router, http.MethodHead, path, "")
+		if head.Code != get.Code || head.Body.Len() != 0 {
+			t.Errorf("HEAD %s: got %d with %d bytes", path, head.Code, head.Body.Len())
+		}
+		if length := head.Header().Get("Content-Length"); length != strconv.Itoa(get.Body.Len()) {
+			t.Errorf("HEAD %s: Content-Length %s, want %d", path, length, get.Body.Len())
+		}
+	}
+	if w := serve(router, http.MethodHead, "/api/test_users/2", ""); w.Code != http.StatusNotFound {
+		t.Errorf("HEAD missing record: got %d", w.Code)
+	}
+}
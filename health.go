@@ -0,0 +1,81 @@
+package apigen
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReadinessChecker is an additional dependency checked by the readiness endpoint,
+// e.g. a cache or a message broker
+type ReadinessChecker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+// RegisterHealthCheck registers GET path answering 200 while the database is reachable,
+// and 503 otherwise. It suits Kubernetes liveness probes.
+func (g *APIGenerator) RegisterHealthCheck(path string) {
+	g.Group.GET(path, func(c *gin.Context) {
+		latency, err := g.pingDB(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"status": "degraded", "db": "error", "error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"status": "ok", "db": "ok", "latency_ms": latency.Milliseconds()})
+	})
+}
+
+// RegisterReadinessCheck registers GET path answering 200 when the database and every
+// check are healthy, and 503 otherwise. It suits Kubernetes readiness probes.
+func (g *APIGenerator) RegisterReadinessCheck(path string, checks ...ReadinessChecker) {
+	g.Group.GET(path, func(c *gin.Context) {
+		ctx := c.Request.Context()
+		healthy := true
+		response := gin.H{"status": "ok", "db": "ok"}
+
+		// Check the database first
+		latency, err := g.pingDB(ctx)
+		if err != nil {
+			healthy = false
+			response["db"] = "error"
+			response["error"] = err.Error()
+		} else {
+			response["latency_ms"] = latency.Milliseconds()
+		}
+
+		// Then the user-provided dependencies
+		results := gin.H{}
+		for _, check := range checks {
+			if err := check.Check(ctx); err != nil {
+				healthy = false
+				results[check.Name()] = err.Error()
+				continue
+			}
+			results[check.Name()] = "ok"
+		}
+		if len(checks) > 0 {
+			response["checks"] = results
+		}
+
+		if !healthy {
+			response["status"] = "degraded"
+			c.JSON(http.StatusServiceUnavailable, response)
+			return
+		}
+		c.JSON(http.StatusOK, response)
+	})
+}
+
+// pingDB runs a trivial query and returns its round-trip latency
+func (g *APIGenerator) pingDB(ctx context.Context) (time.Duration, error) {
+	start := time.Now()
+	var result int
+	if err := g.DB.WithContext(ctx).Raw("SELECT 1").Scan(&result).Error; err != nil {
+		return 0, err
+	}
+	return time.Since(start), nil
+}
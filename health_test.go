@@ -0,0 +1,56 @@
+package apigen
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+// testChecker is a readiness check failing with err
+type testChecker struct {
+	name string
+	err  error
+}
+
+func (c testChecker) Name() string                    { return c.name }
+func (c testChecker) Check(ctx context.Context) error { return c.err }
+
+func TestHealthCheck(t *testing.T) {
+	g, router := newTestAPI(t, func(g *APIGenerator) {
+		g.RegisterHealthCheck("/healthz")
+	}, &testUser{})
+
+	w := serve(router, http.MethodGet, "/healthz", "")
+	body := decode[map[string]any](t, w)
+	if w.Code != http.StatusOK || body["status"] != "ok" || body["db"] != "ok" || body["latency_ms"] == nil {
+		t.Errorf("healthy: got %d %v", w.Code, body)
+	}
+
+	// Closing the connection pool makes the ping fail
+	sqlDB, err := g.DB.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.Close()
+
+	w = serve(router, http.MethodGet, "/healthz", "")
+	body = decode[map[string]any](t, w)
+	if w.Code != http.StatusServiceUnavailable || body["status"] != "degraded" || body["db"] != "error" || body["error"] == nil {
+		t.Errorf("database down: got %d %v", w.Code, body)
+	}
+}
+
+func TestReadinessCheck(t *testing.T) {
+	_, router := newTestAPI(t, func(g *APIGenerator) {
+		g.RegisterReadinessCheck("/ready", testChecker{name: "cache"}, testChecker{name: "broker", err: errors.New("unreachable")})
+	}, &testUser{})
+
+	w := serve(router, http.MethodGet, "/ready", "")
+	body := decode[map[string]any](t, w)
+	checks, _ := body["checks"].(map[string]any)
+	if w.Code != http.StatusServiceUnavailable || body["status"] != "degraded" || body["db"] != "ok" ||
+		checks["cache"] != "ok" || checks["broker"] != "unreachable" {
+		t.Errorf("failing check: got %d %v", w.Code, body)
+	}
+}
@@ -0,0 +1,65 @@
+package apigen
+
+import (
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+	"gorm.io/hints"
+)
+
+// WithIndexHints maps filterable field API names to the index queries filtering on them
+// should use, e.g. WithIndexHints(map[string]string{"email": "idx_users_email"}). Index
+// hints are MySQL syntax: other databases pick their indexes themselves and get none.
+func WithIndexHints(indexes map[string]string) ModelOption {
+	return func(info *ModelInfo) {
+		if info.IndexHints == nil {
+			info.IndexHints = make(map[string]string, len(indexes))
+		}
+		for field, index := range indexes {
+			info.IndexHints[field] = index
+		}
+	}
+}
+
+// indexHintScope returns a scope hinting the indexes of the filtered fields, or nil when
+// none has a hint or the database doesn't take hints
+func (g *APIGenerator) indexHintScope(modelInfo ModelInfo, fields []string) func(*gorm.DB) *gorm.DB {
+	if len(modelInfo.IndexHints) == 0 || g.DB.Dialector.Name() != "mysql" {
+		return nil
+	}
+
+	var indexes []string
+	seen := map[string]bool{}
+	for _, field := range fields {
+		if index, ok := modelInfo.IndexHints[field]; ok && !seen[index] {
+			seen[index] = true
+			indexes = append(indexes, index)
+		}
+	}
+	if len(indexes) == 0 {
+		return nil
+	}
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Clauses(hints.UseIndex(indexes...))
+	}
+}
+
+// logQueryPlan logs the plan of the query built by query at debug level, when
+// AnalyzeQueryPlan is set
+func (g *APIGenerator) logQueryPlan(db *gorm.DB, modelInfo ModelInfo, query func(tx *gorm.DB) *gorm.DB) {
+	if !g.AnalyzeQueryPlan {
+		return
+	}
+
+	sql := db.ToSQL(query)
+	explain := "EXPLAIN "
+	if g.DB.Dialector.Name() == "sqlite" {
+		explain = "EXPLAIN QUERY PLAN "
+	}
+
+	var plan []map[string]any
+	if err := db.Session(&gorm.Session{NewDB: true}).Raw(explain + sql).Scan(&plan).Error; err != nil {
+		log.Debug().Err(err).Str("model", modelInfo.Type.Name()).Str("query", sql).Msg("apigen: failed to explain query")
+		return
+	}
+	log.Debug().Str("model", modelInfo.Type.Name()).Str("query", sql).Interface("plan", plan).Msg("apigen: query plan")
+}
@@ -0,0 +1,35 @@
+package apigen
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+func TestIndexHintsAndQueryPlan(t *testing.T) {
+	var output bytes.Buffer
+	logger := log.Logger
+	log.Logger = zerolog.New(&output).Level(zerolog.DebugLevel)
+	t.Cleanup(func() { log.Logger = logger })
+
+	g, router := newTestAPI(t, func(g *APIGenerator) {
+		g.AnalyzeQueryPlan = true
+		g.RegisterModelWithOptions(&testUser{}, WithIndexHints(map[string]string{"email": "idx_test_users_email"}))
+	}, &testUser{})
+	g.DB.Create(&testUser{Name: "Ada", Email: "ada@example.com"})
+
+	w := serve(router, http.MethodGet, "/api/test_users?email=ada@example.com", "")
+	if users := decode[[]testUser](t, w); w.Code != http.StatusOK || len(users) != 1 {
+		t.Fatalf("filtered list: got %d %s", w.Code, w.Body)
+	}
+	if !strings.Contains(output.String(), `"message":"apigen: query plan"`) || !strings.Contains(output.String(), "test_users") {
+		t.Errorf("query plan was not logged: %s", output.String())
+	}
+	if scope := g.indexHintScope(g.Models["testUser"], []string{"email"}); scope != nil {
+		t.Error("SQLite queries got index hints")
+	}
+}
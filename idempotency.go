@@ -0,0 +1,200 @@
+package apigen
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// IdempotencyKeyHeader carries the client chosen key of a create or patch request
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// idempotencyReplayHeader marks responses replayed from the IdempotencyStore
+	idempotencyReplayHeader = "X-Idempotency-Replay"
+
+	defaultIdempotencyTTL        = 24 * time.Hour
+	defaultIdempotencyMaxEntries = 10000
+	// idempotencyPendingTTL bounds how long the key of a request that never completes,
+	// e.g. because the server stopped, stays reserved
+	idempotencyPendingTTL = time.Minute
+)
+
+var (
+	errIdempotencyKeyInUse  = errors.New("a request with this Idempotency-Key is being processed")
+	errIdempotencyKeyReused = errors.New("the Idempotency-Key was already used with another request body")
+)
+
+// StoredResponse is a response kept for the replays of an idempotent request
+type StoredResponse struct {
+	Status int         `json:"status"` // Zero while the request is being processed
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+	// RequestHash is the SHA-256 of the request body, which replays must repeat
+	RequestHash string `json:"request_hash"`
+}
+
+// IdempotencyStore keeps the responses of requests sent with an Idempotency-Key.
+// Implement it to share the keys across instances, for example in Redis.
+type IdempotencyStore interface {
+	Get(key string) (*StoredResponse, bool)
+	Set(key string, resp *StoredResponse, ttl time.Duration)
+	// Reserve stores resp unless key is already held, in one atomic step like Redis'
+	// SET NX, and reports whether it did
+	Reserve(key string, resp *StoredResponse, ttl time.Duration) bool
+	Delete(key string)
+}
+
+// IdempotencyConfig configures the replay of create and patch requests sent with an
+// Idempotency-Key header
+type IdempotencyConfig struct {
+	TTL        time.Duration    // How long a key is remembered, 24 hours by default
+	MaxEntries int              // Size of the in-memory store, 10000 by default
+	Store      IdempotencyStore // Where responses are kept, NewMemoryIdempotencyStore(MaxEntries) if nil
+}
+
+// memoryIdempotencyStore is an IdempotencyStore held in process memory
+type memoryIdempotencyStore struct {
+	mu    sync.Mutex // Makes Reserve atomic
+	cache Cache
+}
+
+// NewMemoryIdempotencyStore creates an in-memory IdempotencyStore evicting the least
+// recently used key once it holds maxEntries
+func NewMemoryIdempotencyStore(maxEntries int) IdempotencyStore {
+	return &memoryIdempotencyStore{cache: NewMemoryCache(maxEntries)}
+}
+
+// Get implements IdempotencyStore
+func (m *memoryIdempotencyStore) Get(key string) (*StoredResponse, bool) {
+	data, ok := m.cache.Get(key)
+	if !ok {
+		return nil, false
+	}
+	var resp StoredResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, false
+	}
+	return &resp, true
+}
+
+// Set implements IdempotencyStore
+func (m *memoryIdempotencyStore) Set(key string, resp *StoredResponse, ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if data, err := json.Marshal(resp); err == nil {
+		m.cache.Set(key, data, ttl)
+	}
+}
+
+// Reserve implements IdempotencyStore
+func (m *memoryIdempotencyStore) Reserve(key string, resp *StoredResponse, ttl time.Duration) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, held := m.cache.Get(key); held {
+		return false
+	}
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return false
+	}
+	m.cache.Set(key, data, ttl)
+	return true
+}
+
+// Delete implements IdempotencyStore
+func (m *memoryIdempotencyStore) Delete(key string) {
+	m.cache.Delete(key)
+}
+
+// idempotencyStore returns the store of the idempotency keys, creating the in-memory
+// one on first use
+func (g *APIGenerator) idempotencyStore() IdempotencyStore {
+	if g.Idempotency.Store == nil {
+		maxEntries := g.Idempotency.MaxEntries
+		if maxEntries <= 0 {
+			maxEntries = defaultIdempotencyMaxEntries
+		}
+		g.Idempotency.Store = NewMemoryIdempotencyStore(maxEntries)
+	}
+	return g.Idempotency.Store
+}
+
+// idempotencyMiddleware returns the middleware replaying the stored response of create
+// and patch requests repeating an Idempotency-Key. The key is reserved while the first
+// request is processed, so concurrent duplicates get 409 instead of writing twice, and
+// a key reused with another body gets 422. It returns nil for other endpoints.
+func (g *APIGenerator) idempotencyMiddleware(verb, method string) gin.HandlerFunc {
+	if method != http.MethodPatch && verb != VerbCreate && verb != VerbCreateRelated {
+		return nil
+	}
+	store := g.idempotencyStore()
+
+	ttl := g.Idempotency.TTL
+	if ttl <= 0 {
+		ttl = defaultIdempotencyTTL
+	}
+	return func(c *gin.Context) {
+		idempotencyKey := c.GetHeader(IdempotencyKeyHeader)
+		if idempotencyKey == "" || g.isDryRun(c) {
+			c.Next()
+			return
+		}
+
+		// Keys are only shared by the same caller on the same endpoint
+		key := strings.Join([]string{
+			c.Request.Method, c.Request.URL.Path, c.GetString(TenantIDKey), c.GetString(ActorIDKey), idempotencyKey,
+		}, "\n")
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			g.respondBodyError(c, err)
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		sum := sha256.Sum256(body)
+		requestHash := hex.EncodeToString(sum[:])
+
+		if !store.Reserve(key, &StoredResponse{RequestHash: requestHash}, idempotencyPendingTTL) {
+			resp, ok := store.Get(key)
+			switch {
+			case !ok || resp.Status == 0:
+				g.respondError(c, http.StatusConflict, errIdempotencyKeyInUse)
+			case resp.RequestHash != requestHash:
+				g.respondError(c, http.StatusUnprocessableEntity, errIdempotencyKeyReused)
+			default:
+				for name, values := range resp.Header {
+					c.Writer.Header()[name] = values
+				}
+				c.Header(idempotencyReplayHeader, "true")
+				c.Data(resp.Status, resp.Header.Get("Content-Type"), resp.Body)
+				c.Abort()
+			}
+			return
+		}
+
+		writer := &cacheWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+
+		// Server errors may be retried
+		if writer.Status() >= http.StatusInternalServerError {
+			store.Delete(key)
+			return
+		}
+		store.Set(key, &StoredResponse{
+			Status:      writer.Status(),
+			Header:      storableHeader(writer.Header()),
+			Body:        writer.body.Bytes(),
+			RequestHash: requestHash,
+		}, ttl)
+	}
+}
@@ -0,0 +1,48 @@
+package apigen
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestIdempotency(t *testing.T) {
+	g, router := newTestAPI(t, nil, &testUser{})
+	body := `{"name":"Alice","email":"alice@example.com"}`
+
+	first := serve(router, http.MethodPost, "/api/test_users", body, IdempotencyKeyHeader, "k1")
+	if first.Code != http.StatusCreated {
+		t.Fatalf("create: got %d %s", first.Code, first.Body)
+	}
+	replay := serve(router, http.MethodPost, "/api/test_users", body, IdempotencyKeyHeader, "k1")
+	if replay.Code != http.StatusCreated || replay.Header().Get(idempotencyReplayHeader) != "true" || replay.Body.String() != first.Body.String() {
+		t.Errorf("replay: got %d %s", replay.Code, replay.Body)
+	}
+	var count int64
+	g.DB.Model(&testUser{}).Count(&count)
+	if count != 1 {
+		t.Errorf("replay created %d records, want 1", count)
+	}
+
+	if w := serve(router, http.MethodPost, "/api/test_users", `{"name":"Bob"}`, IdempotencyKeyHeader, "k1"); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("key reused with another body: got %d %s", w.Code, w.Body)
+	}
+}
+
+func TestIdempotencyPending(t *testing.T) {
+	g, router := newTestAPI(t, nil, &testUser{})
+	body := `{"name":"Alice"}`
+
+	// A first request holding the key is still being processed
+	key := "POST\n/api/test_users\n\n\nk1"
+	if !g.idempotencyStore().Reserve(key, &StoredResponse{}, idempotencyPendingTTL) {
+		t.Fatal("reserve: key already held")
+	}
+	if w := serve(router, http.MethodPost, "/api/test_users", body, IdempotencyKeyHeader, "k1"); w.Code != http.StatusConflict {
+		t.Errorf("concurrent duplicate: got %d %s", w.Code, w.Body)
+	}
+	var count int64
+	g.DB.Model(&testUser{}).Count(&count)
+	if count != 0 {
+		t.Errorf("concurrent duplicate created %d records", count)
+	}
+}
@@ -0,0 +1,266 @@
+package apigen
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm/schema"
+)
+
+// Dialects supported by InfraGenerator and GoTypeToSQL
+const (
+	DialectPostgres = "postgres"
+	DialectMySQL    = "mysql"
+	DialectSQLite   = "sqlite"
+)
+
+// InfraGenerator generates the database side of a generated API: a docker-compose.yml
+// running the app with its database, and the SQL creating the tables of the models
+type InfraGenerator struct {
+	AppName string // Name of the app service and of the database, "app" by default
+	AppPort int    // Port the app listens on, 8080 by default
+}
+
+// NewInfraGenerator creates a new InfraGenerator
+func NewInfraGenerator(appName string) *InfraGenerator {
+	if appName == "" {
+		appName = "app"
+	}
+	return &InfraGenerator{AppName: appName, AppPort: 8080}
+}
+
+// normalizeDialect maps the usual driver names to a dialect, e.g. "postgresql" and
+// "pgx" to postgres
+func normalizeDialect(dialect string) string {
+	switch strings.ToLower(dialect) {
+	case "postgres", "postgresql", "pgx", "pg":
+		return DialectPostgres
+	case "mysql", "mariadb":
+		return DialectMySQL
+	default:
+		return DialectSQLite
+	}
+}
+
+// GenerateDockerCompose returns a docker-compose.yml running the app, built from the
+// Dockerfile of the current directory, and a postgres or mysql database. SQLite apps
+// get a volume for their database file instead.
+func (g *InfraGenerator) GenerateDockerCompose(dbDriver string) string {
+	var doc strings.Builder
+	doc.WriteString("services:\n")
+	fmt.Fprintf(&doc, "  %s:\n", g.AppName)
+	doc.WriteString("    build: .\n")
+	fmt.Fprintf(&doc, "    ports:\n      - \"%d:%d\"\n", g.AppPort, g.AppPort)
+	doc.WriteString("    environment:\n")
+
+	switch normalizeDialect(dbDriver) {
+	case DialectPostgres:
+		doc.WriteString("      DB_DRIVER: postgres\n")
+		fmt.Fprintf(&doc, "      DATABASE_URL: postgres://%[1]s:%[1]s@db:5432/%[1]s?sslmode=disable\n", g.AppName)
+		doc.WriteString("    depends_on:\n      db:\n        condition: service_healthy\n")
+		doc.WriteString("  db:\n")
+		doc.WriteString("    image: postgres:16\n")
+		doc.WriteString("    environment:\n")
+		fmt.Fprintf(&doc, "      POSTGRES_USER: %s\n      POSTGRES_PASSWORD: %s\n      POSTGRES_DB: %s\n", g.AppName, g.AppName, g.AppName)
+		doc.WriteString("    healthcheck:\n")
+		fmt.Fprintf(&doc, "      test: [\"CMD\", \"pg_isready\", \"-U\", \"%s\"]\n", g.AppName)
+		doc.WriteString("      interval: 5s\n      retries: 10\n")
+		doc.WriteString("    volumes:\n      - db-data:/var/lib/postgresql/data\n")
+	case DialectMySQL:
+		doc.WriteString("      DB_DRIVER: mysql\n")
+		fmt.Fprintf(&doc, "      DATABASE_URL: %[1]s:%[1]s@tcp(db:3306)/%[1]s?parseTime=true\n", g.AppName)
+		doc.WriteString("    depends_on:\n      db:\n        condition: service_healthy\n")
+		doc.WriteString("  db:\n")
+		doc.WriteString("    image: mysql:8\n")
+		doc.WriteString("    environment:\n")
+		fmt.Fprintf(&doc, "      MYSQL_USER: %s\n      MYSQL_PASSWORD: %s\n      MYSQL_DATABASE: %s\n", g.AppName, g.AppName, g.AppName)
+		doc.WriteString("      MYSQL_RANDOM_ROOT_PASSWORD: \"yes\"\n")
+		doc.WriteString("    healthcheck:\n")
+		doc.WriteString("      test: [\"CMD\", \"mysqladmin\", \"ping\", \"-h\", \"localhost\"]\n")
+		doc.WriteString("      interval: 5s\n      retries: 10\n")
+		doc.WriteString("    volumes:\n      - db-data:/var/lib/mysql\n")
+	default:
+		doc.WriteString("      DB_DRIVER: sqlite\n")
+		fmt.Fprintf(&doc, "      DATABASE_URL: /data/%s.db\n", g.AppName)
+		doc.WriteString("    volumes:\n      - db-data:/data\n")
+	}
+
+	doc.WriteString("volumes:\n  db-data:\n")
+	return doc.String()
+}
+
+// GenerateMigrationScript returns the CREATE TABLE statements of the models, with their
+// primary keys and the foreign keys to the other models of the script
+func (g *InfraGenerator) GenerateMigrationScript(models []ModelInfo, dialect string) string {
+	dialect = normalizeDialect(dialect)
+	namer := schema.NamingStrategy{}
+
+	tables := make(map[string]ModelInfo, len(models))
+	for _, modelInfo := range models {
+		tables[modelInfo.Type.Name()] = modelInfo
+	}
+
+	var doc strings.Builder
+	for i, modelInfo := range models {
+		if i > 0 {
+			doc.WriteString("\n")
+		}
+		table := modelInfo.TableName
+		if table == "" {
+			table = tableName(modelInfo.Type, namer)
+		}
+
+		fields := migrationFields(modelInfo)
+		keys := primaryKeyFields(modelInfo.Type, fields)
+		column := func(field FieldInfo) string {
+			if field.Column != "" {
+				return field.Column
+			}
+			return namer.ColumnName("", field.Name)
+		}
+
+		// A single integer key is generated by the database
+		autoIncrement := ""
+		if len(keys) == 1 && isIntegerKind(keys[0].Type.Kind()) {
+			autoIncrement = keys[0].Name
+		}
+
+		var definitions []string
+		for _, field := range fields {
+			sqlType := GoTypeToSQL(field.Type, dialect)
+			if sqlType == "" {
+				continue // Associations live in their own table
+			}
+
+			definition := column(field) + " "
+			switch {
+			case field.Name == autoIncrement && dialect == DialectPostgres:
+				definition += "BIGSERIAL PRIMARY KEY"
+			case field.Name == autoIncrement && dialect == DialectMySQL:
+				definition += sqlType + " NOT NULL AUTO_INCREMENT PRIMARY KEY"
+			case field.Name == autoIncrement:
+				definition += "INTEGER PRIMARY KEY AUTOINCREMENT"
+			case len(keys) == 1 && field.Name == keys[0].Name:
+				definition += sqlType + " NOT NULL PRIMARY KEY"
+			default:
+				definition += sqlType
+			}
+			definitions = append(definitions, definition)
+		}
+
+		if len(keys) > 1 {
+			columns := make([]string, 0, len(keys))
+			for _, key := range keys {
+				columns = append(columns, column(key))
+			}
+			definitions = append(definitions, fmt.Sprintf("PRIMARY KEY (%s)", strings.Join(columns, ", ")))
+		}
+		for _, fk := range modelInfo.ForeignKeys {
+			related, ok := tables[fk.RelatedModel]
+			if !ok || fk.RelationshipID == "" {
+				continue
+			}
+			relatedTable := related.TableName
+			if relatedTable == "" {
+				relatedTable = tableName(related.Type, namer)
+			}
+			definitions = append(definitions, fmt.Sprintf("FOREIGN KEY (%s) REFERENCES %s (id)", namer.ColumnName("", fk.RelationshipID), relatedTable))
+		}
+
+		fmt.Fprintf(&doc, "CREATE TABLE %s (\n    %s\n);\n", table, strings.Join(definitions, ",\n    "))
+	}
+	return doc.String()
+}
+
+// migrationFields returns the fields stored in the table of a model: those promoted
+// from embedded structs without a json tag, e.g. gorm.Model, then the API fields
+func migrationFields(modelInfo ModelInfo) []FieldInfo {
+	var fields []FieldInfo
+	var collect func(t reflect.Type)
+	collect = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.Anonymous && field.Tag.Get("json") == "" && field.Type.Kind() == reflect.Struct {
+				collect(field.Type)
+				continue
+			}
+			if t != modelInfo.Type && field.IsExported() && field.Tag.Get("json") != "-" {
+				fields = append(fields, FieldInfo{
+					Name:     field.Name,
+					Type:     field.Type,
+					IsID:     field.Name == "ID",
+					Column:   gormColumn(field),
+					Nullable: field.Type.Kind() == reflect.Ptr,
+				})
+			}
+		}
+	}
+	collect(modelInfo.Type)
+	return append(fields, modelInfo.Fields...)
+}
+
+// isIntegerKind reports whether a kind is a signed or unsigned integer
+func isIntegerKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// GoTypeToSQL returns the column type of a Go type in a dialect, e.g. TIMESTAMPTZ for
+// time.Time in postgres, or "" for types not stored in a column such as associations
+func GoTypeToSQL(t reflect.Type, dialect string) string {
+	dialect = normalizeDialect(dialect)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	pick := func(postgres, mysql, sqlite string) string {
+		switch dialect {
+		case DialectPostgres:
+			return postgres
+		case DialectMySQL:
+			return mysql
+		default:
+			return sqlite
+		}
+	}
+
+	switch {
+	case t.String() == "time.Time", t.String() == "gorm.DeletedAt":
+		return pick("TIMESTAMPTZ", "DATETIME(3)", "DATETIME")
+	case t == uuidType:
+		return pick("UUID", "CHAR(36)", "TEXT")
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		return pick("BYTEA", "LONGBLOB", "BLOB")
+	case isJSONObject(t):
+		return pick("JSONB", "JSON", "TEXT")
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return pick("BOOLEAN", "BOOLEAN", "NUMERIC")
+	case reflect.Int8, reflect.Int16:
+		return pick("SMALLINT", "SMALLINT", "INTEGER")
+	case reflect.Int32:
+		return pick("INTEGER", "INT", "INTEGER")
+	case reflect.Int, reflect.Int64:
+		return pick("BIGINT", "BIGINT", "INTEGER")
+	case reflect.Uint8, reflect.Uint16:
+		return pick("INTEGER", "SMALLINT UNSIGNED", "INTEGER")
+	case reflect.Uint32:
+		return pick("BIGINT", "INT UNSIGNED", "INTEGER")
+	case reflect.Uint, reflect.Uint64:
+		return pick("BIGINT", "BIGINT UNSIGNED", "INTEGER")
+	case reflect.Float32:
+		return pick("REAL", "FLOAT", "REAL")
+	case reflect.Float64:
+		return pick("DOUBLE PRECISION", "DOUBLE", "REAL")
+	case reflect.String:
+		return pick("TEXT", "VARCHAR(255)", "TEXT")
+	}
+	return ""
+}
@@ -0,0 +1,73 @@
+package apigen
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerateMigrationScript(t *testing.T) {
+	g := New(nil, nil)
+	g.RegisterModelWithOptions(&Blog{})
+	g.RegisterModelWithOptions(&Post{})
+	models := []ModelInfo{g.Models["Blog"], g.Models["Post"]}
+	infra := NewInfraGenerator("blog")
+
+	script := infra.GenerateMigrationScript(models, "postgresql")
+	for _, want := range []string{
+		"CREATE TABLE blogs (\n    id BIGSERIAL PRIMARY KEY,\n    name TEXT\n);\n",
+		"CREATE TABLE posts (\n    id BIGSERIAL PRIMARY KEY,\n    blog_id BIGINT,\n    title TEXT,\n    FOREIGN KEY (blog_id) REFERENCES blogs (id)\n);\n",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("postgres script misses %q:\n%s", want, script)
+		}
+	}
+
+	// The SQLite script runs as is
+	db := newTestDB(t)
+	for _, statement := range strings.Split(infra.GenerateMigrationScript(models, "sqlite"), ";\n") {
+		if strings.TrimSpace(statement) == "" {
+			continue
+		}
+		if err := db.Exec(statement).Error; err != nil {
+			t.Errorf("sqlite statement %q: %v", statement, err)
+		}
+	}
+	if !db.Migrator().HasTable("posts") {
+		t.Error("the sqlite script created no posts table")
+	}
+}
+
+func TestGenerateDockerCompose(t *testing.T) {
+	infra := NewInfraGenerator("blog")
+	for driver, want := range map[string]string{
+		"postgres": "image: postgres:16",
+		"mariadb":  "image: mysql:8",
+		"sqlite":   "DATABASE_URL: /data/blog.db",
+	} {
+		compose := infra.GenerateDockerCompose(driver)
+		if !strings.HasPrefix(compose, "services:\n  blog:\n    build: .\n") || !strings.Contains(compose, want) {
+			t.Errorf("%s compose file misses %q:\n%s", driver, want, compose)
+		}
+	}
+}
+
+func TestGoTypeToSQL(t *testing.T) {
+	for _, tc := range []struct {
+		value   any
+		dialect string
+		want    string
+	}{
+		{time.Time{}, "postgres", "TIMESTAMPTZ"},
+		{new(string), "mysql", "VARCHAR(255)"},
+		{true, "sqlite", "NUMERIC"},
+		{map[string]any{}, "pgx", "JSONB"},
+		{[]byte{}, "mysql", "LONGBLOB"},
+		{[]testTag{}, "postgres", ""},
+	} {
+		if got := GoTypeToSQL(reflect.TypeOf(tc.value), tc.dialect); got != tc.want {
+			t.Errorf("GoTypeToSQL(%T, %s): got %q, want %q", tc.value, tc.dialect, got, tc.want)
+		}
+	}
+}
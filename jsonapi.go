@@ -0,0 +1,95 @@
+package apigen
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JSONAPIMediaType is the Accept header value selecting JSON:API formatted responses
+const JSONAPIMediaType = "application/vnd.api+json"
+
+// wantsJSONAPI reports whether the client asked for a JSON:API document
+func wantsJSONAPI(c *gin.Context) bool {
+	for _, mediaType := range strings.Split(c.GetHeader("Accept"), ",") {
+		if strings.TrimSpace(mediaType) == JSONAPIMediaType {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonAPIDocument wraps an instance, or a slice of instances as a resource collection,
+// in a JSON:API document
+func (g *APIGenerator) jsonAPIDocument(payload any, modelInfo ModelInfo, meta map[string]any) gin.H {
+	value := reflect.Indirect(reflect.ValueOf(payload))
+	if value.Kind() != reflect.Slice {
+		return gin.H{"data": toJSONAPI(payload, modelInfo, g.mountPath)}
+	}
+
+	data := make([]map[string]any, 0, value.Len())
+	for i := 0; i < value.Len(); i++ {
+		data = append(data, toJSONAPI(value.Index(i).Interface(), modelInfo, g.mountPath))
+	}
+
+	documentMeta := gin.H{"total": value.Len()}
+	for key, v := range meta {
+		documentMeta[key] = v
+	}
+	return gin.H{"data": data, "meta": documentMeta}
+}
+
+// ToJSONAPI converts a model instance to a JSON:API resource object. The type is the
+// model's plural name, the attributes hold every JSON field except the ID and the
+// relationships, and each relationship links to its related endpoint.
+func ToJSONAPI(instance any, modelInfo ModelInfo) map[string]any {
+	return toJSONAPI(instance, modelInfo, "")
+}
+
+// toJSONAPI converts a model instance to a JSON:API resource object linking to the
+// endpoints of an API mounted on mountPath
+func toJSONAPI(instance any, modelInfo ModelInfo, mountPath string) map[string]any {
+	id := recordID(instance, modelInfo)
+
+	// Round-trip through JSON so the attributes honour the model's json tags
+	attributes := snapshot(instance, modelInfo)
+	for _, field := range modelInfo.Fields {
+		if field.Name == modelInfo.PrimaryKeyField.Name {
+			delete(attributes, field.JSONName)
+		}
+	}
+
+	resource := map[string]any{
+		"type":       modelInfo.PluralName,
+		"id":         id,
+		"attributes": attributes,
+	}
+
+	relationships := map[string]any{}
+	for _, fk := range modelInfo.ForeignKeys {
+		if fk.RelatedModel == "" {
+			continue
+		}
+
+		name := toSnakeCase(fk.FieldName)
+		for _, field := range modelInfo.Fields {
+			if field.Name == fk.FieldName {
+				name = field.JSONName
+			}
+		}
+		delete(attributes, name)
+
+		relationships[name] = map[string]any{
+			"links": map[string]any{
+				"related": fmt.Sprintf("%s/api/%s/%s/%s", mountPath, modelInfo.PluralName, id, fk.routeName()),
+			},
+		}
+	}
+	if len(relationships) > 0 {
+		resource["relationships"] = relationships
+	}
+
+	return resource
+}
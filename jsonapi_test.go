@@ -0,0 +1,40 @@
+package apigen
+
+import (
+	"net/http"
+	"testing"
+)
+
+// testJSONAPIDocument is a JSON:API document holding one resource
+type testJSONAPIDocument struct {
+	Data struct {
+		Type       string         `json:"type"`
+		ID         string         `json:"id"`
+		Attributes map[string]any `json:"attributes"`
+	} `json:"data"`
+}
+
+func TestJSONAPI(t *testing.T) {
+	g, router := newTestAPI(t, nil, &testUser{})
+	g.DB.Create(&testUser{Name: "Alice", Email: "alice@example.com"})
+
+	w := serve(router, http.MethodGet, "/api/test_users/1", "", "Accept", JSONAPIMediaType)
+	if w.Header().Get("Content-Type") != JSONAPIMediaType {
+		t.Errorf("Content-Type: got %s", w.Header().Get("Content-Type"))
+	}
+	document := decode[testJSONAPIDocument](t, w)
+	if document.Data.Type != "test_users" || document.Data.ID != "1" || document.Data.Attributes["name"] != "Alice" {
+		t.Errorf("resource: got %+v", document.Data)
+	}
+	if _, ok := document.Data.Attributes["id"]; ok {
+		t.Error("the ID is repeated in the attributes")
+	}
+
+	list := decode[struct {
+		Data []map[string]any `json:"data"`
+		Meta map[string]any   `json:"meta"`
+	}](t, serve(router, http.MethodGet, "/api/test_users", "", "Accept", JSONAPIMediaType))
+	if len(list.Data) != 1 || list.Meta["total"] != float64(1) {
+		t.Errorf("collection: got %+v", list)
+	}
+}
@@ -0,0 +1,56 @@
+package apigen
+
+import (
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// lastModifiedFieldName is the struct field holding the modification time of a model
+const lastModifiedFieldName = "UpdatedAt"
+
+// WithLastModified enables the Last-Modified and If-Modified-Since headers on the
+// get endpoint of a model, based on its UpdatedAt field
+func WithLastModified() ModelOption {
+	return func(info *ModelInfo) {
+		info.EnableLastModified = true
+	}
+}
+
+// lastModifiedTime returns the UpdatedAt time of a model instance. It reports false
+// when the model has no UpdatedAt time.Time field or the time is not set.
+func lastModifiedTime(instance any, modelInfo ModelInfo) (time.Time, bool) {
+	for _, field := range modelInfo.Fields {
+		if field.Name != lastModifiedFieldName || field.Type != reflect.TypeOf(time.Time{}) {
+			continue
+		}
+
+		modified := reflect.Indirect(reflect.ValueOf(instance)).FieldByName(field.Name).Interface().(time.Time)
+		return modified, !modified.IsZero()
+	}
+	return time.Time{}, false
+}
+
+// notModified sets the Last-Modified header of a model instance and reports whether
+// the client's copy, as described by If-Modified-Since, is still fresh
+func notModified(c *gin.Context, instance any, modelInfo ModelInfo) bool {
+	if !modelInfo.EnableLastModified {
+		return false
+	}
+
+	modified, ok := lastModifiedTime(instance, modelInfo)
+	if !ok {
+		return false
+	}
+	c.Header("Last-Modified", modified.UTC().Format(http.TimeFormat))
+
+	since, err := http.ParseTime(c.GetHeader("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+
+	// HTTP dates only have second precision
+	return !modified.Truncate(time.Second).After(since)
+}
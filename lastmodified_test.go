@@ -0,0 +1,33 @@
+package apigen
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+// testPage has a modification time
+type testPage struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Title     string    `json:"title"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func TestLastModified(t *testing.T) {
+	g, router := newTestAPI(t, func(g *APIGenerator) {
+		g.RegisterModelWithOptions(&testPage{}, WithLastModified())
+	}, &testPage{})
+	modified := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	g.DB.Create(&testPage{Title: "Home", UpdatedAt: modified})
+
+	w := serve(router, http.MethodGet, "/api/test_pages/1", "")
+	if got := w.Header().Get("Last-Modified"); w.Code != http.StatusOK || got != modified.Format(http.TimeFormat) {
+		t.Errorf("get: got %d, Last-Modified %q", w.Code, got)
+	}
+	if w := serve(router, http.MethodGet, "/api/test_pages/1", "", "If-Modified-Since", modified.Format(http.TimeFormat)); w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("unchanged: got %d %s", w.Code, w.Body)
+	}
+	if w := serve(router, http.MethodGet, "/api/test_pages/1", "", "If-Modified-Since", modified.Add(-time.Hour).Format(http.TimeFormat)); w.Code != http.StatusOK {
+		t.Errorf("changed: got %d", w.Code)
+	}
+}
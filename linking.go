@@ -0,0 +1,99 @@
+package apigen
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// HTTP methods of RFC 2068 associating two resources
+const (
+	MethodLink   = "LINK"
+	MethodUnlink = "UNLINK"
+)
+
+// WithLinkUnlink registers LINK and UNLINK /api/{plural}/{id}/{related}/{related_id} for
+// the many-to-many associations of a model, which add and remove the association
+// between two existing records without a request body
+func WithLinkUnlink() ModelOption {
+	return func(info *ModelInfo) {
+		info.EnableLinkUnlink = true
+	}
+}
+
+// canLink reports whether the records of a many-to-many association can be linked
+// through the relationship route: the model must enable it and the related model
+// must be registered
+func canLink(models map[string]ModelInfo, modelInfo ModelInfo, fk ForeignKeyInfo) bool {
+	if !modelInfo.EnableLinkUnlink || fk.RelationType != RelationManyToMany {
+		return false
+	}
+	_, ok := models[fk.RelatedModel]
+	return ok
+}
+
+// linkHandler returns a handler function adding, or removing when link is false, the
+// association between a record and a related one
+// @Summary Link or unlink a many-to-many related model
+// @Description Add (LINK) or remove (UNLINK) the association between a model instance and a related one
+// @Tags API
+// @Produce json
+// @Param id path string true "ID of the parent model instance"
+// @Param related_id path string true "ID of the related model instance"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/{model}/{id}/{related}/{related_id} [link]
+func (g *APIGenerator) linkHandler(modelInfo ModelInfo, fk ForeignKeyInfo, link bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Check if the parent record exists
+		parentInstance, ok := g.loadParent(c, modelInfo)
+		if !ok {
+			return
+		}
+
+		// Check if the related record exists
+		relatedModelInfo, exists := g.Models[fk.RelatedModel]
+		if !exists {
+			g.respondError(c, http.StatusInternalServerError, fmt.Errorf("Related model %s not registered", fk.RelatedModel))
+			return
+		}
+		keyScope, err := g.primaryKeyScope(relatedModelInfo, c.Param("related_id"))
+		if err != nil {
+			g.respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		related := reflect.New(relatedModelInfo.Type).Interface()
+		if err := g.modelDB(c, relatedModelInfo).Scopes(keyScope).First(related).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				g.respondError(c, http.StatusNotFound, errors.New("Related record not found"))
+				return
+			}
+			g.respondError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		// Write the join table only, leaving both records as they are
+		db := g.dbFromContext(c)
+		if g.isDryRun(c) {
+			c.Header(dryRunHeader, "true")
+			db = db.Session(&gorm.Session{DryRun: true})
+		}
+		association := db.Model(parentInstance).Omit(fk.FieldName + ".*").Association(fk.FieldName)
+		if link {
+			err = association.Append(related)
+		} else {
+			err = association.Delete(related)
+		}
+		if err != nil {
+			g.respondDBError(c, err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,58 @@
+package apigen
+
+import (
+	"net/http"
+	"testing"
+)
+
+// articleTags returns the tags listed by the relationship endpoint of an article
+func articleTags(t *testing.T, router http.Handler, path string) []testTag {
+	t.Helper()
+	return decode[[]testTag](t, serve(router, http.MethodGet, path, ""))
+}
+
+func TestLinkUnlink(t *testing.T) {
+	g, router := newTestAPI(t, func(g *APIGenerator) {
+		g.RegisterModelWithOptions(&testArticle{}, WithLinkUnlink())
+		g.RegisterModelWithOptions(&testTag{})
+	}, &testArticle{}, &testTag{})
+	g.DB.Create(&testArticle{Title: "Linked"})
+	g.DB.Create(&[]testTag{{Name: "go"}, {Name: "sql"}})
+
+	if w := serve(router, MethodLink, "/api/test_articles/1/tags/2", ""); w.Code != http.StatusNoContent {
+		t.Fatalf("link: got %d %s", w.Code, w.Body)
+	}
+	// Linking twice keeps a single association
+	if w := serve(router, MethodLink, "/api/test_articles/1/tags/2", ""); w.Code != http.StatusNoContent {
+		t.Errorf("link again: got %d %s", w.Code, w.Body)
+	}
+	if tags := articleTags(t, router, "/api/test_articles/1/tags"); len(tags) != 1 || tags[0].Name != "sql" {
+		t.Errorf("after link: got %+v", tags)
+	}
+
+	if w := serve(router, MethodLink, "/api/test_articles/1/tags/9", ""); w.Code != http.StatusNotFound {
+		t.Errorf("link of a missing tag: got %d, want 404", w.Code)
+	}
+	if w := serve(router, MethodLink, "/api/test_articles/9/tags/1", ""); w.Code != http.StatusNotFound {
+		t.Errorf("link to a missing article: got %d, want 404", w.Code)
+	}
+
+	if w := serve(router, MethodUnlink, "/api/test_articles/1/tags/2", ""); w.Code != http.StatusNoContent {
+		t.Fatalf("unlink: got %d %s", w.Code, w.Body)
+	}
+	if tags := articleTags(t, router, "/api/test_articles/1/tags"); len(tags) != 0 {
+		t.Errorf("after unlink: got %+v", tags)
+	}
+	var count int64
+	g.DB.Model(&testTag{}).Count(&count)
+	if count != 2 {
+		t.Errorf("unlink deleted the tag: got %d tags", count)
+	}
+}
+
+func TestLinkUnlinkDisabled(t *testing.T) {
+	_, router := newTestAPI(t, nil, &testArticle{}, &testTag{})
+	if w := serve(router, MethodLink, "/api/test_articles/1/tags/1", ""); w.Code != http.StatusNotFound && w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("link without WithLinkUnlink: got %d", w.Code)
+	}
+}
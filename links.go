@@ -0,0 +1,90 @@
+package apigen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// PaginationLinkStyle selects how list endpoints report pagination
+type PaginationLinkStyle int
+
+const (
+	// JSONEnvelope reports the page and limit in the meta of the response body
+	JSONEnvelope PaginationLinkStyle = iota
+	// LinkHeaders reports the first, prev, next and last pages in an RFC 5988 Link header
+	LinkHeaders
+	// Both reports the pagination in the body and in the Link header
+	Both
+)
+
+// totalCountHeader reports the number of records matching a list query
+const totalCountHeader = "X-Total-Count"
+
+// paginate sets the X-Total-Count header, and the Link header of a page of results
+// when the link style asks for it, counting the matching records with query, and
+// returns the pagination meta of the response body
+func (g *APIGenerator) paginate(c *gin.Context, page pagination, query *gorm.DB) (map[string]any, error) {
+	return g.paginateCount(c, page, func() (int64, error) {
+		var total int64
+		err := query.Count(&total).Error
+		return total, err
+	})
+}
+
+// paginateCount works like paginate, counting the matching records with count, which
+// is only called when the headers need the total
+func (g *APIGenerator) paginateCount(c *gin.Context, page pagination, count func() (int64, error)) (map[string]any, error) {
+	links := page.Active && g.PaginationLinkStyle != JSONEnvelope
+	if !links && !g.TotalCountHeader {
+		return page.Meta(), nil
+	}
+
+	total, err := count()
+	if err != nil {
+		return nil, err
+	}
+	if g.TotalCountHeader {
+		c.Header(totalCountHeader, strconv.FormatInt(total, 10))
+	}
+	if !links {
+		return page.Meta(), nil
+	}
+	c.Header("Link", g.paginationLinks(c, page, total))
+
+	if g.PaginationLinkStyle == LinkHeaders {
+		return nil, nil
+	}
+	return page.Meta(), nil
+}
+
+// paginationLinks returns the Link header value pointing at the first, previous,
+// next and last pages, e.g. </api/users?page=3>; rel="next", prefixed with BaseURL
+func (g *APIGenerator) paginationLinks(c *gin.Context, page pagination, total int64) string {
+	base := g.BaseURL + c.Request.URL.Path
+
+	link := func(number int, rel string) string {
+		query := c.Request.URL.Query()
+		query.Set("page", strconv.Itoa(number))
+		query.Set("limit", strconv.Itoa(page.Limit))
+		return fmt.Sprintf("<%s?%s>; rel=%q", base, query.Encode(), rel)
+	}
+
+	last := int((total + int64(page.Limit) - 1) / int64(page.Limit))
+	if last < 1 {
+		last = 1
+	}
+
+	links := []string{link(1, "first")}
+	if page.Page > 1 {
+		links = append(links, link(min(page.Page-1, last), "prev"))
+	}
+	if page.Page < last {
+		links = append(links, link(page.Page+1, "next"))
+	}
+	links = append(links, link(last, "last"))
+	return strings.Join(links, ", ")
+}
@@ -0,0 +1,48 @@
+package apigen
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestPaginationLinks(t *testing.T) {
+	g, router := newTestAPI(t, func(g *APIGenerator) {
+		g.PaginationLinkStyle = LinkHeaders
+	}, &testUser{})
+	g.DB.Create(&[]testUser{{Name: "Alice"}, {Name: "Bob"}, {Name: "Carol"}})
+
+	w := serve(router, http.MethodGet, "/api/test_users?page=2&limit=1", "", "Host", "evil.example")
+	link := w.Header().Get("Link")
+	if !strings.Contains(link, `</api/test_users?limit=1&page=3>; rel="next"`) || strings.Contains(link, "evil.example") {
+		t.Errorf("Link: got %q", link)
+	}
+}
+
+func TestTotalCountHeader(t *testing.T) {
+	g, router := newTestAPI(t, func(g *APIGenerator) {
+		g.TotalCountHeader = true
+		g.WithEnvelope(Envelope{})
+	}, &testUser{})
+	g.DB.Create(&[]testUser{{Name: "Alice"}, {Name: "Bob"}, {Name: "Carol"}})
+
+	w := serve(router, http.MethodGet, "/api/test_users?page=1&limit=2", "")
+	body := decode[map[string]any](t, w)
+	if w.Header().Get(totalCountHeader) != "3" || body["meta"] == nil {
+		t.Errorf("list: got %s %v", w.Header().Get(totalCountHeader), body)
+	}
+
+	serve(router, http.MethodDelete, "/api/test_users/1", "")
+	if w := serve(router, http.MethodGet, "/api/test_users?name=Bob", ""); w.Header().Get(totalCountHeader) != "1" {
+		t.Errorf("filtered list: got %s", w.Header().Get(totalCountHeader))
+	}
+	if w := serve(router, http.MethodGet, "/api/test_users", ""); w.Header().Get(totalCountHeader) != "2" {
+		t.Errorf("list after delete: got %s", w.Header().Get(totalCountHeader))
+	}
+
+	swagger := NewSwaggerGenerator(g.Models)
+	swagger.TotalCountHeader = true
+	if headers, ok := swagger.listResponse("testUser")["headers"].(map[string]any); !ok || headers[totalCountHeader] == nil {
+		t.Errorf("swagger list response misses the header: %v", headers)
+	}
+}
@@ -0,0 +1,37 @@
+package apigen
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestLocationHeader(t *testing.T) {
+	t.Run("integer", func(t *testing.T) {
+		_, router := newTestAPI(t, nil, &testUser{})
+
+		w := serve(router, http.MethodPost, "/api/test_users", `{"name":"Ada"}`)
+		if w.Code != http.StatusCreated || w.Header().Get("Location") != "/api/test_users/1" {
+			t.Errorf("create: got %d with Location %q", w.Code, w.Header().Get("Location"))
+		}
+	})
+
+	t.Run("uuid", func(t *testing.T) {
+		g, router := newTestAPI(t, nil, &testDevice{})
+		g.DB.Callback().Create().Before("gorm:create").Register("apigen:uuid", UUIDPrimaryKey())
+
+		w := serve(router, http.MethodPost, "/api/test_devices", `{"name":"sensor"}`)
+		created := decode[testDevice](t, w)
+		if w.Code != http.StatusCreated || w.Header().Get("Location") != "/api/test_devices/"+created.ID {
+			t.Errorf("create: got %d with Location %q", w.Code, w.Header().Get("Location"))
+		}
+	})
+
+	t.Run("composite", func(t *testing.T) {
+		_, router := newTestAPI(t, nil, &testMembership{})
+
+		w := serve(router, http.MethodPost, "/api/test_memberships", `{"user_id":1,"group_id":7}`)
+		if w.Code != http.StatusCreated || w.Header().Get("Location") != "/api/test_memberships/1/7" {
+			t.Errorf("create: got %d with Location %q", w.Code, w.Header().Get("Location"))
+		}
+	})
+}
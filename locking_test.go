@@ -0,0 +1,41 @@
+package apigen
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// testDraft is updated with optimistic locking
+type testDraft struct {
+	ID      uint   `json:"id" gorm:"primaryKey"`
+	Title   string `json:"title"`
+	Version int    `json:"version"`
+}
+
+func TestOptimisticLocking(t *testing.T) {
+	_, router := newTestAPI(t, func(g *APIGenerator) {
+		g.RegisterModelWithOptions(&testDraft{}, WithOptimisticLocking())
+	}, &testDraft{})
+
+	w := serve(router, http.MethodPost, "/api/test_drafts", `{"title":"First","version":7}`)
+	if draft := decode[testDraft](t, w); w.Code != http.StatusCreated || draft.Version != 1 {
+		t.Fatalf("create: got %d %+v", w.Code, draft)
+	}
+
+	w = serve(router, http.MethodPut, "/api/test_drafts/1", `{"id":1,"title":"Second","version":1}`)
+	if draft := decode[testDraft](t, w); w.Code != http.StatusOK || draft.Version != 2 {
+		t.Errorf("update: got %d %+v", w.Code, draft)
+	}
+	if w := serve(router, http.MethodPut, "/api/test_drafts/1", `{"id":1,"title":"Stale","version":1}`); w.Code != http.StatusConflict {
+		t.Errorf("stale update: got %d %s", w.Code, w.Body)
+	}
+}
+
+func TestOptimisticLockingWithoutVersion(t *testing.T) {
+	g := New(newTestDB(t), gin.New())
+	if err := g.RegisterModelWithOptions(&testUser{}, WithOptimisticLocking()); err == nil {
+		t.Error("register a model without Version field: got no error")
+	}
+}
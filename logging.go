@@ -0,0 +1,116 @@
+package apigen
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// ModelNameKey is the gin context key holding the name of the model a request is served for
+	ModelNameKey = "apigen.model"
+	// requestIDHeader carries the ID correlating a request with its log entry
+	requestIDHeader = "X-Request-ID"
+)
+
+// LogEntry describes a request served by the API
+type LogEntry struct {
+	Method     string `json:"method"`
+	Path       string `json:"path"`
+	ModelName  string `json:"model_name,omitempty"`
+	StatusCode int    `json:"status_code"`
+	LatencyMs  int64  `json:"latency_ms"`
+	RequestID  string `json:"request_id"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Logger records the requests served by the API
+type Logger interface {
+	Log(entry LogEntry)
+}
+
+// jsonLogger writes log entries as newline-delimited JSON
+type jsonLogger struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+// JSONLogger returns a Logger writing one JSON object per line to w
+func JSONLogger(w io.Writer) Logger {
+	return &jsonLogger{encoder: json.NewEncoder(w)}
+}
+
+// Log writes the entry to the underlying writer
+func (l *jsonLogger) Log(entry LogEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_ = l.encoder.Encode(entry)
+}
+
+// RequestLoggerMiddleware logs the method, path, model, status and latency of every request.
+// The request ID is the one set by RequestIDMiddleware, or else taken from the
+// X-Request-ID header, or generated, and echoed back.
+func RequestLoggerMiddleware(logger Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		requestID := c.GetString(RequestIDKey)
+		if requestID == "" {
+			requestID = c.GetHeader(requestIDHeader)
+			if !validRequestID(requestID) {
+				requestID = newRequestID()
+			}
+			c.Set(RequestIDKey, requestID)
+			c.Header(requestIDHeader, requestID)
+		}
+
+		c.Next()
+
+		entry := LogEntry{
+			Method:     c.Request.Method,
+			Path:       c.Request.URL.Path,
+			ModelName:  c.GetString(ModelNameKey),
+			StatusCode: c.Writer.Status(),
+			LatencyMs:  time.Since(start).Milliseconds(),
+			RequestID:  requestID,
+		}
+		if err := c.Errors.Last(); err != nil {
+			entry.Error = err.Error()
+		}
+		logger.Log(entry)
+	}
+}
+
+// newRequestID returns a random 128-bit request ID
+func newRequestID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(id)
+}
+
+// loggingMiddleware returns the request logging middleware of a model's endpoints,
+// or nil when logging is disabled
+func (g *APIGenerator) loggingMiddleware(modelInfo ModelInfo) gin.HandlerFunc {
+	if !g.EnableLogging {
+		return nil
+	}
+
+	logger := g.Logger
+	if logger == nil {
+		logger = JSONLogger(os.Stdout)
+	}
+	log := RequestLoggerMiddleware(logger)
+
+	return func(c *gin.Context) {
+		c.Set(ModelNameKey, modelInfo.Type.Name())
+		log(c)
+	}
+}
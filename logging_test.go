@@ -0,0 +1,50 @@
+package apigen
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestRequestLogging(t *testing.T) {
+	var output bytes.Buffer
+	_, router := newTestAPI(t, func(g *APIGenerator) {
+		g.EnableLogging = true
+		g.Logger = JSONLogger(&output)
+	}, &testUser{})
+
+	w := serve(router, http.MethodGet, "/api/test_users/1", "", requestIDHeader, "req-42")
+	if w.Header().Get(requestIDHeader) != "req-42" {
+		t.Errorf("request ID: got %q", w.Header().Get(requestIDHeader))
+	}
+	serve(router, http.MethodGet, "/api/test_users", "")
+
+	decoder := json.NewDecoder(&output)
+	var missing, listed LogEntry
+	if err := decoder.Decode(&missing); err != nil {
+		t.Fatalf("first log line: %v", err)
+	}
+	if missing.Method != http.MethodGet || missing.Path != "/api/test_users/1" || missing.ModelName != "testUser" ||
+		missing.StatusCode != http.StatusNotFound || missing.RequestID != "req-42" || missing.Error == "" {
+		t.Errorf("first entry: %+v", missing)
+	}
+	if err := decoder.Decode(&listed); err != nil {
+		t.Fatalf("second log line: %v", err)
+	}
+	if listed.StatusCode != http.StatusOK || len(listed.RequestID) != 32 || listed.Error != "" {
+		t.Errorf("second entry: %+v", listed)
+	}
+}
+
+func TestLoggingDisabled(t *testing.T) {
+	var output bytes.Buffer
+	_, router := newTestAPI(t, func(g *APIGenerator) {
+		g.Logger = JSONLogger(&output)
+	}, &testUser{})
+
+	serve(router, http.MethodGet, "/api/test_users", "")
+	if output.Len() != 0 {
+		t.Errorf("logged without EnableLogging: %s", output.String())
+	}
+}
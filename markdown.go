@@ -0,0 +1,187 @@
+package apigen
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultMarkdownBaseURL is the server address used in the curl examples
+const defaultMarkdownBaseURL = "http://localhost:8080"
+
+// MarkdownGenerator generates a Markdown API reference for the registered models
+type MarkdownGenerator struct {
+	Models  map[string]ModelInfo
+	BaseURL string // Server address used in the curl examples
+}
+
+// NewMarkdownGenerator creates a new MarkdownGenerator
+func NewMarkdownGenerator(models map[string]ModelInfo) *MarkdownGenerator {
+	return &MarkdownGenerator{
+		Models:  models,
+		BaseURL: defaultMarkdownBaseURL,
+	}
+}
+
+// markdownEndpoint describes one endpoint in the reference
+type markdownEndpoint struct {
+	Method      string
+	Path        string
+	Description string
+	Body        bool // Whether the endpoint takes a request body
+}
+
+// Generate returns the GitHub-flavoured Markdown reference, with a section per model
+func (g *MarkdownGenerator) Generate() string {
+	var doc strings.Builder
+	doc.WriteString("# API Reference\n")
+
+	// Sort the models so the document is stable
+	names := make([]string, 0, len(g.Models))
+	for name := range g.Models {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		modelInfo := g.Models[name]
+
+		fmt.Fprintf(&doc, "\n## %s\n\n", modelInfo.PluralName)
+		fmt.Fprintf(&doc, "Model `%s`, served under `/api/%s`.\n", name, modelInfo.PluralName)
+
+		doc.WriteString("\n### Request fields\n\n")
+		doc.WriteString("| Field | Type | Required |\n")
+		doc.WriteString("| --- | --- | --- |\n")
+		for _, field := range requestFields(modelInfo) {
+			required := "no"
+			if !field.OmitEmpty {
+				required = "yes"
+			}
+			fmt.Fprintf(&doc, "| `%s` | %s | %s |\n", field.JSONName, getTypeName(field.Type), required)
+		}
+
+		doc.WriteString("\n### Response fields\n\n")
+		doc.WriteString("| Field | Type |\n")
+		doc.WriteString("| --- | --- |\n")
+		for _, field := range modelInfo.Fields {
+			fmt.Fprintf(&doc, "| `%s` | %s |\n", field.JSONName, getTypeName(field.Type))
+		}
+
+		doc.WriteString("\n### Endpoints\n")
+		for _, endpoint := range g.endpoints(modelInfo) {
+			fmt.Fprintf(&doc, "\n#### `%s %s`\n\n%s\n\n", endpoint.Method, endpoint.Path, endpoint.Description)
+			doc.WriteString("```bash\n")
+			doc.WriteString(g.curlExample(modelInfo, endpoint))
+			doc.WriteString("\n```\n")
+		}
+	}
+
+	return doc.String()
+}
+
+// endpoints lists the endpoints generated for a model
+func (g *MarkdownGenerator) endpoints(modelInfo ModelInfo) []markdownEndpoint {
+	basePath := "/api/" + modelInfo.PluralName
+	instancePath := basePath + instanceSwaggerPath(modelInfo)
+
+	var endpoints []markdownEndpoint
+	if modelInfo.verbEnabled(http.MethodGet) {
+		endpoints = append(endpoints,
+			markdownEndpoint{Method: http.MethodGet, Path: basePath, Description: "List all " + modelInfo.PluralName + "."},
+			markdownEndpoint{Method: http.MethodGet, Path: basePath + "/search?q={term}", Description: "Search " + modelInfo.PluralName + "."},
+		)
+		if !modelInfo.DisableCount {
+			endpoints = append(endpoints, markdownEndpoint{Method: http.MethodGet, Path: basePath + "/count", Description: "Count " + modelInfo.PluralName + "."})
+		}
+		endpoints = append(endpoints, markdownEndpoint{Method: http.MethodGet, Path: instancePath, Description: "Get a " + modelInfo.ResourceName + "."})
+	}
+	if modelInfo.verbEnabled(http.MethodPost) {
+		endpoints = append(endpoints, markdownEndpoint{Method: http.MethodPost, Path: basePath, Description: "Create a " + modelInfo.ResourceName + ".", Body: true})
+	}
+	if modelInfo.verbEnabled(http.MethodPut) {
+		endpoints = append(endpoints, markdownEndpoint{Method: http.MethodPut, Path: instancePath, Description: "Update a " + modelInfo.ResourceName + ".", Body: true})
+	}
+	if modelInfo.verbEnabled(http.MethodDelete) {
+		endpoints = append(endpoints, markdownEndpoint{Method: http.MethodDelete, Path: instancePath, Description: "Delete a " + modelInfo.ResourceName + "."})
+	}
+
+	return endpoints
+}
+
+// curlExample returns a curl command calling an endpoint
+func (g *MarkdownGenerator) curlExample(modelInfo ModelInfo, endpoint markdownEndpoint) string {
+	// Fill the path parameters with placeholder values
+	path := endpoint.Path
+	for _, field := range modelInfo.PrimaryKeyFields {
+		path = strings.ReplaceAll(path, "{"+field.JSONName+"}", "1")
+	}
+	path = strings.ReplaceAll(path, "{id}", "1")
+	path = strings.ReplaceAll(path, "{term}", "example")
+
+	command := fmt.Sprintf("curl -X %s '%s%s'", endpoint.Method, g.BaseURL, path)
+	if !endpoint.Body {
+		return command
+	}
+
+	body := make(map[string]any)
+	for _, field := range requestFields(modelInfo) {
+		body[field.JSONName] = exampleValue(field.Type)
+	}
+	data, _ := json.MarshalIndent(body, "", "  ")
+
+	return fmt.Sprintf("%s \\\n  -H 'Content-Type: application/json' \\\n  -d '%s'", command, data)
+}
+
+// requestFields returns the fields accepted in create requests
+func requestFields(modelInfo ModelInfo) []FieldInfo {
+	var fields []FieldInfo
+	for _, field := range modelInfo.Fields {
+		if !field.IsID {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// exampleValue returns a placeholder value of a Go type for the curl examples
+func exampleValue(t reflect.Type) any {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t.String() == "time.Time":
+		return "2024-01-01T00:00:00Z"
+	case t == uuidType:
+		return "00000000-0000-0000-0000-000000000000"
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return false
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return 0
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		return []any{}
+	case reflect.Map:
+		return map[string]any{}
+	default:
+		return nil
+	}
+}
+
+// ServeMarkdown serves the Markdown API reference of the registered models at path
+func (g *APIGenerator) ServeMarkdown(path string) {
+	g.Group.GET(path, func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/markdown; charset=utf-8", []byte(NewMarkdownGenerator(g.Models).Generate()))
+	})
+}
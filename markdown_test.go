@@ -0,0 +1,39 @@
+package apigen
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestMarkdownGenerator(t *testing.T) {
+	g, router := newTestAPI(t, func(g *APIGenerator) {
+		g.RegisterModelWithOptions(&testUser{})
+		g.RegisterModelWithOptions(&testStory{})
+		g.ServeMarkdown("/docs/api.md")
+	}, &testUser{}, &testStory{})
+
+	doc := NewMarkdownGenerator(g.Models).Generate()
+	for _, want := range []string{
+		"# API Reference\n",
+		"\n## test_users\n",
+		"\n## stories\n",
+		"| Field | Type | Required |\n| --- | --- | --- |\n",
+		"| `name` | string | yes |\n",
+		"#### `GET /api/test_users/{id}`",
+		"curl -X POST 'http://localhost:8080/api/test_users'",
+		"```bash\n",
+	} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("markdown misses %q:\n%s", want, doc)
+		}
+	}
+	if strings.Index(doc, "## stories") > strings.Index(doc, "## test_users") {
+		t.Error("models are not sorted by name")
+	}
+
+	w := serve(router, http.MethodGet, "/docs/api.md", "")
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/markdown") || w.Body.String() != doc {
+		t.Errorf("served markdown: got %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+}
@@ -0,0 +1,137 @@
+package apigen
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// mergePatchContentType is the media type of RFC 7396 JSON merge patches
+const mergePatchContentType = "application/merge-patch+json"
+
+// mergePatchHandler returns a handler function applying a JSON merge patch to an
+// instance of a model: null values reset fields to their zero value and absent keys
+// leave them unchanged
+// @Summary Patch a model instance
+// @Description Apply an RFC 7396 JSON merge patch to an instance of a model
+// @Tags API
+// @Accept application/merge-patch+json,json
+// @Produce json,application/vnd.api+json
+// @Param id path string true "ID of the model instance"
+// @Param patch body object true "Fields to set, null to reset them"
+// @Param Idempotency-Key header string false "Replays the stored response of a request sent with the same key"
+// @Success 200 {object} any
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 413 {object} map[string]string
+// @Failure 415 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Router /api/{model}/{id} [patch]
+func (g *APIGenerator) mergePatchHandler(modelInfo ModelInfo) gin.HandlerFunc {
+	update := g.modifyHandler(modelInfo, func(c *gin.Context, instance any) (map[string]bool, error) {
+		if modelInfo.LockVersion {
+			setVersion(instance, 0)
+		}
+		keys, err := g.bindMergePatch(c, modelInfo, instance)
+		if err != nil {
+			return nil, err
+		}
+		if modelInfo.LockVersion && getVersion(instance) == 0 {
+			return nil, fmt.Errorf("version is required")
+		}
+		return keys, nil
+	})
+
+	return func(c *gin.Context) {
+		if contentType := c.ContentType(); contentType != mergePatchContentType && contentType != binding.MIMEJSON {
+			g.respondError(c, http.StatusUnsupportedMediaType, fmt.Errorf("PATCH requires Content-Type %s", mergePatchContentType))
+			return
+		}
+		update(c)
+	}
+}
+
+// bindMergePatch applies the merge patch in the request body to an instance and
+// validates the result. It returns the API fields named by the patch.
+func (g *APIGenerator) bindMergePatch(c *gin.Context, modelInfo ModelInfo, instance any) (map[string]bool, error) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return nil, err
+	}
+	patch := map[string]any{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&patch); err != nil {
+		return nil, errors.New("merge patch must be a JSON object")
+	}
+	if g.StrictSchemaValidation {
+		if err := unknownFields(patch, modelInfo); err != nil {
+			return nil, err
+		}
+	}
+
+	value := reflect.ValueOf(instance).Elem()
+	keys := make(map[string]bool, len(patch))
+	for key, patchValue := range patch {
+		field, ok := modelInfo.fieldByJSONName(key)
+		if !ok || modelInfo.isReadOnly(field) {
+			continue // Unknown and read-only keys are ignored, as they are by PUT
+		}
+		keys[key] = true
+
+		target := value.FieldByName(field.Name)
+		if patchValue == nil {
+			target.Set(reflect.Zero(target.Type()))
+			continue
+		}
+		if object, ok := patchValue.(map[string]any); ok && isJSONObject(target.Type()) {
+			// Objects are merged recursively with the stored object
+			existing, _ := target.Interface().(map[string]any)
+			patchValue = mergeJSON(existing, object)
+		}
+
+		data, err := json.Marshal(patchValue)
+		if err != nil {
+			return nil, err
+		}
+		decoded := reflect.New(target.Type())
+		if err := json.Unmarshal(data, decoded.Interface()); err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %w", key, err)
+		}
+		target.Set(decoded.Elem())
+	}
+
+	if binding.Validator == nil {
+		return keys, nil
+	}
+	return keys, binding.Validator.ValidateStruct(instance)
+}
+
+// mergeJSON applies a merge patch to a JSON object as RFC 7396 describes, returning a
+// new object
+func mergeJSON(target, patch map[string]any) map[string]any {
+	merged := make(map[string]any, len(target)+len(patch))
+	for key, value := range target {
+		merged[key] = value
+	}
+	for key, value := range patch {
+		switch value := value.(type) {
+		case nil:
+			delete(merged, key)
+		case map[string]any:
+			existing, _ := merged[key].(map[string]any)
+			merged[key] = mergeJSON(existing, value)
+		default:
+			merged[key] = value
+		}
+	}
+	return merged
+}
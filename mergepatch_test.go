@@ -0,0 +1,33 @@
+package apigen
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestMergePatch(t *testing.T) {
+	g, router := newTestAPI(t, nil, &testUser{})
+	g.DB.Create(&testUser{Name: "Ada", Email: "ada@example.com"})
+
+	w := serve(router, http.MethodPatch, "/api/test_users/1", `{"email":null}`, "Content-Type", mergePatchContentType)
+	if w.Code != http.StatusOK {
+		t.Fatalf("merge patch: got %d %s", w.Code, w.Body)
+	}
+	var user testUser
+	g.DB.First(&user, 1)
+	if user.Email != "" || user.Name != "Ada" {
+		t.Errorf("after merge patch: %+v", user)
+	}
+
+	if w := serve(router, http.MethodPatch, "/api/test_users/1", `name=Grace`, "Content-Type", "application/x-www-form-urlencoded"); w.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("form body: got %d, want 415", w.Code)
+	}
+
+	swagger := NewSwaggerGenerator(g.Models)
+	swagger.BuildPathsForAllModels()
+	patch := swagger.GenerateAllPaths()["/api/test_users/{id}"].(map[string]any)["patch"].(map[string]any)
+	consumes, _ := patch["consumes"].([]string)
+	if len(consumes) == 0 || consumes[0] != mergePatchContentType {
+		t.Errorf("swagger PATCH consumes: got %v", patch["consumes"])
+	}
+}
@@ -0,0 +1,81 @@
+package apigen
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/rs/zerolog/log"
+)
+
+// Metrics holds the Prometheus collectors recording the requests served by the
+// generated endpoints, labelled by the model's resource name
+type Metrics struct {
+	Requests *prometheus.CounterVec   // apigen_http_requests_total{model,method,status}
+	Duration *prometheus.HistogramVec // apigen_http_request_duration_seconds{model,method}
+}
+
+// NewMetrics creates the request metrics and registers them with reg. Collectors
+// already registered by another generator are reused.
+func NewMetrics(reg prometheus.Registerer) (*Metrics, error) {
+	requests := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "apigen_http_requests_total",
+		Help: "Number of requests served by the generated endpoints.",
+	}, []string{"model", "method", "status"})
+	duration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "apigen_http_request_duration_seconds",
+		Help:    "Latency of the requests served by the generated endpoints.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"model", "method"})
+
+	if err := reg.Register(requests); err != nil {
+		var registered prometheus.AlreadyRegisteredError
+		if !errors.As(err, &registered) {
+			return nil, err
+		}
+		requests = registered.ExistingCollector.(*prometheus.CounterVec)
+	}
+	if err := reg.Register(duration); err != nil {
+		var registered prometheus.AlreadyRegisteredError
+		if !errors.As(err, &registered) {
+			return nil, err
+		}
+		duration = registered.ExistingCollector.(*prometheus.HistogramVec)
+	}
+
+	return &Metrics{Requests: requests, Duration: duration}, nil
+}
+
+// RegisterMetricsEndpoint registers GET path serving the metrics of the default
+// Prometheus registry
+func (g *APIGenerator) RegisterMetricsEndpoint(path string) {
+	g.Group.GET(path, gin.WrapH(promhttp.Handler()))
+}
+
+// metricsMiddleware returns the middleware recording the metrics of a model's
+// endpoints, or nil when metrics are disabled
+func (g *APIGenerator) metricsMiddleware(modelInfo ModelInfo, method string) gin.HandlerFunc {
+	if !g.EnableMetrics {
+		return nil
+	}
+	if g.Metrics == nil {
+		metrics, err := NewMetrics(prometheus.DefaultRegisterer)
+		if err != nil {
+			log.Error().Err(err).Msg("apigen: failed to register metrics")
+			return nil
+		}
+		g.Metrics = metrics
+	}
+
+	metrics := g.Metrics
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		metrics.Requests.WithLabelValues(modelInfo.ResourceName, method, strconv.Itoa(c.Writer.Status())).Inc()
+		metrics.Duration.WithLabelValues(modelInfo.ResourceName, method).Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,52 @@
+package apigen
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	metrics, err := NewMetrics(registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, router := newTestAPI(t, func(g *APIGenerator) {
+		g.EnableMetrics = true
+		g.Metrics = metrics
+	}, &testUser{})
+	resource := g.Models["testUser"].ResourceName
+
+	for range 3 {
+		serve(router, http.MethodGet, "/api/test_users", "")
+	}
+	serve(router, http.MethodGet, "/api/test_users/1", "")
+	if count := testutil.ToFloat64(metrics.Requests.WithLabelValues(resource, http.MethodGet, "200")); count != 3 {
+		t.Errorf("200 responses: got %v, want 3", count)
+	}
+	if count := testutil.ToFloat64(metrics.Requests.WithLabelValues(resource, http.MethodGet, "404")); count != 1 {
+		t.Errorf("404 responses: got %v, want 1", count)
+	}
+
+	// Closing the connection pool makes the queries fail
+	sqlDB, err := g.DB.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.Close()
+	serve(router, http.MethodGet, "/api/test_users", "")
+	if count := testutil.ToFloat64(metrics.Requests.WithLabelValues(resource, http.MethodGet, "500")); count != 1 {
+		t.Errorf("500 responses: got %v, want 1", count)
+	}
+	if count := testutil.CollectAndCount(metrics.Duration); count != 1 {
+		t.Errorf("duration series: got %d, want 1", count)
+	}
+
+	// A second registration reuses the collectors
+	if again, err := NewMetrics(registry); err != nil || again.Requests != metrics.Requests {
+		t.Errorf("registering again: got %v %v", again, err)
+	}
+}
@@ -0,0 +1,134 @@
+package apigen
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/rs/zerolog/log"
+)
+
+// pathSegment matches the unreserved characters of a URL path segment
+var pathSegment = regexp.MustCompile(`^[A-Za-z0-9._~-]+$`)
+
+// ConfigError describes a misconfigured model found when it is registered, or when
+// the API is generated
+type ConfigError struct {
+	Model   string // Name of the model type
+	Field   string // JSON name of the field at fault, if any
+	Message string // Problem description, e.g. "has no primary key"
+	Warning bool   // Whether the model can still be served, e.g. for a relation to a model that isn't registered
+}
+
+// Error names the model and field of the problem
+func (e ConfigError) Error() string {
+	if e.Field != "" {
+		return fmt.Sprintf("model %s: field %s %s", e.Model, e.Field, e.Message)
+	}
+	return fmt.Sprintf("model %s %s", e.Model, e.Message)
+}
+
+// ValidateModelInfo checks a model before it is registered: it needs a primary key,
+// unique JSON field names, a resource name usable as a path segment, a plural name no
+// other registered model uses, ordered and deprecated fields that exist, and default
+// values fitting their fields.
+func (g *APIGenerator) ValidateModelInfo(info ModelInfo) []ConfigError {
+	name := info.Type.Name()
+	var problems []ConfigError
+	add := func(field, message string) {
+		problems = append(problems, ConfigError{Model: name, Field: field, Message: message})
+	}
+
+	if len(info.PrimaryKeyFields) == 0 && info.PrimaryKeyField.Type == nil {
+		add("", `has no primary key, add an ID field or tag one with gorm:"primaryKey"`)
+	}
+
+	seen := make(map[string]bool, len(info.Fields))
+	for _, field := range info.Fields {
+		if seen[field.JSONName] {
+			add(field.JSONName, "is declared more than once")
+		}
+		seen[field.JSONName] = true
+	}
+	for _, field := range info.ComputedFields {
+		if seen[field.JSONName] {
+			add(field.JSONName, "is computed but also declared by the model")
+		}
+		seen[field.JSONName] = true
+	}
+
+	if !pathSegment.MatchString(info.ResourceName) {
+		add("", fmt.Sprintf("resource name %q is not a valid URL path segment", info.ResourceName))
+	}
+	for other, otherInfo := range g.Models {
+		if other != name && otherInfo.PluralName == info.PluralName {
+			add("", fmt.Sprintf("shares the plural name %q with model %s", info.PluralName, other))
+		}
+	}
+
+	for _, name := range info.OrderedFields {
+		if !seen[name] {
+			add(name, "is ordered but is not a field")
+		}
+	}
+	for _, name := range deprecatedFieldNames(info) {
+		if !seen[name] {
+			add(name, "is deprecated but is not a field")
+		}
+	}
+
+	defaults := make([]string, 0, len(info.DefaultValues))
+	for field := range info.DefaultValues {
+		defaults = append(defaults, field)
+	}
+	sort.Strings(defaults)
+	for _, name := range defaults {
+		field, ok := info.fieldByJSONName(name)
+		if !ok {
+			add(name, "has a default value but is not a field")
+		} else if _, err := defaultValue(field.Type, info.DefaultValues[name]); err != nil {
+			add(name, "has an invalid default value: "+err.Error())
+		}
+	}
+	return problems
+}
+
+// checkRelations reports, as warnings, the foreign keys of the registered models to
+// models that aren't registered. GenerateAPI runs it once every model is registered,
+// so models may be registered in any order.
+func (g *APIGenerator) checkRelations() []ConfigError {
+	names := make([]string, 0, len(g.Models))
+	for name := range g.Models {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var problems []ConfigError
+	for _, name := range names {
+		for _, fk := range g.Models[name].ForeignKeys {
+			if _, registered := g.Models[fk.RelatedModel]; fk.RelatedModel != "" && !registered {
+				problems = append(problems, ConfigError{
+					Model:   name,
+					Field:   fk.FieldName,
+					Message: fmt.Sprintf("references model %s, which is not registered", fk.RelatedModel),
+					Warning: true,
+				})
+			}
+		}
+	}
+	return problems
+}
+
+// configError logs the warnings among problems, and joins the errors
+func configError(problems []ConfigError) error {
+	var errs []error
+	for _, problem := range problems {
+		if problem.Warning {
+			log.Warn().Str("model", problem.Model).Str("field", problem.Field).Msg("apigen: " + problem.Message)
+			continue
+		}
+		errs = append(errs, problem)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,33 @@
+package apigen
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCheckRelations(t *testing.T) {
+	g := New(newTestDB(t), gin.New())
+	info, err := g.analyzeModel(&testArticle{})
+	if err != nil {
+		t.Fatalf("analyze: %v", err)
+	}
+	if problems := g.ValidateModelInfo(info); len(problems) != 0 {
+		t.Errorf("validate before the related model is registered: got %v", problems)
+	}
+
+	// Articles reference tags, registered afterwards
+	if err := g.RegisterModelWithOptions(&testArticle{}); err != nil {
+		t.Fatalf("register articles: %v", err)
+	}
+	problems := g.checkRelations()
+	if len(problems) != 1 || !problems[0].Warning || problems[0].Field != "Tags" {
+		t.Errorf("relations without tags: got %v", problems)
+	}
+	if err := g.RegisterModelWithOptions(&testTag{}); err != nil {
+		t.Fatalf("register tags: %v", err)
+	}
+	if problems := g.checkRelations(); len(problems) != 0 {
+		t.Errorf("relations with tags: got %v", problems)
+	}
+}
@@ -0,0 +1,204 @@
+package apigen
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// apigenAnnotation marks a struct RegisterModelsFromDir registers, in its doc comment
+const apigenAnnotation = "@apigen"
+
+// APIModel marks a struct RegisterModelsFromDir registers, without a doc comment
+type APIModel interface {
+	APIModel()
+}
+
+// modelTypes holds the types RegisterModelType made known, by type name
+var modelTypes = struct {
+	sync.RWMutex
+	byName map[string][]reflect.Type
+}{byName: make(map[string][]reflect.Type)}
+
+// RegisterModelType makes model types known to RegisterModelsFromDir. Source files only
+// name a struct, a Go binary can't build a type from them, so generated model packages
+// call it from an init function, e.g. apigen.RegisterModelType(&User{}, &Post{}).
+func RegisterModelType(models ...any) {
+	modelTypes.Lock()
+	defer modelTypes.Unlock()
+	for _, model := range models {
+		t := reflect.TypeOf(model)
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		known := modelTypes.byName[t.Name()]
+		if !containsType(known, t) {
+			modelTypes.byName[t.Name()] = append(known, t)
+		}
+	}
+}
+
+// containsType tells whether types holds t
+func containsType(types []reflect.Type, t reflect.Type) bool {
+	for _, known := range types {
+		if known == t {
+			return true
+		}
+	}
+	return false
+}
+
+// lookupModelType returns the type RegisterModelType made known for a struct of a package,
+// going by the last element of its import path when several packages declare the name
+func lookupModelType(pkgName, name string) (reflect.Type, error) {
+	modelTypes.RLock()
+	candidates := modelTypes.byName[name]
+	modelTypes.RUnlock()
+
+	if len(candidates) > 1 {
+		var inPackage []reflect.Type
+		for _, t := range candidates {
+			if path.Base(t.PkgPath()) == pkgName {
+				inPackage = append(inPackage, t)
+			}
+		}
+		candidates = inPackage
+	}
+	switch len(candidates) {
+	case 0:
+		return nil, fmt.Errorf("type %s.%s is unknown, pass it to apigen.RegisterModelType", pkgName, name)
+	case 1:
+		return candidates[0], nil
+	default:
+		return nil, fmt.Errorf("type name %s.%s is ambiguous", pkgName, name)
+	}
+}
+
+// RegisterModelsFromDir registers the structs of the Go files in dir annotated with a
+// "// @apigen" comment or implementing APIModel. Their types must be made known with
+// RegisterModelType. The error lists every struct that failed to register.
+func (g *APIGenerator) RegisterModelsFromDir(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return err
+	}
+	var sources []string
+	for _, file := range files {
+		if !strings.HasSuffix(file, "_test.go") {
+			sources = append(sources, file)
+		}
+	}
+	pkgName, names, err := AnnotatedModels(sources...)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, name := range names {
+		t, err := lookupModelType(pkgName, name)
+		if err == nil {
+			err = g.RegisterModel(reflect.New(t).Interface(), "")
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// AnnotatedModels parses Go source files of a package, returning its name and the
+// sorted names of the structs annotated with "// @apigen" or implementing APIModel
+func AnnotatedModels(files ...string) (string, []string, error) {
+	fset := token.NewFileSet()
+	pkgName := ""
+	structs := make(map[string]bool)
+	annotated := make(map[string]bool)
+	markers := make(map[string]bool)
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			return "", nil, err
+		}
+		parsed, err := parser.ParseFile(fset, file, src, parser.ParseComments)
+		if err != nil {
+			return "", nil, err
+		}
+		if pkgName == "" {
+			pkgName = parsed.Name.Name
+		} else if parsed.Name.Name != pkgName {
+			return "", nil, fmt.Errorf("%s declares package %s, expected %s", file, parsed.Name.Name, pkgName)
+		}
+
+		for _, decl := range parsed.Decls {
+			switch decl := decl.(type) {
+			case *ast.GenDecl:
+				if decl.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range decl.Specs {
+					typeSpec := spec.(*ast.TypeSpec)
+					if _, ok := typeSpec.Type.(*ast.StructType); !ok || typeSpec.TypeParams != nil {
+						continue
+					}
+					structs[typeSpec.Name.Name] = true
+					// A lone type declaration keeps its comment on the GenDecl
+					if hasAnnotation(typeSpec.Doc) || (len(decl.Specs) == 1 && hasAnnotation(decl.Doc)) {
+						annotated[typeSpec.Name.Name] = true
+					}
+				}
+			case *ast.FuncDecl:
+				if name := receiverName(decl); name != "" && decl.Name.Name == "APIModel" &&
+					decl.Type.Params.NumFields() == 0 && decl.Type.Results.NumFields() == 0 {
+					markers[name] = true
+				}
+			}
+		}
+	}
+
+	var names []string
+	for name := range structs {
+		if annotated[name] || markers[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return pkgName, names, nil
+}
+
+// hasAnnotation tells whether a doc comment holds a line starting with @apigen
+func hasAnnotation(doc *ast.CommentGroup) bool {
+	if doc == nil {
+		return false
+	}
+	for _, line := range strings.Split(doc.Text(), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) > 0 && fields[0] == apigenAnnotation {
+			return true
+		}
+	}
+	return false
+}
+
+// receiverName returns the type name of a method's receiver, T for both T and *T
+func receiverName(decl *ast.FuncDecl) string {
+	if decl.Recv == nil || len(decl.Recv.List) != 1 {
+		return ""
+	}
+	expr := decl.Recv.List[0].Type
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
@@ -0,0 +1,74 @@
+package apigen
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// testWidget is registered by its @apigen annotation
+type testWidget struct {
+	ID   uint   `json:"id" gorm:"primaryKey"`
+	Name string `json:"name"`
+}
+
+// testGadget is registered as an APIModel
+type testGadget struct {
+	ID uint `json:"id" gorm:"primaryKey"`
+}
+
+func (testGadget) APIModel() {}
+
+const testModelsSource = `package apigen
+
+// testWidget is a widget
+// @apigen
+type testWidget struct {
+	ID   uint
+	Name string
+}
+
+type testGadget struct {
+	ID uint
+}
+
+func (*testGadget) APIModel() {}
+
+// testPlain isn't annotated
+type testPlain struct {
+	ID uint
+}
+`
+
+func TestRegisterModelsFromDir(t *testing.T) {
+	RegisterModelType(&testWidget{}, &testGadget{})
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "models.go"), []byte(testModelsSource), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	g := New(nil, nil)
+	if err := g.RegisterModelsFromDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0, len(g.Models))
+	for name := range g.Models {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	if !slices.Equal(names, []string{"testGadget", "testWidget"}) {
+		t.Errorf("registered %v", names)
+	}
+
+	// Structs whose type wasn't made known are reported together
+	unknown := "package apigen\n\n// @apigen\ntype testMissing struct{}\n\n// @apigen\ntype testLost struct{}\n"
+	if err := os.WriteFile(filepath.Join(dir, "missing.go"), []byte(unknown), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	err := New(nil, nil).RegisterModelsFromDir(dir)
+	if err == nil || !strings.Contains(err.Error(), "testMissing") || !strings.Contains(err.Error(), "testLost") {
+		t.Errorf("got error %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+package apigen
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NestedResource describes child records addressed through their parent, registered
+// with RegisterNestedResource
+type NestedResource struct {
+	Child      string // Name of the child model, e.g. "Post"
+	ForeignKey string // Field of the child holding the parent ID, e.g. "UserID"
+	Path       string // Full route path, including the mount path, e.g. "/api/users/:id/posts/:child_id"
+}
+
+// nestedRoutePath returns the path of the children of a parent, relative to the mount path
+func nestedRoutePath(parentInfo, childInfo ModelInfo) string {
+	return fmt.Sprintf("/api/%s/:id/%s/:child_id", parentInfo.PluralName, childInfo.PluralName)
+}
+
+// nestedParentKey is the request context key of the parent ID of a nested route
+type nestedParentKey struct{}
+
+// RegisterNestedResource serves the children of a parent record under the parent's
+// instance path, e.g. RegisterNestedResource("User", "Post") serves GET, PUT and DELETE
+// /api/users/:id/posts/:child_id. Only posts of the user can be reached: posts of other
+// users answer 404 like missing ones. The child must have an ID field pointing at the
+// parent, e.g. Post.UserID.
+func (g *APIGenerator) RegisterNestedResource(parentModel, childModel string) error {
+	parentInfo, exists := g.Models[parentModel]
+	if !exists {
+		return fmt.Errorf("model %s is not registered", parentModel)
+	}
+	childInfo, exists := g.Models[childModel]
+	if !exists {
+		return fmt.Errorf("model %s is not registered", childModel)
+	}
+	if parentInfo.hasCompositePrimaryKey() || childInfo.hasCompositePrimaryKey() {
+		return fmt.Errorf("nested resources don't support composite primary keys")
+	}
+
+	// Find the child field holding the parent ID
+	var foreignKey string
+	for _, fk := range childInfo.ForeignKeys {
+		if fk.RelatedModel == parentModel && fk.RelationshipID != "" {
+			foreignKey = fk.RelationshipID
+			break
+		}
+	}
+	if foreignKey == "" {
+		return fmt.Errorf("model %s has no foreign key to %s", childModel, parentModel)
+	}
+	for _, nested := range parentInfo.NestedResources {
+		if nested.Child == childModel {
+			return fmt.Errorf("nested resource %s is already registered on model %s", childModel, parentModel)
+		}
+	}
+
+	column := g.DB.NamingStrategy.ColumnName("", foreignKey)
+	for _, field := range childInfo.Fields {
+		if field.Name == foreignKey {
+			column = g.columnName(field)
+		}
+	}
+
+	// Scope every query of the child to the parent of the request
+	scoped := childInfo
+	scoped.Scopes = append(slices.Clip(childInfo.Scopes), func(db *gorm.DB) *gorm.DB {
+		parentID, ok := db.Statement.Context.Value(nestedParentKey{}).(string)
+		if !ok {
+			return db
+		}
+		return db.Where(clause.Eq{Column: clause.Column{Table: childInfo.TableName, Name: column}, Value: parentID})
+	})
+
+	routePath := nestedRoutePath(parentInfo, childInfo)
+	nested := NestedResource{
+		Child:      childModel,
+		ForeignKey: foreignKey,
+		Path:       g.mountPath + routePath,
+	}
+	if scoped.verbEnabled(http.MethodGet) {
+		g.handleRelated(scoped, parentModel, VerbGet, http.MethodGet, routePath, g.nestedHandler(parentInfo, g.getHandler(scoped)))
+	}
+	if scoped.verbEnabled(http.MethodPut) {
+		g.handleRelated(scoped, parentModel, VerbUpdate, http.MethodPut, routePath, g.nestedHandler(parentInfo, g.updateHandler(scoped)))
+	}
+	if scoped.verbEnabled(http.MethodDelete) {
+		g.handleRelated(scoped, parentModel, VerbDelete, http.MethodDelete, routePath, g.nestedHandler(parentInfo, g.deleteHandler(scoped)))
+	}
+
+	parentInfo.NestedResources = append(parentInfo.NestedResources, nested)
+	g.Models[parentModel] = parentInfo
+	return nil
+}
+
+// nestedHandler checks that the parent of a nested route exists, then runs the child
+// handler with :id pointing at the child and the parent ID in the request context
+func (g *APIGenerator) nestedHandler(parentInfo ModelInfo, handler gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Check if the parent record exists
+		if _, ok := g.loadParent(c, parentInfo); !ok {
+			return
+		}
+
+		// Address the child with the generated handlers
+		parentID := c.Param("id")
+		childID := c.Param("child_id")
+		for i := range c.Params {
+			if c.Params[i].Key == "id" {
+				c.Params[i].Value = childID
+			}
+		}
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), nestedParentKey{}, parentID))
+
+		handler(c)
+	}
+}
@@ -0,0 +1,54 @@
+package apigen
+
+import (
+	"net/http"
+	"testing"
+)
+
+// Blog and Post are exported because foreign keys name the related model, e.g. BlogID
+type Blog struct {
+	ID   uint   `json:"id" gorm:"primaryKey"`
+	Name string `json:"name"`
+}
+
+type Post struct {
+	ID     uint   `json:"id" gorm:"primaryKey"`
+	BlogID uint   `json:"blog_id"`
+	Title  string `json:"title"`
+}
+
+func TestNestedResource(t *testing.T) {
+	g, router := newTestAPI(t, func(g *APIGenerator) {
+		g.RegisterModelWithOptions(&Blog{})
+		g.RegisterModelWithOptions(&Post{})
+		if err := g.RegisterNestedResource("Blog", "Post"); err != nil {
+			t.Fatalf("register nested resource: %v", err)
+		}
+		if err := g.RegisterNestedResource("Post", "Blog"); err == nil {
+			t.Error("nesting a model without a foreign key to the parent succeeded")
+		}
+	}, &Blog{}, &Post{})
+	g.DB.Create(&[]Blog{{Name: "first"}, {Name: "second"}})
+	g.DB.Create(&[]Post{{BlogID: 1, Title: "hello"}, {BlogID: 2, Title: "other"}})
+
+	if post := decode[Post](t, serve(router, http.MethodGet, "/api/blogs/1/posts/1", "")); post.Title != "hello" {
+		t.Errorf("get: got %+v", post)
+	}
+	for _, method := range []string{http.MethodGet, http.MethodPut, http.MethodDelete} {
+		if w := serve(router, method, "/api/blogs/1/posts/2", `{"title":"stolen"}`); w.Code != http.StatusNotFound {
+			t.Errorf("%s of another blog's post: got %d, want 404", method, w.Code)
+		}
+	}
+	if w := serve(router, http.MethodGet, "/api/blogs/3/posts/1", ""); w.Code != http.StatusNotFound {
+		t.Errorf("get under a missing blog: got %d, want 404", w.Code)
+	}
+
+	if w := serve(router, http.MethodDelete, "/api/blogs/1/posts/1", ""); w.Code >= http.StatusBadRequest {
+		t.Fatalf("delete: got %d %s", w.Code, w.Body)
+	}
+	var count int64
+	g.DB.Model(&Post{}).Count(&count)
+	if count != 1 {
+		t.Errorf("got %d posts after delete, want 1", count)
+	}
+}
@@ -0,0 +1,142 @@
+package apigen
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ModelOption configures a model when it is registered
+type ModelOption func(*ModelInfo)
+
+// WithResourceName sets the singular resource name of a model (e.g. "article"),
+// which also determines its plural route name
+func WithResourceName(name string) ModelOption {
+	return func(info *ModelInfo) {
+		info.ResourceName = name
+	}
+}
+
+// WithHooks registers callbacks around the write operations of a model. Hooks passed
+// in several options are chained and run in the order they were given.
+func WithHooks(hooks ModelHooks) ModelOption {
+	return func(info *ModelInfo) {
+		info.Hooks = info.Hooks.chain(hooks)
+	}
+}
+
+// WithRateLimit limits every endpoint of a model to rpm requests per minute per client IP
+func WithRateLimit(rpm int) ModelOption {
+	return WithMethodRateLimit(anyMethod, rpm)
+}
+
+// WithMethodRateLimit limits the endpoints of a model served under an HTTP method to
+// rpm requests per minute per client IP. It overrides WithRateLimit for that method,
+// which allows e.g. writes to be more restricted than reads.
+func WithMethodRateLimit(method string, rpm int) ModelOption {
+	return func(info *ModelInfo) {
+		if info.RateLimits == nil {
+			info.RateLimits = make(map[string]int)
+		}
+		info.RateLimits[strings.ToUpper(method)] = rpm
+	}
+}
+
+// WithDisabledVerbs prevents the routes of the given HTTP methods from being registered
+func WithDisabledVerbs(verbs ...string) ModelOption {
+	return func(info *ModelInfo) {
+		for _, verb := range verbs {
+			info.DisabledVerbs = append(info.DisabledVerbs, strings.ToUpper(verb))
+		}
+	}
+}
+
+// WithOrderedFields lists the fields first in the Swagger schemas of a model, by JSON
+// name, e.g. the ID and name before the fields of an embedded gorm.Model
+func WithOrderedFields(fields ...string) ModelOption {
+	return func(info *ModelInfo) {
+		info.OrderedFields = append(info.OrderedFields, fields...)
+	}
+}
+
+// WithSearchableFields sets the JSON names of the fields matched by the search endpoint
+func WithSearchableFields(fields ...string) ModelOption {
+	return func(info *ModelInfo) {
+		info.SearchableFields = append(info.SearchableFields, fields...)
+	}
+}
+
+// ModelHooks are callbacks run around the write operations of a model. An error
+// returned by a Before hook rejects the request with 422 Unprocessable Entity before
+// the database is touched; an error returned by an After hook results in a 500.
+type ModelHooks struct {
+	BeforeCreate func(c *gin.Context, instance any) error
+	AfterCreate  func(c *gin.Context, instance any) error
+	BeforeUpdate func(c *gin.Context, instance any) error
+	AfterUpdate  func(c *gin.Context, instance any) error
+	BeforeDelete func(c *gin.Context, instance any) error
+	AfterDelete  func(c *gin.Context, instance any) error
+}
+
+// hookFunc is the signature shared by all model hooks
+type hookFunc = func(c *gin.Context, instance any) error
+
+// chain returns hooks running h first and then next
+func (h ModelHooks) chain(next ModelHooks) ModelHooks {
+	return ModelHooks{
+		BeforeCreate: chainHook(h.BeforeCreate, next.BeforeCreate),
+		AfterCreate:  chainHook(h.AfterCreate, next.AfterCreate),
+		BeforeUpdate: chainHook(h.BeforeUpdate, next.BeforeUpdate),
+		AfterUpdate:  chainHook(h.AfterUpdate, next.AfterUpdate),
+		BeforeDelete: chainHook(h.BeforeDelete, next.BeforeDelete),
+		AfterDelete:  chainHook(h.AfterDelete, next.AfterDelete),
+	}
+}
+
+// chainHook runs first and then second, stopping at the first error
+func chainHook(first, second hookFunc) hookFunc {
+	if first == nil {
+		return second
+	}
+	if second == nil {
+		return first
+	}
+	return func(c *gin.Context, instance any) error {
+		if err := first(c, instance); err != nil {
+			return err
+		}
+		return second(c, instance)
+	}
+}
+
+// runHook calls a hook if it is set
+func runHook(hook hookFunc, c *gin.Context, instance any) error {
+	if hook == nil {
+		return nil
+	}
+	return hook(c, instance)
+}
+
+// WithDefaultPreloads sets the associations always loaded by the list and get endpoints
+func WithDefaultPreloads(associations ...string) ModelOption {
+	return func(info *ModelInfo) {
+		info.DefaultPreloads = append(info.DefaultPreloads, associations...)
+	}
+}
+
+// WithPreloadAssociations loads every association of the records returned by the
+// list and get endpoints
+func WithPreloadAssociations() ModelOption {
+	return func(info *ModelInfo) {
+		info.PreloadAssociations = true
+	}
+}
+
+// WithScopes applies GORM scopes to every query the generated endpoints run for a model,
+// e.g. a tenant filter or a condition hiding archived records
+func WithScopes(scopes ...func(*gorm.DB) *gorm.DB) ModelOption {
+	return func(info *ModelInfo) {
+		info.Scopes = append(info.Scopes, scopes...)
+	}
+}
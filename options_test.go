@@ -0,0 +1,65 @@
+package apigen
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// testProject is hidden from the API once archived
+type testProject struct {
+	ID     uint   `json:"id" gorm:"primaryKey"`
+	Name   string `json:"name"`
+	Active bool   `json:"active"`
+}
+
+func TestRegisterModelWithOptions(t *testing.T) {
+	g, router := newTestAPI(t, func(g *APIGenerator) {
+		err := g.RegisterModelWithOptions(&testUser{},
+			WithResourceName("member"),
+			WithHooks(ModelHooks{BeforeCreate: func(c *gin.Context, instance any) error {
+				user := instance.(*testUser)
+				user.Email = strings.ToLower(user.Email)
+				return nil
+			}}),
+		)
+		if err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}, &testUser{})
+
+	w := serve(router, http.MethodPost, "/api/members", `{"name":"Alice","email":"Alice@Example.com"}`)
+	if user := decode[testUser](t, w); w.Code != http.StatusCreated || user.Email != "alice@example.com" {
+		t.Errorf("create: got %d %+v", w.Code, user)
+	}
+	if err := g.RegisterModelWithOptions(testUser{}.Name); err == nil {
+		t.Error("register a string: got no error")
+	}
+}
+
+func TestWithScopes(t *testing.T) {
+	g, router := newTestAPI(t, func(g *APIGenerator) {
+		g.RegisterModelWithOptions(&testProject{}, WithScopes(func(db *gorm.DB) *gorm.DB {
+			return db.Where("active = ?", true)
+		}))
+	}, &testProject{})
+	g.DB.Create(&[]testProject{{Name: "apigen", Active: true}, {Name: "archived"}})
+
+	if projects := decode[[]testProject](t, serve(router, http.MethodGet, "/api/test_projects", "")); len(projects) != 1 || projects[0].Name != "apigen" {
+		t.Errorf("list: got %+v", projects)
+	}
+	if count := decode[map[string]int](t, serve(router, http.MethodGet, "/api/test_projects/count", "")); count["count"] != 1 {
+		t.Errorf("count: got %v", count)
+	}
+	if projects := decode[[]testProject](t, serve(router, http.MethodGet, "/api/test_projects/search?q=archived", "")); len(projects) != 0 {
+		t.Errorf("search: got %+v", projects)
+	}
+	for _, method := range []string{http.MethodGet, http.MethodPatch, http.MethodDelete} {
+		if w := serve(router, method, "/api/test_projects/2", `{"name":"revived"}`); w.Code != http.StatusNotFound {
+			t.Errorf("%s of an out-of-scope record: got %d, want 404", method, w.Code)
+		}
+	}
+}
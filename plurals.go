@@ -0,0 +1,61 @@
+package apigen
+
+import "strings"
+
+// DefaultIrregularPlurals lists the English words whose plural doesn't follow the
+// suffix rules, including words ending in f or fe that simply take an s. New copies it
+// into the IrregularPlurals of every generator.
+var DefaultIrregularPlurals = map[string]string{
+	"person":    "people",
+	"man":       "men",
+	"woman":     "women",
+	"child":     "children",
+	"foot":      "feet",
+	"tooth":     "teeth",
+	"goose":     "geese",
+	"mouse":     "mice",
+	"louse":     "lice",
+	"ox":        "oxen",
+	"datum":     "data",
+	"medium":    "media",
+	"criterion": "criteria",
+	"analysis":  "analyses",
+	"quiz":      "quizzes",
+	"sheep":     "sheep",
+	"fish":      "fish",
+	"deer":      "deer",
+	"series":    "series",
+	"species":   "species",
+	"news":      "news",
+	"roof":      "roofs",
+	"proof":     "proofs",
+	"chief":     "chiefs",
+	"chef":      "chefs",
+	"belief":    "beliefs",
+	"brief":     "briefs",
+	"reef":      "reefs",
+	"golf":      "golfs",
+	"gulf":      "gulfs",
+	"safe":      "safes",
+	"cafe":      "cafes",
+	"giraffe":   "giraffes",
+}
+
+// AddIrregularPlural registers the plural of a word for the models registered
+// afterwards, overriding the default suffix rules
+func (g *APIGenerator) AddIrregularPlural(singular, plural string) {
+	if g.IrregularPlurals == nil {
+		g.IrregularPlurals = make(map[string]string)
+	}
+	g.IrregularPlurals[strings.ToLower(singular)] = strings.ToLower(plural)
+}
+
+// copyPlurals returns a copy of a plural dictionary, so changes to one generator's
+// dictionary don't leak into the defaults
+func copyPlurals(plurals map[string]string) map[string]string {
+	copied := make(map[string]string, len(plurals))
+	for singular, plural := range plurals {
+		copied[singular] = plural
+	}
+	return copied
+}
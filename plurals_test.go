@@ -0,0 +1,81 @@
+package apigen
+
+import "testing"
+
+func TestPluralize(t *testing.T) {
+	for _, tc := range []struct{ singular, plural string }{
+		{"user", "users"},
+		{"category", "categories"}, // Consonant + y -> ies
+		{"toy", "toys"},            // Vowel + y -> s
+		{"day", "days"},
+		{"key", "keys"},
+		{"address", "addresses"},
+		{"box", "boxes"},
+		{"church", "churches"},
+		{"dish", "dishes"},
+		{"index", "indexes"},
+		{"knife", "knives"}, // fe -> ves
+		{"leaf", "leaves"},  // f -> ves
+		{"wolf", "wolves"},
+		{"staff", "staffs"}, // ff -> s
+		{"motive", "motives"},
+		{"blog_post", "blog_posts"},
+		{"person", "persons"}, // Suffix rules alone
+	} {
+		if got := pluralize(tc.singular, nil); got != tc.plural {
+			t.Errorf("pluralize(%q): got %q, want %q", tc.singular, got, tc.plural)
+		}
+	}
+}
+
+func TestIrregularPlurals(t *testing.T) {
+	g := New(nil, nil)
+	g.AddIrregularPlural("Cactus", "Cacti")
+
+	for _, tc := range []struct{ singular, plural string }{
+		{"person", "people"},      // Irregular
+		{"child", "children"},     // Irregular, en
+		{"mouse", "mice"},         // Vowel change
+		{"sheep", "sheep"},        // Unchanged
+		{"criterion", "criteria"}, // Latin and Greek
+		{"knife", "knives"},       // fe -> ves
+		{"leaf", "leaves"},        // f -> ves
+		{"golf", "golfs"},         // f -> s
+		{"cafe", "cafes"},         // fe -> s
+		{"pdf", "pdfs"},           // Not a word
+		{"index", "indexes"},      // Routes kept as they were
+		{"cactus", "cacti"},       // Added
+		{"team_member", "team_members"},
+		{"sales_person", "sales_people"},
+	} {
+		if got := pluralize(tc.singular, g.IrregularPlurals); got != tc.plural {
+			t.Errorf("pluralize(%q): got %q, want %q", tc.singular, got, tc.plural)
+		}
+	}
+
+	if _, changed := DefaultIrregularPlurals["cactus"]; changed {
+		t.Error("AddIrregularPlural changed DefaultIrregularPlurals")
+	}
+}
+
+func TestIrregularPluralRoutes(t *testing.T) {
+	type Person struct {
+		ID uint `json:"id"`
+	}
+	g := New(nil, nil)
+	if err := g.RegisterModelWithOptions(&Person{}); err != nil {
+		t.Fatal(err)
+	}
+	if plural := g.Models["Person"].PluralName; plural != "people" {
+		t.Errorf("default plural: got %q, want people", plural)
+	}
+
+	g = New(nil, nil)
+	g.AddIrregularPlural("person", "persons")
+	if err := g.RegisterModelWithOptions(&Person{}); err != nil {
+		t.Fatal(err)
+	}
+	if plural := g.Models["Person"].PluralName; plural != "persons" {
+		t.Errorf("overridden plural: got %q, want persons", plural)
+	}
+}
@@ -0,0 +1,266 @@
+package apigen
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// errForbidden is answered with 403 when the policy of a model denies a request
+var errForbidden = errors.New("forbidden")
+
+// ResourcePolicy decides which requests may reach the records of a model. The update
+// and delete checks get the ID of the addressed record. Upserts are checked once their
+// body is read, with CanUpdate for the record holding their key or CanCreate when there
+// is none. Bulk requests are checked for each of their IDs.
+type ResourcePolicy interface {
+	CanRead(c *gin.Context) bool
+	CanCreate(c *gin.Context) bool
+	CanUpdate(c *gin.Context, id string) bool
+	CanDelete(c *gin.Context, id string) bool
+}
+
+// WithPolicy guards the endpoints of a model with a policy, answering 403 to the
+// requests it denies before any query runs. It runs after the auth middleware, so it
+// can rely on what they stored in the context.
+func WithPolicy(rp ResourcePolicy) ModelOption {
+	return func(info *ModelInfo) {
+		info.Policy = rp
+	}
+}
+
+// FullAccessPolicy allows every request
+type FullAccessPolicy struct{}
+
+// CanRead allows the request
+func (FullAccessPolicy) CanRead(c *gin.Context) bool { return true }
+
+// CanCreate allows the request
+func (FullAccessPolicy) CanCreate(c *gin.Context) bool { return true }
+
+// CanUpdate allows the request
+func (FullAccessPolicy) CanUpdate(c *gin.Context, id string) bool { return true }
+
+// CanDelete allows the request
+func (FullAccessPolicy) CanDelete(c *gin.Context, id string) bool { return true }
+
+// NoAccessPolicy denies every request
+type NoAccessPolicy struct{}
+
+// CanRead denies the request
+func (NoAccessPolicy) CanRead(c *gin.Context) bool { return false }
+
+// CanCreate denies the request
+func (NoAccessPolicy) CanCreate(c *gin.Context) bool { return false }
+
+// CanUpdate denies the request
+func (NoAccessPolicy) CanUpdate(c *gin.Context, id string) bool { return false }
+
+// CanDelete denies the request
+func (NoAccessPolicy) CanDelete(c *gin.Context, id string) bool { return false }
+
+// PublicReadPolicy lets everyone read, and only authenticated requests write, i.e.
+// those an auth middleware stored an actor ID for under ActorIDKey
+type PublicReadPolicy struct{}
+
+// CanRead allows the request
+func (PublicReadPolicy) CanRead(c *gin.Context) bool { return true }
+
+// CanCreate allows authenticated requests
+func (PublicReadPolicy) CanCreate(c *gin.Context) bool { return authenticated(c) }
+
+// CanUpdate allows authenticated requests
+func (PublicReadPolicy) CanUpdate(c *gin.Context, id string) bool { return authenticated(c) }
+
+// CanDelete allows authenticated requests
+func (PublicReadPolicy) CanDelete(c *gin.Context, id string) bool { return authenticated(c) }
+
+// authenticated tells whether an auth middleware identified the client of a request
+func authenticated(c *gin.Context) bool {
+	return c.GetString(ActorIDKey) != ""
+}
+
+// policyMiddleware returns the middleware asking the policies of the models a route
+// reaches whether a request may proceed, or nil when none of them has a policy. Besides
+// the model's own policy, relationship and nested routes ask the one of the related or
+// parent model, and reads ask the ones of the associations they preload.
+func (g *APIGenerator) policyMiddleware(modelInfo ModelInfo, route RouteInfo) gin.HandlerFunc {
+	var checks []func(c *gin.Context) bool
+	for _, check := range []func(c *gin.Context) bool{
+		modelPolicyCheck(modelInfo, route.Verb, route.Method),
+		g.relatedPolicyCheck(route),
+		g.preloadPolicyCheck(modelInfo, route.Verb),
+	} {
+		if check != nil {
+			checks = append(checks, check)
+		}
+	}
+	if len(checks) == 0 {
+		return nil
+	}
+
+	return func(c *gin.Context) {
+		for _, allowed := range checks {
+			if !allowed(c) {
+				g.respondError(c, http.StatusForbidden, errForbidden)
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// modelPolicyCheck returns the check of the policy of a model for an endpoint, or nil
+// without a policy and for the endpoints whose handlers check it: the bulk endpoints
+// check each ID, and upserts the record holding their key. Custom actions are checked
+// by method.
+func modelPolicyCheck(modelInfo ModelInfo, verb, method string) func(c *gin.Context) bool {
+	policy := modelInfo.Policy
+	if policy == nil {
+		return nil
+	}
+
+	switch verb {
+	case VerbBulkPatch, VerbBulkDelete, VerbUpsert:
+		return nil
+	case VerbList, VerbGet, VerbSearch, VerbQuery, VerbCount, VerbRelated, VerbStream:
+		return policy.CanRead
+	case VerbCreate, VerbImport:
+		return policy.CanCreate
+	case VerbUpdate, VerbRestore, VerbCreateRelated, VerbReplaceRelated, VerbLink, VerbUnlink:
+		return func(c *gin.Context) bool {
+			return policy.CanUpdate(c, policyRecordID(c, modelInfo))
+		}
+	case VerbDelete, VerbDestroy:
+		return func(c *gin.Context) bool {
+			return policy.CanDelete(c, policyRecordID(c, modelInfo))
+		}
+	}
+
+	switch method {
+	case http.MethodGet, http.MethodHead:
+		return policy.CanRead
+	case http.MethodPost:
+		return policy.CanCreate
+	case http.MethodDelete:
+		return func(c *gin.Context) bool {
+			return policy.CanDelete(c, policyRecordID(c, modelInfo))
+		}
+	default:
+		return func(c *gin.Context) bool {
+			return policy.CanUpdate(c, policyRecordID(c, modelInfo))
+		}
+	}
+}
+
+// relatedPolicyCheck returns the check of the policy of the related or parent model of
+// a route, or nil when it has none. Creating a related record needs CanCreate; the
+// other routes read the related records, or the parent of nested ones, and need
+// CanRead. Replacing a has-one record is checked by its handler, which knows whether
+// the record is created or updated.
+func (g *APIGenerator) relatedPolicyCheck(route RouteInfo) func(c *gin.Context) bool {
+	policy := g.Models[route.RelatedModel].Policy
+	if route.RelatedModel == "" || policy == nil {
+		return nil
+	}
+
+	switch route.Verb {
+	case VerbReplaceRelated:
+		return nil
+	case VerbCreateRelated:
+		return policy.CanCreate
+	default:
+		return policy.CanRead
+	}
+}
+
+// preloadPolicyCheck returns the check of the policies of the associations a read
+// preloads, or nil when the model has no association guarded by a policy. Requests
+// preloading an association whose policy denies reads are refused.
+func (g *APIGenerator) preloadPolicyCheck(modelInfo ModelInfo, verb string) func(c *gin.Context) bool {
+	switch verb {
+	case VerbList, VerbGet, VerbQuery:
+	default:
+		return nil
+	}
+
+	guarded := map[string]ResourcePolicy{}
+	for association, relatedModelInfo := range g.associationModels(modelInfo) {
+		if relatedModelInfo.Policy != nil {
+			guarded[association] = relatedModelInfo.Policy
+		}
+	}
+	if len(guarded) == 0 {
+		return nil
+	}
+
+	return func(c *gin.Context) bool {
+		// Unknown associations are refused by the handler
+		associations, err := requestedPreloads(c, modelInfo)
+		if err != nil {
+			return true
+		}
+		if modelInfo.PreloadAssociations {
+			associations = associations[:0]
+			for association := range guarded {
+				associations = append(associations, association)
+			}
+		}
+		for _, association := range associations {
+			if policy, ok := guarded[association]; ok && !policy.CanRead(c) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// upsertAllowed asks the policy of a model whether an upsert may write an instance:
+// CanUpdate with the ID of the record holding its upsert key, or CanCreate when no
+// record holds it yet
+func (g *APIGenerator) upsertAllowed(c *gin.Context, modelInfo ModelInfo, instance any) (bool, error) {
+	if modelInfo.Policy == nil {
+		return true, nil
+	}
+
+	existing := reflect.New(modelInfo.Type).Interface()
+	err := g.modelDB(c, modelInfo).Scopes(g.upsertKeyScope(modelInfo, instance)).First(existing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return modelInfo.Policy.CanCreate(c), nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return modelInfo.Policy.CanUpdate(c, recordID(existing, modelInfo)), nil
+}
+
+// policyRecordID returns the ID of the record a request addresses, the components of
+// a composite key joined by "/", or "" if the path addresses none
+func policyRecordID(c *gin.Context, modelInfo ModelInfo) string {
+	if !modelInfo.hasCompositePrimaryKey() {
+		return c.Param("id")
+	}
+	parts := make([]string, 0, len(modelInfo.PrimaryKeyFields))
+	for _, field := range modelInfo.PrimaryKeyFields {
+		if value := c.Param(field.JSONName); value != "" {
+			parts = append(parts, value)
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// bulkAllowed asks a check of the policy of a model, CanUpdate or CanDelete, whether
+// every record addressed by a bulk request may be written
+func bulkAllowed(c *gin.Context, ids []any, allowed func(c *gin.Context, id string) bool) bool {
+	for _, id := range ids {
+		if !allowed(c, fmt.Sprint(id)) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,101 @@
+package apigen
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// testOwnerPolicy lets everyone read, and only writes records other than 2
+type testOwnerPolicy struct {
+	PublicReadPolicy
+}
+
+func (testOwnerPolicy) CanCreate(c *gin.Context) bool            { return false }
+func (testOwnerPolicy) CanUpdate(c *gin.Context, id string) bool { return id != "2" }
+func (testOwnerPolicy) CanDelete(c *gin.Context, id string) bool { return id != "2" }
+
+func TestPolicy(t *testing.T) {
+	g, router := newTestAPI(t, func(g *APIGenerator) {
+		g.RegisterModelWithOptions(&testUser{}, WithPolicy(testOwnerPolicy{}), WithBulkDelete(), WithBulkPatch())
+	}, &testUser{})
+	g.DB.Create(&[]testUser{{Name: "Alice"}, {Name: "Bob"}, {Name: "Carol"}})
+
+	if w := serve(router, http.MethodGet, "/api/test_users", ""); w.Code != http.StatusOK {
+		t.Errorf("list: got %d", w.Code)
+	}
+	if w := serve(router, http.MethodPost, "/api/test_users", `{"name":"Dave"}`); w.Code != http.StatusForbidden {
+		t.Errorf("create: got %d", w.Code)
+	}
+	if w := serve(router, http.MethodDelete, "/api/test_users/2", ""); w.Code != http.StatusForbidden {
+		t.Errorf("delete a denied record: got %d", w.Code)
+	}
+
+	// Bulk requests are denied if any of their records is
+	if w := serve(router, http.MethodDelete, "/api/test_users/bulk", `{"ids":[1,2]}`); w.Code != http.StatusForbidden {
+		t.Errorf("bulk delete with a denied record: got %d %s", w.Code, w.Body)
+	}
+	if w := serve(router, http.MethodPatch, "/api/test_users/bulk", `{"ids":[2,3],"patch":{"name":"Eve"}}`); w.Code != http.StatusForbidden {
+		t.Errorf("bulk patch with a denied record: got %d %s", w.Code, w.Body)
+	}
+	var count int64
+	g.DB.Model(&testUser{}).Where("name = ?", "Eve").Or("id IN ?", []int{1, 2}).Count(&count)
+	if count != 2 {
+		t.Errorf("denied bulk requests wrote records: matched %d, want 2", count)
+	}
+	if w := serve(router, http.MethodDelete, "/api/test_users/bulk", `{"ids":[1,3]}`); w.Code != http.StatusOK {
+		t.Errorf("bulk delete of allowed records: got %d %s", w.Code, w.Body)
+	}
+}
+
+func TestPolicyUpsert(t *testing.T) {
+	g, router := newTestAPI(t, func(g *APIGenerator) {
+		g.RegisterModelWithOptions(&testUser{}, WithPolicy(testOwnerPolicy{}))
+	}, &testUser{})
+	g.DB.Create(&[]testUser{{Name: "Alice"}, {Name: "Bob"}})
+
+	// The policy is asked about the record holding the upsert key
+	if w := serve(router, http.MethodPut, "/api/test_users", `{"id":1,"name":"Ann"}`); w.Code != http.StatusOK {
+		t.Errorf("upsert of an allowed record: got %d %s", w.Code, w.Body)
+	}
+	if w := serve(router, http.MethodPut, "/api/test_users", `{"id":2,"name":"Ben"}`); w.Code != http.StatusForbidden {
+		t.Errorf("upsert of a denied record: got %d %s", w.Code, w.Body)
+	}
+	if w := serve(router, http.MethodPut, "/api/test_users", `{"id":3,"name":"Carol"}`); w.Code != http.StatusForbidden {
+		t.Errorf("upsert inserting a record: got %d %s", w.Code, w.Body)
+	}
+	var bob testUser
+	g.DB.First(&bob, 2)
+	if bob.Name != "Bob" {
+		t.Errorf("denied upsert wrote the record: %+v", bob)
+	}
+}
+
+func TestPolicyRelatedModel(t *testing.T) {
+	g, router := newTestAPI(t, func(g *APIGenerator) {
+		g.RegisterModelWithOptions(&testCat{})
+		g.RegisterModelWithOptions(&testToy{}, WithPolicy(NoAccessPolicy{}))
+	}, &testCat{}, &testToy{})
+	g.DB.Create(&testCat{Name: "Tom", Toys: []testToy{{Name: "mouse"}}})
+
+	// The toys can't be reached through their owner either
+	for _, tc := range []struct{ method, path, body string }{
+		{http.MethodGet, "/api/test_cats/1/toys", ""},
+		{http.MethodGet, "/api/test_cats/1?preload=Toys", ""},
+		{http.MethodGet, "/api/test_cats?preload=toys", ""},
+		{http.MethodPost, "/api/test_cats/1/toys", `{"name":"yarn"}`},
+	} {
+		if w := serve(router, tc.method, tc.path, tc.body); w.Code != http.StatusForbidden {
+			t.Errorf("%s %s: got %d %s, want 403", tc.method, tc.path, w.Code, w.Body)
+		}
+	}
+	if w := serve(router, http.MethodGet, "/api/test_cats/1", ""); w.Code != http.StatusOK {
+		t.Errorf("owner without its toys: got %d %s", w.Code, w.Body)
+	}
+	var count int64
+	g.DB.Model(&testToy{}).Count(&count)
+	if count != 1 {
+		t.Errorf("denied request created a toy: %d toys", count)
+	}
+}
@@ -0,0 +1,196 @@
+package apigen
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// primaryKeyFields returns the fields tagged gorm:"primaryKey" in declaration order,
+// falling back to the ID field GORM uses by convention
+func primaryKeyFields(modelType reflect.Type, fields []FieldInfo) []FieldInfo {
+	var keys []FieldInfo
+	var id *FieldInfo
+	for i, field := range fields {
+		structField, ok := modelType.FieldByName(field.Name)
+		if ok && isPrimaryKeyField(structField) {
+			keys = append(keys, field)
+		}
+		if field.Name == "ID" {
+			id = &fields[i]
+		}
+	}
+
+	if len(keys) == 0 && id != nil {
+		keys = append(keys, *id)
+	}
+	return keys
+}
+
+// singlePrimaryKey returns the field holding a single-column primary key, which may be
+// the ID promoted from an embedded gorm.Model, or an empty FieldInfo for composite keys
+func singlePrimaryKey(modelType reflect.Type, keys []FieldInfo) FieldInfo {
+	switch {
+	case len(keys) == 1:
+		return keys[0]
+	case len(keys) > 1:
+		return FieldInfo{}
+	}
+
+	field, ok := modelType.FieldByName("ID")
+	if !ok {
+		return FieldInfo{}
+	}
+	return FieldInfo{
+		Name:     field.Name,
+		JSONName: "ID",
+		Type:     field.Type,
+		IsID:     true,
+		IsUUID:   isUUIDField(field),
+		Column:   gormColumn(field),
+	}
+}
+
+// isPrimaryKeyField reports whether a struct field is tagged as part of the primary key
+func isPrimaryKeyField(field reflect.StructField) bool {
+	for _, setting := range strings.Split(field.Tag.Get("gorm"), ";") {
+		name, _, _ := strings.Cut(setting, ":")
+		name = strings.TrimSpace(name)
+		if strings.EqualFold(name, "primaryKey") || strings.EqualFold(name, "primary_key") {
+			return true
+		}
+	}
+	return false
+}
+
+// hasCompositePrimaryKey reports whether the model's primary key spans several fields
+func (m ModelInfo) hasCompositePrimaryKey() bool {
+	return len(m.PrimaryKeyFields) > 1
+}
+
+// instancePath returns the route suffix addressing a single record, e.g. "/:id",
+// or one positional parameter per primary key field for composite keys
+func (m ModelInfo) instancePath() string {
+	if !m.hasCompositePrimaryKey() {
+		return "/:id"
+	}
+
+	var path strings.Builder
+	for _, field := range m.PrimaryKeyFields {
+		path.WriteString("/:" + field.JSONName)
+	}
+	return path.String()
+}
+
+// compositeKeyScope builds the WHERE clause matching every component of a composite
+// primary key from the path parameters
+func (g *APIGenerator) compositeKeyScope(c *gin.Context, modelInfo ModelInfo) (func(*gorm.DB) *gorm.DB, error) {
+	conditions := make([]clause.Expression, 0, len(modelInfo.PrimaryKeyFields))
+	for _, field := range modelInfo.PrimaryKeyFields {
+		raw := c.Param(field.JSONName)
+		if raw == "" {
+			return nil, fmt.Errorf("%s is required", field.JSONName)
+		}
+
+		value, err := parseFilterValue(field.Type, raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", field.JSONName, err)
+		}
+		conditions = append(conditions, clause.Eq{Column: clause.Column{Name: g.columnName(field)}, Value: value})
+	}
+
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(clause.And(conditions...))
+	}, nil
+}
+
+// primaryKey returns the primary key values of a model instance
+func primaryKey(instance any, modelInfo ModelInfo) []any {
+	value := reflect.Indirect(reflect.ValueOf(instance))
+	key := make([]any, 0, len(modelInfo.PrimaryKeyFields))
+	for _, field := range modelInfo.PrimaryKeyFields {
+		key = append(key, value.FieldByName(field.Name).Interface())
+	}
+	return key
+}
+
+// primaryKeyValue returns the value of the single-column primary key of a model
+// instance, or an error when the model has no such field
+func primaryKeyValue(instance any, modelInfo ModelInfo) (any, error) {
+	name := modelInfo.PrimaryKeyField.Name
+	if name == "" {
+		name = "ID"
+	}
+	value := reflect.Indirect(reflect.ValueOf(instance)).FieldByName(name)
+	if !value.IsValid() {
+		return nil, fmt.Errorf("Model %s has no single-column primary key", modelInfo.Type.Name())
+	}
+	return value.Interface(), nil
+}
+
+// setLocation points the Location header of a 201 response at the created record,
+// e.g. /api/users/42, or /api/memberships/1/7 for composite keys
+func setLocation(c *gin.Context, instance any, modelInfo ModelInfo) {
+	if location := resourceLocation(c.Request.URL.Path, instance, modelInfo); location != "" {
+		c.Header("Location", location)
+	}
+}
+
+// resourceLocation returns the URL of a record below the collection at basePath, or
+// an empty string when the record has no ID
+func resourceLocation(basePath string, instance any, modelInfo ModelInfo) string {
+	segments := []string{strings.TrimSuffix(basePath, "/")}
+	for _, value := range primaryKey(instance, modelInfo) {
+		segments = append(segments, url.PathEscape(fmt.Sprint(value)))
+	}
+	if len(modelInfo.PrimaryKeyFields) == 0 {
+		// Models embedding gorm.Model carry a promoted ID field
+		id := recordID(instance, modelInfo)
+		if id == "" {
+			return ""
+		}
+		segments = append(segments, url.PathEscape(id))
+	}
+	return strings.Join(segments, "/")
+}
+
+// primaryKeyColumn returns the column of a single-column primary key, assuming GORM's
+// id column when the model declares no key field
+func (g *APIGenerator) primaryKeyColumn(modelInfo ModelInfo) string {
+	if modelInfo.PrimaryKeyField.Name == "" {
+		return "id"
+	}
+	return g.columnName(modelInfo.PrimaryKeyField)
+}
+
+// primaryKeyScope builds the WHERE clause matching the record whose single-column
+// primary key is id, converted to the type of the key field
+func (g *APIGenerator) primaryKeyScope(modelInfo ModelInfo, id string) (func(*gorm.DB) *gorm.DB, error) {
+	var value any = id
+	if key := modelInfo.PrimaryKeyField; key.Type != nil {
+		if key.IsUUID && !uuidPattern.MatchString(id) {
+			return nil, errors.New("Invalid ID format")
+		}
+		parsed, err := parseFilterValue(key.Type, id)
+		if err != nil {
+			return nil, errors.New("Invalid ID format")
+		}
+		value = parsed
+		// uuid.UUID keys are stored in canonical form, whatever the case of the URL
+		if key.Type == uuidType {
+			value = uuid.MustParse(id)
+		}
+	}
+
+	column := clause.Column{Table: clause.CurrentTable, Name: g.primaryKeyColumn(modelInfo)}
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(clause.Eq{Column: column, Value: value})
+	}, nil
+}
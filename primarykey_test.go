@@ -0,0 +1,91 @@
+package apigen
+
+import (
+	"net/http"
+	"testing"
+)
+
+// testMembership has a two-column primary key
+type testMembership struct {
+	UserID  uint   `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	GroupID uint   `json:"group_id" gorm:"primaryKey;autoIncrement:false"`
+	Role    string `json:"role"`
+}
+
+func TestCompositePrimaryKey(t *testing.T) {
+	g, router := newTestAPI(t, nil, &testMembership{})
+	g.DB.Create(&[]testMembership{{UserID: 1, GroupID: 7, Role: "member"}, {UserID: 1, GroupID: 8, Role: "member"}})
+
+	if fields := g.Models["testMembership"].PrimaryKeyFields; len(fields) != 2 {
+		t.Fatalf("got primary key fields %+v, want 2", fields)
+	}
+	swagger := NewSwaggerGenerator(g.Models)
+	swagger.BuildPathsForAllModels()
+	if _, ok := swagger.GenerateAllPaths()["/api/test_memberships/{user_id}/{group_id}"]; !ok {
+		t.Errorf("swagger misses the composite key path: %v", swagger.GenerateAllPaths())
+	}
+
+	w := serve(router, http.MethodGet, "/api/test_memberships/1/7", "")
+	if membership := decode[testMembership](t, w); w.Code != http.StatusOK || membership.GroupID != 7 {
+		t.Fatalf("get: got %d %s", w.Code, w.Body)
+	}
+	if w := serve(router, http.MethodGet, "/api/test_memberships/2/7", ""); w.Code != http.StatusNotFound {
+		t.Errorf("get a missing key: got %d, want 404", w.Code)
+	}
+
+	if w := serve(router, http.MethodPatch, "/api/test_memberships/1/7", `{"role":"admin"}`); w.Code != http.StatusOK {
+		t.Fatalf("patch: got %d %s", w.Code, w.Body)
+	}
+	var other testMembership
+	g.DB.First(&other, "user_id = ? AND group_id = ?", 1, 8)
+	if other.Role != "member" {
+		t.Errorf("patch changed another record: %+v", other)
+	}
+	if membership := decode[testMembership](t, serve(router, http.MethodGet, "/api/test_memberships/1/7", "")); membership.Role != "admin" {
+		t.Errorf("get after patch: %+v", membership)
+	}
+
+	if w := serve(router, http.MethodDelete, "/api/test_memberships/1/7", ""); w.Code >= http.StatusBadRequest {
+		t.Fatalf("delete: got %d %s", w.Code, w.Body)
+	}
+	var count int64
+	g.DB.Model(&testMembership{}).Count(&count)
+	if count != 1 {
+		t.Errorf("got %d memberships after delete, want 1", count)
+	}
+}
+
+// testCountry is keyed by its ISO code
+type testCountry struct {
+	Code string `json:"code" gorm:"primaryKey"`
+	Name string `json:"name"`
+}
+
+func TestCustomPrimaryKey(t *testing.T) {
+	g, router := newTestAPI(t, nil, &testCountry{})
+	if key := g.Models["testCountry"].PrimaryKeyField; key.Name != "Code" {
+		t.Fatalf("PrimaryKeyField: got %+v", key)
+	}
+
+	w := serve(router, http.MethodPost, "/api/test_countries", `{"code":"FR","name":"France"}`)
+	if w.Code != http.StatusCreated || w.Header().Get("Location") != "/api/test_countries/FR" {
+		t.Fatalf("create: got %d %s", w.Code, w.Body)
+	}
+	if country := decode[testCountry](t, serve(router, http.MethodGet, "/api/test_countries/FR", "")); country.Name != "France" {
+		t.Errorf("get: got %+v", country)
+	}
+	if w := serve(router, http.MethodPatch, "/api/test_countries/FR", `{"name":"République française"}`); w.Code != http.StatusOK {
+		t.Errorf("patch: got %d %s", w.Code, w.Body)
+	}
+	var country testCountry
+	g.DB.First(&country, "code = ?", "FR")
+	if country.Name != "République française" {
+		t.Errorf("after patch: %+v", country)
+	}
+	if w := serve(router, http.MethodDelete, "/api/test_countries/FR", ""); w.Code >= http.StatusBadRequest {
+		t.Errorf("delete: got %d %s", w.Code, w.Body)
+	}
+	if w := serve(router, http.MethodGet, "/api/test_countries/FR", ""); w.Code != http.StatusNotFound {
+		t.Errorf("get after delete: got %d, want 404", w.Code)
+	}
+}
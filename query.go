@@ -0,0 +1,348 @@
+package apigen
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// defaultPageSize is used when a page is requested without a limit
+	defaultPageSize = 20
+	// maxPageSize caps the number of records returned by a single page
+	maxPageSize = 100
+)
+
+// timeType is the type of timestamp fields
+var timeType = reflect.TypeOf(time.Time{})
+
+// pagination holds the page and limit query parameters of a list request
+type pagination struct {
+	Page   int
+	Limit  int
+	Active bool // Whether the client asked for pagination at all
+	Ranged bool // Whether the page was selected by a Range header, see parseRecordRange
+	Start  int  // Offset of the first record of a Range
+}
+
+// parsePagination reads the page and limit query parameters.
+// Pagination is only active when at least one of them is present.
+func parsePagination(c *gin.Context) (pagination, error) {
+	p := pagination{Page: 1, Limit: defaultPageSize}
+
+	if page, ok := c.GetQuery("page"); ok {
+		n, err := strconv.Atoi(page)
+		if err != nil || n < 1 {
+			return p, fmt.Errorf("page must be a positive integer")
+		}
+		p.Page = n
+		p.Active = true
+	}
+
+	if limit, ok := c.GetQuery("limit"); ok {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 {
+			return p, fmt.Errorf("limit must be a positive integer")
+		}
+		if n > maxPageSize {
+			n = maxPageSize
+		}
+		p.Limit = n
+		p.Active = true
+	}
+
+	return p, nil
+}
+
+// Offset returns the number of records to skip for the current page
+func (p pagination) Offset() int {
+	if p.Ranged {
+		return p.Start
+	}
+	return (p.Page - 1) * p.Limit
+}
+
+// Meta returns the pagination info reported with a page of results, or nil if inactive
+func (p pagination) Meta() map[string]any {
+	if !p.Active || p.Ranged {
+		return nil // A Range is reported in the Content-Range header
+	}
+	return map[string]any{"page": p.Page, "limit": p.Limit}
+}
+
+// Scope returns a GORM scope applying the pagination, or a no-op if inactive
+func (p pagination) Scope() func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if !p.Active {
+			return db
+		}
+		return db.Offset(p.Offset()).Limit(p.Limit)
+	}
+}
+
+// parseSort reads the sort query parameter, a comma separated list of JSON field
+// names where a leading "-" requests descending order (e.g. "?sort=-age,name")
+func (g *APIGenerator) parseSort(c *gin.Context, modelInfo ModelInfo) (func(*gorm.DB) *gorm.DB, error) {
+	var columns []clause.OrderByColumn
+
+	for _, name := range strings.Split(c.Query("sort"), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		desc := strings.HasPrefix(name, "-")
+		name = strings.TrimPrefix(name, "-")
+
+		field, ok := modelInfo.fieldByJSONName(name)
+		if !ok {
+			return nil, fmt.Errorf("cannot sort by unknown field %q", name)
+		}
+
+		columns = append(columns, clause.OrderByColumn{
+			Column: clause.Column{Name: g.columnName(field)},
+			Desc:   desc,
+		})
+	}
+
+	return func(db *gorm.DB) *gorm.DB {
+		for _, column := range columns {
+			db = db.Order(column)
+		}
+		return db
+	}, nil
+}
+
+// parsePreloads reads the preload query parameter, a comma separated list of associations
+// loaded along with the records (e.g. "?preload=User"), on top of the model's default preloads.
+// Only known associations are accepted so clients can't preload arbitrary relations. The
+// preloaded records go through the scopes of their own model, such as its tenant filter.
+func (g *APIGenerator) parsePreloads(c *gin.Context, modelInfo ModelInfo) (func(*gorm.DB) *gorm.DB, error) {
+	associations, err := requestedPreloads(c, modelInfo)
+	if err != nil {
+		return nil, err
+	}
+	related := g.associationModels(modelInfo)
+	if modelInfo.PreloadAssociations {
+		// Every association is loaded anyway
+		associations = make([]string, 0, len(related))
+		for association := range related {
+			associations = append(associations, association)
+		}
+		slices.Sort(associations)
+	}
+
+	return func(db *gorm.DB) *gorm.DB {
+		for _, association := range associations {
+			relatedModelInfo, ok := related[association]
+			if !ok {
+				db = db.Preload(association)
+				continue
+			}
+			scopes := g.modelScopes(relatedModelInfo)
+			if len(scopes) == 0 {
+				db = db.Preload(association)
+				continue
+			}
+			db = db.Preload(association, func(tx *gorm.DB) *gorm.DB { return tx.Scopes(scopes...) })
+		}
+		return db
+	}, nil
+}
+
+// requestedPreloads returns the model's default preloads and the associations named by
+// the preload query parameter of a request
+func requestedPreloads(c *gin.Context, modelInfo ModelInfo) ([]string, error) {
+	associations := slices.Clone(modelInfo.DefaultPreloads)
+
+	for _, name := range strings.Split(c.Query("preload"), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		association, ok := modelInfo.associationByName(name)
+		if !ok {
+			return nil, fmt.Errorf("cannot preload unknown association %q", name)
+		}
+		if !slices.Contains(associations, association) {
+			associations = append(associations, association)
+		}
+	}
+	return associations, nil
+}
+
+// associationModels maps the associations of a model, as GORM resolves them, to the
+// models of their records. Unregistered models get a ModelInfo holding just their type.
+func (g *APIGenerator) associationModels(modelInfo ModelInfo) map[string]ModelInfo {
+	if g.DB == nil {
+		return nil
+	}
+	stmt := &gorm.Statement{DB: g.DB}
+	if err := stmt.Parse(reflect.New(modelInfo.Type).Interface()); err != nil {
+		return nil
+	}
+	models := make(map[string]ModelInfo, len(stmt.Schema.Relationships.Relations))
+	for name, relation := range stmt.Schema.Relationships.Relations {
+		relatedType := relation.FieldSchema.ModelType
+		relatedModelInfo, ok := g.Models[relatedType.Name()]
+		if !ok || relatedModelInfo.Type != relatedType {
+			relatedModelInfo = ModelInfo{Type: relatedType}
+		}
+		models[name] = relatedModelInfo
+	}
+	return models
+}
+
+// filterOperators maps the suffix of a filter parameter to its SQL operator
+var filterOperators = map[string]string{
+	"eq":   "=",
+	"ne":   "<>",
+	"gt":   ">",
+	"gte":  ">=",
+	"lt":   "<",
+	"lte":  "<=",
+	"like": "LIKE",
+	"in":   "IN",
+}
+
+// parseFilters reads filter query parameters of the form field=value or field__op=value
+// (e.g. "?age__gte=18&name__like=al%"). Parameters that don't name a field are ignored
+// so they can't clash with pagination, sorting or other query parameters.
+func (g *APIGenerator) parseFilters(c *gin.Context, modelInfo ModelInfo) (func(*gorm.DB) *gorm.DB, error) {
+	query := c.Request.URL.Query()
+
+	// Sort the keys so the generated SQL is deterministic
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var conditions []clause.Expression
+	var filtered []string
+	for _, key := range keys {
+		name, op, _ := strings.Cut(key, "__")
+		field, ok := modelInfo.filterField(name)
+		if !ok {
+			continue
+		}
+		filtered = append(filtered, name)
+
+		if op == "" {
+			op = "eq"
+		}
+		operator, ok := filterOperators[op]
+		if !ok {
+			return nil, fmt.Errorf("unknown filter operator %q for field %q", op, name)
+		}
+
+		column := clause.Column{Name: g.columnName(field)}
+		for _, raw := range query[key] {
+			if op == "in" {
+				var values []any
+				for _, part := range strings.Split(raw, ",") {
+					value, err := parseFilterValue(field.Type, part)
+					if err != nil {
+						return nil, fmt.Errorf("invalid value for filter %q: %w", key, err)
+					}
+					values = append(values, value)
+				}
+				conditions = append(conditions, clause.Expr{SQL: "? IN ?", Vars: []any{column, values}})
+				continue
+			}
+
+			value, err := parseFilterValue(field.Type, raw)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for filter %q: %w", key, err)
+			}
+			conditions = append(conditions, clause.Expr{SQL: "? " + operator + " ?", Vars: []any{column, value}})
+		}
+	}
+
+	hint := g.indexHintScope(modelInfo, filtered)
+	return func(db *gorm.DB) *gorm.DB {
+		for _, condition := range conditions {
+			db = db.Where(condition)
+		}
+		if hint != nil {
+			db = db.Scopes(hint)
+		}
+		return db
+	}, nil
+}
+
+// timestampFields maps the filter names of the timestamps gorm maintains to their fields
+var timestampFields = map[string]string{
+	"created_at": "CreatedAt",
+	"updated_at": "UpdatedAt",
+}
+
+// filterField looks up a filtered field by its JSON name. The created_at and updated_at
+// timestamps of an embedded struct such as gorm.Model can be filtered too, although
+// they aren't API fields.
+func (m ModelInfo) filterField(name string) (FieldInfo, bool) {
+	if field, ok := m.fieldByJSONName(name); ok {
+		return field, true
+	}
+	for filterName, fieldName := range timestampFields {
+		if m.FieldNames == CamelCase {
+			filterName = toCamelCase(filterName)
+		}
+		if name != filterName {
+			continue
+		}
+		for _, field := range migrationFields(m) {
+			if field.Name == fieldName && field.Type == timeType {
+				field.JSONName = name
+				return field, true
+			}
+		}
+	}
+	return FieldInfo{}, false
+}
+
+// parseFilterValue converts a query string value to the Go type of the filtered field.
+// Timestamps are expected in ISO 8601 (RFC 3339) format, e.g. 2024-01-01T00:00:00Z,
+// and are compared in UTC, as databases storing them as text can't compare offsets.
+func parseFilterValue(t reflect.Type, raw string) (any, error) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType || t == deletedAtType {
+		value, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an ISO 8601 timestamp such as 2024-01-01T00:00:00Z", raw)
+		}
+		return value.UTC(), nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return strconv.ParseBool(raw)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.ParseInt(raw, 10, 64)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.ParseUint(raw, 10, 64)
+	case reflect.Float32, reflect.Float64:
+		return strconv.ParseFloat(raw, 64)
+	default:
+		return raw, nil
+	}
+}
+
+// columnName returns the database column backing a model field
+func (g *APIGenerator) columnName(field FieldInfo) string {
+	if field.Column != "" {
+		return field.Column
+	}
+	return g.DB.NamingStrategy.ColumnName("", field.Name)
+}
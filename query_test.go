@@ -0,0 +1,111 @@
+package apigen
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestPreload(t *testing.T) {
+	g, router := newTestAPI(t, nil, &testArticle{}, &testTag{})
+	g.DB.Create(&testArticle{Title: "Preloading", Tags: []testTag{{Name: "gorm"}}})
+
+	if article := decode[testArticle](t, serve(router, http.MethodGet, "/api/test_articles/1", "")); len(article.Tags) != 0 {
+		t.Errorf("get without preload: got tags %+v", article.Tags)
+	}
+	if article := decode[testArticle](t, serve(router, http.MethodGet, "/api/test_articles/1?preload=tags", "")); len(article.Tags) != 1 {
+		t.Errorf("get with preload: got tags %+v", article.Tags)
+	}
+	if articles := decode[[]testArticle](t, serve(router, http.MethodGet, "/api/test_articles?preload=Tags", "")); len(articles) != 1 || len(articles[0].Tags) != 1 {
+		t.Errorf("list with preload: got %+v", articles)
+	}
+	if w := serve(router, http.MethodGet, "/api/test_articles?preload=Author", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("unknown association: got %d, want 400", w.Code)
+	}
+}
+
+// testAuthor has many testBooks
+type testAuthor struct {
+	ID    uint       `json:"id" gorm:"primaryKey"`
+	Name  string     `json:"name"`
+	Books []testBook `json:"books,omitempty" gorm:"foreignKey:Author"`
+}
+
+type testBook struct {
+	ID     uint   `json:"id" gorm:"primaryKey"`
+	Author uint   `json:"author"`
+	Title  string `json:"title"`
+}
+
+func TestPreloadAssociations(t *testing.T) {
+	g, router := newTestAPI(t, func(g *APIGenerator) {
+		g.RegisterModelWithOptions(&testAuthor{}, WithPreloadAssociations())
+	}, &testAuthor{}, &testBook{})
+
+	authors := make([]testAuthor, 100)
+	for i := range authors {
+		authors[i].Books = make([]testBook, 10)
+	}
+	g.DB.CreateInBatches(authors, 50)
+
+	queries := 0
+	g.DB.Callback().Query().Before("gorm:query").Register("test:count_queries", func(*gorm.DB) { queries++ })
+	defer g.DB.Callback().Query().Remove("test:count_queries")
+
+	w := serve(router, http.MethodGet, "/api/test_authors", "")
+	listed := decode[[]testAuthor](t, w)
+	if len(listed) != 100 || len(listed[99].Books) != 10 {
+		t.Fatalf("list: got %d authors", len(listed))
+	}
+	if queries != 2 {
+		t.Errorf("list ran %d queries, want 2", queries)
+	}
+}
+
+func TestTimeRangeFilters(t *testing.T) {
+	g, router := newTestAPI(t, nil, &testMemo{}, &testTicket{})
+	day := func(d int) time.Time { return time.Date(2024, time.March, d, 12, 0, 0, 0, time.UTC) }
+	g.DB.Create(&[]testMemo{
+		{Model: gorm.Model{CreatedAt: day(1), UpdatedAt: day(5)}, Text: "first"},
+		{Model: gorm.Model{CreatedAt: day(2), UpdatedAt: day(2)}, Text: "second"},
+		{Model: gorm.Model{CreatedAt: day(3), UpdatedAt: day(3)}, Text: "third"},
+	})
+	g.DB.Create(&[]testTicket{{Title: "old", OpenedAt: day(1)}, {Title: "new", OpenedAt: day(3)}})
+
+	texts := func(query string) []string {
+		t.Helper()
+		w := serve(router, http.MethodGet, "/api/test_memos?sort=text&"+query, "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: got %d %s", query, w.Code, w.Body)
+		}
+		var texts []string
+		for _, memo := range decode[[]testMemo](t, w) {
+			texts = append(texts, memo.Text)
+		}
+		return texts
+	}
+	if got := texts("created_at__gte=2024-03-02T00:00:00Z&created_at__lte=2024-03-02T23:59:59Z"); !slices.Equal(got, []string{"second"}) {
+		t.Errorf("created on the 2nd: got %v", got)
+	}
+	if got := texts("created_at__lt=2024-03-02T12:00:00%2B02:00"); !slices.Equal(got, []string{"first"}) {
+		t.Errorf("created before 10:00 UTC on the 2nd: got %v", got)
+	}
+	if got := texts("updated_at__gt=2024-03-04T00:00:00Z"); !slices.Equal(got, []string{"first"}) {
+		t.Errorf("updated after the 4th: got %v", got)
+	}
+
+	tickets := decode[[]testTicket](t, serve(router, http.MethodGet, "/api/test_tickets?opened_at__gte=2024-03-02T00:00:00Z", ""))
+	if len(tickets) != 1 || tickets[0].Title != "new" {
+		t.Errorf("opened after the 2nd: got %+v", tickets)
+	}
+
+	w := serve(router, http.MethodGet, "/api/test_memos?created_at__gte=2024-03-02", "")
+	if body := decode[map[string]any](t, w); w.Code != http.StatusBadRequest || !strings.Contains(fmt.Sprint(body["error"]), "ISO 8601") {
+		t.Errorf("date without a time: got %d %v", w.Code, body)
+	}
+}
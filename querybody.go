@@ -0,0 +1,282 @@
+package apigen
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxFilterDepth caps the nesting of and/or groups in the filter of a query request
+const maxFilterDepth = 8
+
+// queryRequest is the body of POST /api/{plural}/query, e.g.
+// {"filter": {"and": [...]}, "sort": [{"field": "name", "order": "asc"}], "page": 1, "limit": 20}
+type queryRequest struct {
+	Filter *queryFilter `json:"filter"`
+	Sort   []querySort  `json:"sort"`
+	Page   int          `json:"page"`
+	Limit  int          `json:"limit"`
+}
+
+// queryFilter is a node of a filter tree: a group of conditions combined with and or
+// or, or a condition comparing a field to a value with one of the filter operators
+type queryFilter struct {
+	And   []queryFilter `json:"and"`
+	Or    []queryFilter `json:"or"`
+	Field string        `json:"field"`
+	Op    string        `json:"op"`
+	Value any           `json:"value"`
+}
+
+// querySort orders the results of a query request by a field
+type querySort struct {
+	Field string `json:"field"`
+	Order string `json:"order"` // "asc" or "desc", ascending by default
+}
+
+// queryHandler returns a handler function for listing the instances of a model matching
+// a filter tree sent in the request body
+// @Summary Query instances of a model
+// @Description List the instances of a model matching nested and/or filters sent as JSON
+// @Tags API
+// @Accept json
+// @Produce json,application/vnd.api+json
+// @Param query body object true "Filter, sort and page, e.g. {\"filter\": {\"or\": [{\"field\": \"age\", \"op\": \"gte\", \"value\": 18}]}}"
+// @Param preload query string false "Comma separated associations to load, e.g. User"
+// @Param fields query string false "Comma separated fields to include, e.g. id,name"
+// @Success 200 {array} any
+// @Header 200 {integer} X-Total-Count "Number of matching records, when TotalCountHeader is set"
+// @Failure 400 {object} map[string]string
+// @Router /api/{model}/query [post]
+func (g *APIGenerator) queryHandler(modelInfo ModelInfo) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		request, err := decodeQueryRequest(c)
+		if err != nil {
+			g.respondBodyError(c, err)
+			return
+		}
+
+		// Parse the filter tree, sorting and pagination of the body
+		filter, err := g.queryScope(modelInfo, request)
+		if err != nil {
+			g.respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		page, err := request.pagination()
+		if err != nil {
+			g.respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		preloads, err := g.parsePreloads(c, modelInfo)
+		if err != nil {
+			g.respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		fields, err := parseFields(c, modelInfo)
+		if err != nil {
+			g.respondError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		// Query the database
+		results := reflect.New(reflect.SliceOf(modelInfo.Type)).Interface()
+		deleted := deletedScope(c, modelInfo)
+		if err := g.modelDB(c, modelInfo).Scopes(deleted, filter, page.Scope(), preloads).Find(results).Error; err != nil {
+			g.respondError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		if g.TotalCountHeader {
+			var total int64
+			if err := g.modelDB(c, modelInfo).Model(reflect.New(modelInfo.Type).Interface()).Scopes(deleted, filter).Count(&total).Error; err != nil {
+				g.respondError(c, http.StatusInternalServerError, err)
+				return
+			}
+			c.Header(totalCountHeader, strconv.FormatInt(total, 10))
+		}
+
+		// Return the results, trimmed to the selected fields
+		g.respondWithFields(c, http.StatusOK, modelInfo, results, page.Meta(), fields)
+	}
+}
+
+// decodeQueryRequest reads the body of a query request, keeping numbers exact
+func decodeQueryRequest(c *gin.Context) (queryRequest, error) {
+	var request queryRequest
+	if c.Request.Body == nil {
+		return request, nil
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return request, err
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return request, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&request); err != nil {
+		return request, fmt.Errorf("invalid query: %w", err)
+	}
+	return request, nil
+}
+
+// pagination returns the page requested by the body, active when it sets page or limit
+func (r queryRequest) pagination() (pagination, error) {
+	p := pagination{Page: 1, Limit: defaultPageSize, Active: r.Page != 0 || r.Limit != 0}
+	if r.Page < 0 {
+		return p, errors.New("page must be a positive integer")
+	}
+	if r.Limit < 0 {
+		return p, errors.New("limit must be a positive integer")
+	}
+	if r.Page > 0 {
+		p.Page = r.Page
+	}
+	if r.Limit > 0 {
+		p.Limit = min(r.Limit, maxPageSize)
+	}
+	return p, nil
+}
+
+// queryScope builds the WHERE and ORDER BY clauses of a query request, checking every
+// field against the fields of the model
+func (g *APIGenerator) queryScope(modelInfo ModelInfo, request queryRequest) (func(*gorm.DB) *gorm.DB, error) {
+	var condition clause.Expression
+	if request.Filter != nil {
+		var err error
+		if condition, err = g.filterExpression(modelInfo, *request.Filter, 1); err != nil {
+			return nil, err
+		}
+	}
+
+	var columns []clause.OrderByColumn
+	for _, order := range request.Sort {
+		field, ok := modelInfo.fieldByJSONName(order.Field)
+		if !ok {
+			return nil, fmt.Errorf("cannot sort by unknown field %q", order.Field)
+		}
+		switch strings.ToLower(order.Order) {
+		case "", "asc":
+			columns = append(columns, clause.OrderByColumn{Column: clause.Column{Name: g.columnName(field)}})
+		case "desc":
+			columns = append(columns, clause.OrderByColumn{Column: clause.Column{Name: g.columnName(field)}, Desc: true})
+		default:
+			return nil, fmt.Errorf("sort order of %q must be asc or desc", order.Field)
+		}
+	}
+
+	return func(db *gorm.DB) *gorm.DB {
+		if condition != nil {
+			db = db.Where(condition)
+		}
+		for _, column := range columns {
+			db = db.Order(column)
+		}
+		return db
+	}, nil
+}
+
+// filterExpression converts a node of a filter tree to a SQL expression
+func (g *APIGenerator) filterExpression(modelInfo ModelInfo, filter queryFilter, depth int) (clause.Expression, error) {
+	if depth > maxFilterDepth {
+		return nil, fmt.Errorf("filters may not be nested more than %d levels deep", maxFilterDepth)
+	}
+
+	group := func(filters []queryFilter) ([]clause.Expression, error) {
+		if len(filters) == 0 {
+			return nil, errors.New("and and or groups need at least one condition")
+		}
+		expressions := make([]clause.Expression, 0, len(filters))
+		for _, child := range filters {
+			expression, err := g.filterExpression(modelInfo, child, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			expressions = append(expressions, expression)
+		}
+		return expressions, nil
+	}
+
+	switch {
+	case filter.And != nil && filter.Or == nil && filter.Field == "":
+		expressions, err := group(filter.And)
+		if err != nil {
+			return nil, err
+		}
+		return clause.And(expressions...), nil
+	case filter.Or != nil && filter.And == nil && filter.Field == "":
+		expressions, err := group(filter.Or)
+		if err != nil {
+			return nil, err
+		}
+		return clause.Or(expressions...), nil
+	case filter.Field != "" && filter.And == nil && filter.Or == nil:
+		return g.conditionExpression(modelInfo, filter)
+	}
+	return nil, errors.New("a filter must hold exactly one of and, or, or field")
+}
+
+// conditionExpression converts a field condition of a filter tree to a SQL expression
+func (g *APIGenerator) conditionExpression(modelInfo ModelInfo, filter queryFilter) (clause.Expression, error) {
+	field, ok := modelInfo.filterField(filter.Field)
+	if !ok {
+		return nil, fmt.Errorf("cannot filter by unknown field %q", filter.Field)
+	}
+	op := filter.Op
+	if op == "" {
+		op = "eq"
+	}
+	operator, ok := filterOperators[op]
+	if !ok {
+		return nil, fmt.Errorf("unknown filter operator %q for field %q", op, filter.Field)
+	}
+	column := clause.Column{Name: g.columnName(field)}
+
+	if op == "in" {
+		items, ok := filter.Value.([]any)
+		if !ok || len(items) == 0 {
+			return nil, fmt.Errorf("the in filter of %q needs a non-empty array", filter.Field)
+		}
+		values := make([]any, 0, len(items))
+		for _, item := range items {
+			value, err := queryValue(field, item)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for filter %q: %w", filter.Field, err)
+			}
+			values = append(values, value)
+		}
+		return clause.Expr{SQL: "? IN ?", Vars: []any{column, values}}, nil
+	}
+
+	value, err := queryValue(field, filter.Value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for filter %q: %w", filter.Field, err)
+	}
+	return clause.Expr{SQL: "? " + operator + " ?", Vars: []any{column, value}}, nil
+}
+
+// queryValue converts a JSON value of a filter to the Go type of the filtered field
+func queryValue(field FieldInfo, value any) (any, error) {
+	switch value := value.(type) {
+	case string:
+		return parseFilterValue(field.Type, value)
+	case json.Number, bool:
+		return parseFilterValue(field.Type, fmt.Sprint(value))
+	case nil:
+		return nil, errors.New("value is required")
+	}
+	return nil, errors.New("value must be a string, number or boolean")
+}
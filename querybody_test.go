@@ -0,0 +1,70 @@
+package apigen
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func TestQueryHandler(t *testing.T) {
+	g, router := newTestAPI(t, nil, &testUser{})
+	g.DB.Create(&[]testUser{
+		{Name: "Ada", Email: "ada@example.com"},
+		{Name: "Grace", Email: "grace@example.com"},
+		{Name: "Linus", Email: "linus@example.org"},
+	})
+
+	body := `{"filter":{"and":[{"field":"id","op":"gte","value":2},{"or":[{"field":"name","value":"Grace"},{"field":"email","op":"like","value":"%.org"}]}]},"sort":[{"field":"name","order":"desc"}]}`
+	w := serve(router, http.MethodPost, "/api/test_users/query", body)
+	users := decode[[]testUser](t, w)
+	if w.Code != http.StatusOK || len(users) != 2 || users[0].Name != "Linus" || users[1].Name != "Grace" {
+		t.Errorf("got %d %+v", w.Code, users)
+	}
+
+	w = serve(router, http.MethodPost, "/api/test_users/query", `{"sort":[{"field":"id"}],"page":2,"limit":2}`)
+	if users := decode[[]testUser](t, w); w.Code != http.StatusOK || len(users) != 1 || users[0].Name != "Linus" {
+		t.Errorf("page 2: got %d %+v", w.Code, users)
+	}
+
+	for name, body := range map[string]string{
+		"unknown field":    `{"filter":{"field":"password","value":"x"}}`,
+		"unknown operator": `{"filter":{"field":"name","op":"near","value":"x"}}`,
+		"empty group":      `{"filter":{"or":[]}}`,
+		"unknown sort":     `{"sort":[{"field":"password"}]}`,
+		"unknown key":      `{"where":{}}`,
+	} {
+		if w := serve(router, http.MethodPost, "/api/test_users/query", body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: got %d, want 400", name, w.Code)
+		}
+	}
+}
+
+func TestQueryScopeSQL(t *testing.T) {
+	g, _ := newTestAPI(t, nil, &testUser{})
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/test_users/query", strings.NewReader(`{"filter":{"and":[{"field":"id","op":"gte","value":2},{"or":[{"field":"name","value":"Grace"},{"field":"email","op":"like","value":"%.org"}]}]},"sort":[{"field":"name","order":"desc"}]}`))
+	request, err := decodeQueryRequest(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scope, err := g.queryScope(g.Models["testUser"], request)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var users []testUser
+	statement := g.DB.Session(&gorm.Session{DryRun: true}).Scopes(scope).Find(&users).Statement
+	sql := statement.SQL.String()
+	for _, want := range []string{"`id` >= ?", "(`name` = ? OR `email` LIKE ?)", "ORDER BY `name` DESC"} {
+		if !strings.Contains(sql, want) {
+			t.Errorf("SQL %q lacks %q", sql, want)
+		}
+	}
+	if len(statement.Vars) != 3 {
+		t.Errorf("got vars %v, want 3", statement.Vars)
+	}
+}
@@ -0,0 +1,59 @@
+package apigen
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rangeUnit is the unit of the Range headers paging through the records of a list
+const rangeUnit = "records"
+
+// parseRecordRange reads a Range header selecting records by position, e.g.
+// "records=0-24" for the first 25 records. An open range such as "records=50-" returns
+// a page of the default size. Other units and malformed ranges are ignored, as the
+// header is only a hint.
+func parseRecordRange(c *gin.Context) (pagination, bool) {
+	spec, ok := strings.CutPrefix(strings.TrimSpace(c.GetHeader("Range")), rangeUnit+"=")
+	if !ok || strings.Contains(spec, ",") {
+		return pagination{}, false
+	}
+	first, last, ok := strings.Cut(strings.TrimSpace(spec), "-")
+	if !ok {
+		return pagination{}, false
+	}
+
+	start, err := strconv.Atoi(first)
+	if err != nil || start < 0 {
+		return pagination{}, false
+	}
+	limit := defaultPageSize
+	if last != "" {
+		end, err := strconv.Atoi(last)
+		if err != nil || end < start {
+			return pagination{}, false
+		}
+		limit = end - start + 1
+	}
+	return pagination{Page: 1, Limit: min(limit, maxPageSize), Active: true, Ranged: true, Start: start}, true
+}
+
+// rangeNotSatisfiable responds 416 to a Range starting past the last record
+func (g *APIGenerator) rangeNotSatisfiable(c *gin.Context, page pagination, total int64) {
+	c.Header("Content-Range", fmt.Sprintf("%s */%d", rangeUnit, total))
+	g.respondError(c, http.StatusRequestedRangeNotSatisfiable, fmt.Errorf("range starts at record %d, past the %d matching records", page.Start, total))
+}
+
+// contentRange sets the Content-Range header of the records returned for a Range, e.g.
+// "records 0-24/100", and returns the status of the response
+func contentRange(c *gin.Context, page pagination, count int, total int64) int {
+	if count == 0 {
+		c.Header("Content-Range", fmt.Sprintf("%s */%d", rangeUnit, total))
+		return http.StatusOK
+	}
+	c.Header("Content-Range", fmt.Sprintf("%s %d-%d/%d", rangeUnit, page.Start, page.Start+count-1, total))
+	return http.StatusPartialContent
+}
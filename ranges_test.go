@@ -0,0 +1,43 @@
+package apigen
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestRangePagination(t *testing.T) {
+	g, router := newTestAPI(t, nil, &testUser{})
+	for i := 1; i <= 30; i++ {
+		g.DB.Create(&testUser{Name: fmt.Sprintf("user%d", i)})
+	}
+
+	w := serve(router, http.MethodGet, "/api/test_users?sort=id", "", "Range", "records=10-14")
+	users := decode[[]testUser](t, w)
+	if w.Code != http.StatusPartialContent || w.Header().Get("Content-Range") != "records 10-14/30" {
+		t.Fatalf("got %d %v", w.Code, w.Header())
+	}
+	if len(users) != 5 || users[0].ID != 11 || users[4].ID != 15 {
+		t.Errorf("got %+v, want users 11 to 15", users)
+	}
+
+	// The end is clamped to the last record
+	w = serve(router, http.MethodGet, "/api/test_users?sort=id", "", "Range", "records=25-99")
+	if users := decode[[]testUser](t, w); w.Code != http.StatusPartialContent || len(users) != 5 || w.Header().Get("Content-Range") != "records 25-29/30" {
+		t.Errorf("clamped: got %d %d users %v", w.Code, len(users), w.Header())
+	}
+
+	w = serve(router, http.MethodGet, "/api/test_users", "", "Range", "records=30-40")
+	if w.Code != http.StatusRequestedRangeNotSatisfiable || w.Header().Get("Content-Range") != "records */30" {
+		t.Errorf("past the end: got %d %v", w.Code, w.Header())
+	}
+
+	// Query parameters win over the header, and other units are ignored
+	w = serve(router, http.MethodGet, "/api/test_users?page=2&limit=10", "", "Range", "records=0-4")
+	if users := decode[[]testUser](t, w); w.Code != http.StatusOK || len(users) != 10 || users[0].ID != 11 {
+		t.Errorf("query pagination: got %d %d users", w.Code, len(users))
+	}
+	if w := serve(router, http.MethodGet, "/api/test_users", "", "Range", "bytes=0-4"); w.Code != http.StatusOK || w.Header().Get("Accept-Ranges") != "records" {
+		t.Errorf("bytes range: got %d %v", w.Code, w.Header())
+	}
+}
@@ -0,0 +1,158 @@
+package apigen
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/time/rate"
+)
+
+// anyMethod is the RateLimits key used for limits that apply to every HTTP method
+const anyMethod = "*"
+
+// RateLimitStore decides whether a request identified by key may proceed under a
+// limit of rpm requests per minute. Implement it to share limits across instances,
+// for example with a Redis backed token bucket.
+type RateLimitStore interface {
+	// Allow consumes a token for key. When no token is available it returns false
+	// and the time until the next token becomes available.
+	Allow(key string, rpm int) (bool, time.Duration)
+}
+
+// Bounds of the in-memory rate limit store. A token bucket left idle for a minute has
+// refilled completely, so forgetting it doesn't change the limit its client sees.
+const (
+	defaultRateLimitKeys    = 100_000
+	rateLimitIdleExpiration = time.Minute
+)
+
+// memoryRateLimitStore keeps one token bucket per key in process memory, forgetting
+// the buckets left idle and, past maxKeys, the least recently used ones
+type memoryRateLimitStore struct {
+	mu      sync.Mutex
+	maxKeys int
+	buckets map[string]*list.Element // key -> element of recent holding a *rateLimitBucket
+	recent  *list.List               // Most recently used bucket first
+	timeNow func() time.Time
+}
+
+// rateLimitBucket is the token bucket of a key
+type rateLimitBucket struct {
+	key      string
+	limiter  *rate.Limiter
+	lastUsed time.Time
+}
+
+// NewMemoryRateLimitStore creates an in-memory RateLimitStore holding the buckets of
+// up to 100,000 clients
+func NewMemoryRateLimitStore() RateLimitStore {
+	return NewMemoryRateLimitStoreSize(defaultRateLimitKeys)
+}
+
+// NewMemoryRateLimitStoreSize creates an in-memory RateLimitStore holding the buckets
+// of up to maxKeys clients. The least recently seen client starts over with a full
+// bucket when a new one pushes it out.
+func NewMemoryRateLimitStoreSize(maxKeys int) RateLimitStore {
+	if maxKeys <= 0 {
+		maxKeys = defaultRateLimitKeys
+	}
+	return &memoryRateLimitStore{
+		maxKeys: maxKeys,
+		buckets: make(map[string]*list.Element),
+		recent:  list.New(),
+		timeNow: time.Now,
+	}
+}
+
+// Allow implements RateLimitStore
+func (s *memoryRateLimitStore) Allow(key string, rpm int) (bool, time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.timeNow()
+	s.evict(now)
+
+	var bucket *rateLimitBucket
+	if element, ok := s.buckets[key]; ok {
+		bucket = element.Value.(*rateLimitBucket)
+		s.recent.MoveToFront(element)
+	} else {
+		bucket = &rateLimitBucket{key: key, limiter: rate.NewLimiter(rate.Every(time.Minute/time.Duration(rpm)), rpm)}
+		s.buckets[key] = s.recent.PushFront(bucket)
+	}
+	bucket.lastUsed = now
+
+	reservation := bucket.limiter.ReserveN(now, 1)
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return false, delay
+	}
+	return true, 0
+}
+
+// evict forgets the buckets idle since before the expiration, and the least recently
+// used ones while the store is full
+func (s *memoryRateLimitStore) evict(now time.Time) {
+	for element := s.recent.Back(); element != nil; element = s.recent.Back() {
+		bucket := element.Value.(*rateLimitBucket)
+		if s.recent.Len() < s.maxKeys && now.Sub(bucket.lastUsed) < rateLimitIdleExpiration {
+			return
+		}
+		s.recent.Remove(element)
+		delete(s.buckets, bucket.key)
+	}
+}
+
+// RateLimit limits every endpoint of a model to rpm requests per minute per client IP.
+//
+// Deprecated: use WithRateLimit.
+func RateLimit(rpm int) ModelOption {
+	return WithRateLimit(rpm)
+}
+
+// RateLimitMethod limits the endpoints of a model served under an HTTP method.
+//
+// Deprecated: use WithMethodRateLimit.
+func RateLimitMethod(method string, rpm int) ModelOption {
+	return WithMethodRateLimit(method, rpm)
+}
+
+// rateLimitClient identifies the client of a rate limited request by the address of
+// its connection. Forwarding headers are ignored: clients could send a new one with
+// every request to get a fresh bucket.
+func rateLimitClient(c *gin.Context) string {
+	return c.RemoteIP()
+}
+
+// rateLimitMiddleware returns the rate limiting middleware for a model's endpoints
+// served under method, or nil if they are not rate limited
+func (g *APIGenerator) rateLimitMiddleware(modelInfo ModelInfo, method string) gin.HandlerFunc {
+	rpm, ok := modelInfo.RateLimits[method]
+	if !ok {
+		rpm, ok = modelInfo.RateLimits[anyMethod]
+	}
+	if !ok || rpm <= 0 {
+		return nil
+	}
+
+	client := g.RateLimitKey
+	if client == nil {
+		client = rateLimitClient
+	}
+	return func(c *gin.Context) {
+		key := fmt.Sprintf("%s:%s:%s", modelInfo.Type.Name(), method, client(c))
+		if allowed, retryAfter := g.RateLimitStore.Allow(key, rpm); !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			g.respondError(c, http.StatusTooManyRequests, errors.New("Rate limit exceeded"))
+			return
+		}
+		c.Next()
+	}
+}
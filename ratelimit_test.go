@@ -0,0 +1,69 @@
+package apigen
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestMemoryRateLimitStore(t *testing.T) {
+	store := NewMemoryRateLimitStoreSize(2).(*memoryRateLimitStore)
+	now := time.Unix(0, 0)
+	store.timeNow = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if allowed, _ := store.Allow("a", 3); !allowed {
+			t.Fatalf("request %d under the limit was rejected", i+1)
+		}
+	}
+	if allowed, retryAfter := store.Allow("a", 3); allowed || retryAfter <= 0 {
+		t.Errorf("request over the limit: got allowed=%v retryAfter=%v", allowed, retryAfter)
+	}
+
+	// A third client pushes the least recently used one out
+	store.Allow("b", 3)
+	store.Allow("c", 3)
+	if _, ok := store.buckets["a"]; ok || len(store.buckets) != 2 {
+		t.Errorf("store over capacity holds %d buckets", len(store.buckets))
+	}
+
+	// Idle buckets are forgotten
+	now = now.Add(rateLimitIdleExpiration)
+	store.Allow("d", 3)
+	if len(store.buckets) != 1 {
+		t.Errorf("idle buckets were kept: %d buckets", len(store.buckets))
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	_, router := newTestAPI(t, func(g *APIGenerator) {
+		if err := g.RegisterModelWithOptions(&testUser{}, WithMethodRateLimit(http.MethodGet, 1)); err != nil {
+			t.Fatal(err)
+		}
+	}, &testUser{})
+
+	if w := serve(router, http.MethodGet, "/api/test_users", ""); w.Code != http.StatusOK {
+		t.Fatalf("first request: got %d", w.Code)
+	}
+	w := serve(router, http.MethodGet, "/api/test_users", "")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+		t.Errorf("second request: got %d with Retry-After %q", w.Code, w.Header().Get("Retry-After"))
+	}
+	if w := serve(router, http.MethodPost, "/api/test_users", `{"name":"a"}`); w.Code != http.StatusCreated {
+		t.Errorf("POST, not limited: got %d", w.Code)
+	}
+}
+
+func TestRateLimitClient(t *testing.T) {
+	_, router := newTestAPI(t, func(g *APIGenerator) {
+		if err := g.RegisterModelWithOptions(&testUser{}, RateLimit(1)); err != nil {
+			t.Fatal(err)
+		}
+	}, &testUser{})
+
+	// Forwarding headers don't make a client new
+	serve(router, http.MethodGet, "/api/test_users", "", "X-Forwarded-For", "203.0.113.1")
+	if w := serve(router, http.MethodGet, "/api/test_users", "", "X-Forwarded-For", "203.0.113.2"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("request with another X-Forwarded-For: got %d, want 429", w.Code)
+	}
+}
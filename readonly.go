@@ -0,0 +1,42 @@
+package apigen
+
+import (
+	"reflect"
+	"slices"
+)
+
+// WithReadOnlyFields sets the JSON names of fields clients may not write, such as
+// created_at or computed totals. Values sent for them by create, update and patch
+// requests are ignored.
+func WithReadOnlyFields(fields ...string) ModelOption {
+	return func(info *ModelInfo) {
+		info.ReadOnlyFields = append(info.ReadOnlyFields, fields...)
+	}
+}
+
+// isReadOnly reports whether a field is listed in the model's ReadOnlyFields, or is
+// the soft delete timestamp, which only the delete and restore endpoints set
+func (m ModelInfo) isReadOnly(field FieldInfo) bool {
+	return field.Type == deletedAtType || slices.Contains(m.ReadOnlyFields, field.JSONName)
+}
+
+// preserveReadOnly saves the read-only fields of an instance and returns a function
+// restoring them, undoing whatever a request body set. New instances get back their
+// zero values, which lets GORM fill in timestamps and defaults.
+func (m ModelInfo) preserveReadOnly(instance any) func() {
+	value := reflect.Indirect(reflect.ValueOf(instance))
+	saved := map[string]reflect.Value{}
+	for _, field := range m.Fields {
+		if m.isReadOnly(field) {
+			original := reflect.New(field.Type).Elem()
+			original.Set(value.FieldByName(field.Name))
+			saved[field.Name] = original
+		}
+	}
+
+	return func() {
+		for name, original := range saved {
+			value.FieldByName(name).Set(original)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package apigen
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testReceipt has a server-generated timestamp
+type testReceipt struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Total     int       `json:"total"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func TestReadOnlyFields(t *testing.T) {
+	g, router := newTestAPI(t, func(g *APIGenerator) {
+		g.RegisterModelWithOptions(&testReceipt{}, WithReadOnlyFields("created_at"))
+	}, &testReceipt{})
+
+	w := serve(router, http.MethodPost, "/api/test_receipts", `{"total":42,"created_at":"2000-01-01T00:00:00Z"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: got %d %s", w.Code, w.Body)
+	}
+	var receipt testReceipt
+	g.DB.First(&receipt, 1)
+	if receipt.CreatedAt.Year() == 2000 || time.Since(receipt.CreatedAt) > time.Minute || receipt.Total != 42 {
+		t.Errorf("after create: %+v", receipt)
+	}
+
+	if w := serve(router, http.MethodPatch, "/api/test_receipts/1", `{"total":7,"created_at":"2000-01-01T00:00:00Z"}`); w.Code != http.StatusOK {
+		t.Fatalf("patch: got %d %s", w.Code, w.Body)
+	}
+	var patched testReceipt
+	g.DB.First(&patched, 1)
+	if !patched.CreatedAt.Equal(receipt.CreatedAt) || patched.Total != 7 {
+		t.Errorf("after patch: %+v", patched)
+	}
+
+	response := NewSwaggerGenerator(g.Models).GenerateResponseBody(g.Models["testReceipt"])
+	createdAt, _ := response["properties"].(*OrderedProperties).Get("created_at")
+	if createdAt.(map[string]any)["readOnly"] != true {
+		t.Errorf("swagger created_at: got %v", createdAt)
+	}
+	code, err := NewModelAnalyzer().GenerateRequestStruct(g.Models["testReceipt"], true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(code, "CreatedAt") {
+		t.Errorf("request struct lists the read-only field:\n%s", code)
+	}
+}
+
+// testRating has a score only the server computes
+type testRating struct {
+	ID    uint   `json:"id" gorm:"primaryKey"`
+	Code  string `json:"code" gorm:"uniqueIndex"`
+	Label string `json:"label"`
+	Score int    `json:"score"`
+}
+
+func TestReadOnlyFieldsUpsert(t *testing.T) {
+	g, router := newTestAPI(t, func(g *APIGenerator) {
+		g.RegisterModelWithOptions(&testRating{}, WithUniqueKey("code"), WithReadOnlyFields("score"))
+	}, &testRating{})
+	g.DB.Create(&testRating{Code: "a", Label: "old", Score: 42})
+
+	if w := serve(router, http.MethodPut, "/api/test_ratings", `{"code":"a","label":"new","score":7}`); w.Code != http.StatusOK {
+		t.Fatalf("upsert: got %d %s", w.Code, w.Body)
+	}
+	var rating testRating
+	g.DB.First(&rating, 1)
+	if rating.Score != 42 || rating.Label != "new" {
+		t.Errorf("after upsert: %+v", rating)
+	}
+}
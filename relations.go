@@ -0,0 +1,194 @@
+package apigen
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RelationType classifies a ForeignKeyInfo
+type RelationType string
+
+const (
+	// RelationPolymorphic marks an association declared with gorm:"polymorphic:Owner",
+	// whose records point back at the parent through an owner_id and owner_type column
+	RelationPolymorphic RelationType = "polymorphic"
+
+	// RelationHasOne marks a single associated record holding the foreign key, e.g.
+	// Profile Profile `gorm:"foreignKey:UserID"` for a Profile with a UserID field
+	RelationHasOne RelationType = "has_one"
+
+	// RelationManyToMany marks records associated through a join table, e.g.
+	// Tags []Tag `gorm:"many2many:post_tags"`
+	RelationManyToMany RelationType = "many_to_many"
+)
+
+// polymorphicRelation returns the ForeignKeyInfo of a field tagged gorm:"polymorphic:...",
+// e.g. Toys []Toy `gorm:"polymorphic:Owner"`
+func polymorphicRelation(field reflect.StructField) (ForeignKeyInfo, bool) {
+	polymorphic := gormTagValue(field, "polymorphic")
+	if polymorphic == "" {
+		return ForeignKeyInfo{}, false
+	}
+
+	related := field.Type
+	for related.Kind() == reflect.Ptr || related.Kind() == reflect.Slice {
+		related = related.Elem()
+	}
+	return ForeignKeyInfo{
+		FieldName:        field.Name,
+		RelatedModel:     related.Name(),
+		RelationType:     RelationPolymorphic,
+		PolymorphicType:  polymorphic,
+		PolymorphicValue: gormTagValue(field, "polymorphicValue"),
+	}, true
+}
+
+// manyToManyRelation returns the ForeignKeyInfo of a field tagged gorm:"many2many:...",
+// e.g. Tags []Tag `gorm:"many2many:post_tags"`
+func manyToManyRelation(field reflect.StructField) (ForeignKeyInfo, bool) {
+	if gormTagValue(field, "many2many") == "" {
+		return ForeignKeyInfo{}, false
+	}
+
+	related := field.Type
+	for related.Kind() == reflect.Ptr || related.Kind() == reflect.Slice {
+		related = related.Elem()
+	}
+	return ForeignKeyInfo{
+		FieldName:    field.Name,
+		RelatedModel: related.Name(),
+		RelationType: RelationManyToMany,
+	}, true
+}
+
+// hasOneRelation returns the ForeignKeyInfo of a struct field tagged gorm:"foreignKey:..."
+// naming a field of the related model, e.g. Profile Profile `gorm:"foreignKey:UserID"`.
+// A foreign key of the model itself declares a belongs-to association instead.
+func hasOneRelation(modelType reflect.Type, field reflect.StructField) (ForeignKeyInfo, bool) {
+	foreignKey := gormTagValue(field, "foreignKey")
+	related := field.Type
+	if related.Kind() == reflect.Ptr {
+		related = related.Elem()
+	}
+	if foreignKey == "" || related.Kind() != reflect.Struct || isBasicType(related) {
+		return ForeignKeyInfo{}, false
+	}
+	if _, ok := related.FieldByName(foreignKey); !ok {
+		return ForeignKeyInfo{}, false
+	}
+	if _, ok := modelType.FieldByName(foreignKey); ok {
+		return ForeignKeyInfo{}, false
+	}
+	return ForeignKeyInfo{
+		FieldName:    field.Name,
+		RelatedModel: related.Name(),
+		RelatedField: foreignKey,
+		RelationType: RelationHasOne,
+	}, true
+}
+
+// routeName returns the last segment of the relationship route, e.g. "toys" for a
+// polymorphic Toys field, "profile" for a has-one Profile field, "tags" for a
+// many-to-many Tags field, or the snake_case related model name otherwise
+func (fk ForeignKeyInfo) routeName() string {
+	if fk.RelationType == RelationPolymorphic || fk.RelationType == RelationHasOne || fk.RelationType == RelationManyToMany {
+		return toSnakeCase(fk.FieldName)
+	}
+	return toSnakeCase(fk.RelatedModel)
+}
+
+// polymorphicScope matches the related records owned by a parent, e.g.
+// owner_id = 1 AND owner_type = 'dogs'. Like GORM, the owner type defaults to
+// the parent's table name.
+func (g *APIGenerator) polymorphicScope(modelInfo, relatedModelInfo ModelInfo, fk ForeignKeyInfo, parentID any) func(*gorm.DB) *gorm.DB {
+	ownerType := fk.ownerType(modelInfo)
+	table := relatedModelInfo.TableName
+	idColumn := g.DB.NamingStrategy.ColumnName("", fk.PolymorphicType+"ID")
+	typeColumn := g.DB.NamingStrategy.ColumnName("", fk.PolymorphicType+"Type")
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(clause.And(
+			clause.Eq{Column: clause.Column{Table: table, Name: idColumn}, Value: parentID},
+			clause.Eq{Column: clause.Column{Table: table, Name: typeColumn}, Value: ownerType},
+		))
+	}
+}
+
+// ownerType returns the owner type a polymorphic relation stores for the parent
+func (fk ForeignKeyInfo) ownerType(modelInfo ModelInfo) string {
+	if fk.PolymorphicValue != "" {
+		return fk.PolymorphicValue
+	}
+	return modelInfo.TableName
+}
+
+// backReference returns the fields of the related model pointing back at the parent:
+// the parent ID field, and the owner type field of polymorphic relations. It returns
+// false when the related records don't point back at the parent.
+func (fk ForeignKeyInfo) backReference(modelInfo, relatedModelInfo ModelInfo) (idField, typeField string, ok bool) {
+	switch {
+	case fk.RelationType == RelationPolymorphic:
+		idField, typeField = fk.PolymorphicType+"ID", fk.PolymorphicType+"Type"
+	case fk.RelationType == RelationHasOne:
+		idField = fk.RelatedField
+	case fk.RelationType == RelationManyToMany:
+		return "", "", false
+	case fk.RelationshipID == "":
+		idField = modelInfo.Type.Name() + "ID"
+	default:
+		return "", "", false
+	}
+
+	if _, found := relatedModelInfo.Type.FieldByName(idField); !found {
+		return "", "", false
+	}
+	if field, found := relatedModelInfo.Type.FieldByName(typeField); typeField != "" && (!found || field.Type.Kind() != reflect.String) {
+		return "", "", false
+	}
+	return idField, typeField, true
+}
+
+// canCreateRelated reports whether related records can be created through the
+// relationship route: the related model must be registered, accept POST and point
+// back at the parent
+func canCreateRelated(models map[string]ModelInfo, modelInfo ModelInfo, fk ForeignKeyInfo) bool {
+	relatedModelInfo, ok := models[fk.RelatedModel]
+	if !ok || !relatedModelInfo.verbEnabled(http.MethodPost) {
+		return false
+	}
+	_, _, ok = fk.backReference(modelInfo, relatedModelInfo)
+	return ok
+}
+
+// setBackReference points a new related record at its parent
+func (fk ForeignKeyInfo) setBackReference(modelInfo, relatedModelInfo ModelInfo, instance, parent any) error {
+	idField, typeField, _ := fk.backReference(modelInfo, relatedModelInfo)
+	value := reflect.Indirect(reflect.ValueOf(instance))
+
+	field := value.FieldByName(idField)
+	parentID := reflect.Indirect(reflect.ValueOf(parent)).FieldByName(modelInfo.PrimaryKeyField.Name)
+	if !parentID.IsValid() || !parentID.Type().ConvertibleTo(field.Type()) {
+		return fmt.Errorf("cannot store the ID of %s in %s.%s", modelInfo.Type.Name(), relatedModelInfo.Type.Name(), idField)
+	}
+	field.Set(parentID.Convert(field.Type()))
+
+	if typeField != "" {
+		value.FieldByName(typeField).SetString(fk.ownerType(modelInfo))
+	}
+	return nil
+}
+
+// gormTagValue returns the value of a setting in the gorm tag of a field, if any
+func gormTagValue(field reflect.StructField, name string) string {
+	for _, setting := range strings.Split(field.Tag.Get("gorm"), ";") {
+		key, value, _ := strings.Cut(setting, ":")
+		if strings.EqualFold(strings.TrimSpace(key), name) {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,158 @@
+package apigen
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+// testToy belongs to a testCat or a testDog
+type testToy struct {
+	ID        uint   `json:"id" gorm:"primaryKey"`
+	Name      string `json:"name"`
+	OwnerID   uint   `json:"owner_id"`
+	OwnerType string `json:"owner_type"`
+}
+
+type testCat struct {
+	ID   uint      `json:"id" gorm:"primaryKey"`
+	Name string    `json:"name"`
+	Toys []testToy `json:"toys,omitempty" gorm:"polymorphic:Owner"`
+}
+
+type testDog struct {
+	ID   uint      `json:"id" gorm:"primaryKey"`
+	Name string    `json:"name"`
+	Toys []testToy `json:"toys,omitempty" gorm:"polymorphic:Owner;polymorphicValue:dog"`
+}
+
+func TestPolymorphicRelation(t *testing.T) {
+	g, router := newTestAPI(t, nil, &testCat{}, &testDog{}, &testToy{})
+	// The cat and the dog share ID 1, so only the owner type tells their toys apart
+	g.DB.Create(&testCat{Name: "Tom", Toys: []testToy{{Name: "mouse"}, {Name: "yarn"}}})
+	g.DB.Create(&testDog{Name: "Rex", Toys: []testToy{{Name: "bone"}}})
+
+	if fk := g.Models["testCat"].ForeignKeys[0]; fk.RelationType != RelationPolymorphic || fk.PolymorphicType != "Owner" {
+		t.Errorf("cat relation: got %+v", fk)
+	}
+
+	toys := decode[[]testToy](t, serve(router, http.MethodGet, "/api/test_cats/1/toys", ""))
+	if len(toys) != 2 || toys[0].OwnerType != "test_cats" {
+		t.Errorf("cat toys: got %+v", toys)
+	}
+	toys = decode[[]testToy](t, serve(router, http.MethodGet, "/api/test_dogs/1/toys", ""))
+	if len(toys) != 1 || toys[0].Name != "bone" {
+		t.Errorf("dog toys: got %+v", toys)
+	}
+}
+
+func TestPolymorphicRelationUnregistered(t *testing.T) {
+	_, router := newTestAPI(t, func(g *APIGenerator) {
+		g.RegisterModelWithOptions(&testCat{})
+	}, &testCat{}, &testToy{})
+
+	if w := serve(router, http.MethodGet, "/api/test_cats/1/toys", ""); w.Code != http.StatusNotFound {
+		t.Errorf("route of an unregistered model: got %d, want 404", w.Code)
+	}
+}
+
+func TestCreateRelated(t *testing.T) {
+	g, router := newTestAPI(t, nil, &testCat{}, &testToy{})
+	g.DB.Create(&testCat{Name: "Tom"})
+
+	w := serve(router, http.MethodPost, "/api/test_cats/1/toys", `{"name":"ball","owner_id":7}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: got %d %s", w.Code, w.Body)
+	}
+	var toy testToy
+	g.DB.First(&toy)
+	if toy.Name != "ball" || toy.OwnerID != 1 || toy.OwnerType != "test_cats" {
+		t.Errorf("created toy: %+v", toy)
+	}
+
+	if w := serve(router, http.MethodPost, "/api/test_cats/2/toys", `{"name":"ball"}`); w.Code != http.StatusNotFound {
+		t.Errorf("missing parent: got %d, want 404", w.Code)
+	}
+
+	swagger := NewSwaggerGenerator(g.Models)
+	swagger.BuildPathsForAllModels()
+	if _, ok := swagger.GenerateAllPaths()["/api/test_cats/{id}/toys"].(map[string]any)["post"]; !ok {
+		t.Error("swagger misses the POST operation of the relationship path")
+	}
+}
+
+// testGarage has a UUID primary key, which its cars point back at
+type testGarage struct {
+	ID   string    `json:"id" gorm:"primaryKey;type:uuid"`
+	Name string    `json:"name"`
+	Cars []testCar `json:"cars,omitempty" gorm:"polymorphic:Owner"`
+	Tags []testTag `json:"tags,omitempty" gorm:"many2many:test_garage_tags"`
+}
+
+type testCar struct {
+	ID        uint   `json:"id" gorm:"primaryKey"`
+	Name      string `json:"name"`
+	OwnerID   string `json:"owner_id"`
+	OwnerType string `json:"owner_type"`
+}
+
+func TestRelatedStringPrimaryKey(t *testing.T) {
+	g, router := newTestAPI(t, nil, &testGarage{}, &testCar{}, &testTag{})
+	const first, second = "8f14e45f-ceea-467a-9575-1d3b3b4a3b3a", "c9f0f895-fb98-4b91-8f3b-9c2b1d2f7a1e"
+	g.DB.Create(&testGarage{ID: first, Name: "North", Cars: []testCar{{Name: "Beetle"}, {Name: "Mini"}}, Tags: []testTag{{Name: "busy"}}})
+	g.DB.Create(&testGarage{ID: second, Name: "South", Cars: []testCar{{Name: "Fiat"}}})
+
+	w := serve(router, http.MethodGet, "/api/test_garages/"+first+"/cars", "")
+	if cars := decode[[]testCar](t, w); w.Code != http.StatusOK || len(cars) != 2 || cars[0].OwnerID != first {
+		t.Errorf("cars: got %d %s", w.Code, w.Body)
+	}
+	w = serve(router, http.MethodGet, "/api/test_garages/"+first+"/tags", "")
+	if tags := decode[[]testTag](t, w); w.Code != http.StatusOK || len(tags) != 1 || tags[0].Name != "busy" {
+		t.Errorf("tags: got %d %s", w.Code, w.Body)
+	}
+	if w := serve(router, http.MethodGet, "/api/test_garages/00000000-0000-0000-0000-000000000000/cars", ""); w.Code != http.StatusNotFound {
+		t.Errorf("missing garage: got %d, want 404", w.Code)
+	}
+	if w := serve(router, http.MethodGet, "/api/test_garages/not-a-uuid/cars", ""); w.Code != http.StatusBadRequest && w.Code != http.StatusNotFound {
+		t.Errorf("malformed ID: got %d", w.Code)
+	}
+}
+
+func TestRelatedPagination(t *testing.T) {
+	g, router := newTestAPI(t, func(g *APIGenerator) {
+		g.TotalCountHeader = true
+	}, &testCat{}, &testToy{}, &testArticle{}, &testTag{})
+	toys := make([]testToy, 50)
+	for i := range toys {
+		toys[i].Name = fmt.Sprintf("toy%d", i+1)
+	}
+	g.DB.Create(&testCat{Name: "Tom", Toys: toys})
+	tags := make([]testTag, 25)
+	for i := range tags {
+		tags[i].Name = fmt.Sprintf("tag%d", i+1)
+	}
+	g.DB.Create(&testArticle{Title: "Tagged", Tags: tags})
+
+	w := serve(router, http.MethodGet, "/api/test_cats/1/toys?page=1&limit=10", "")
+	if page := decode[[]testToy](t, w); w.Code != http.StatusOK || len(page) != 10 || page[0].Name != "toy1" {
+		t.Errorf("first page of toys: got %d %d toys", w.Code, len(page))
+	}
+	if total := w.Header().Get(totalCountHeader); total != "50" {
+		t.Errorf("toys total: got %q, want 50", total)
+	}
+	if page := decode[[]testToy](t, serve(router, http.MethodGet, "/api/test_cats/1/toys?page=5&limit=10", "")); len(page) != 10 || page[0].Name != "toy41" {
+		t.Errorf("last page of toys: got %+v", page)
+	}
+
+	w = serve(router, http.MethodGet, "/api/test_articles/1/tags?page=3&limit=10", "")
+	if page := decode[[]testTag](t, w); w.Code != http.StatusOK || len(page) != 5 {
+		t.Errorf("last page of tags: got %d %d tags", w.Code, len(page))
+	}
+	if total := w.Header().Get(totalCountHeader); total != "25" {
+		t.Errorf("tags total: got %q, want 25", total)
+	}
+
+	if w := serve(router, http.MethodGet, "/api/test_cats/1/toys?limit=0", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid limit: got %d, want 400", w.Code)
+	}
+}
@@ -0,0 +1,86 @@
+package apigen
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// RequestIDKey is the gin context key holding the ID of a request
+	RequestIDKey = "apigen.request_id"
+	// requestIDAttribute is the span attribute carrying the ID of a request
+	requestIDAttribute = "http.request_id"
+	// maxRequestIDLength caps the length of the request IDs accepted from clients
+	maxRequestIDLength = 128
+)
+
+// RequestIDOptions configures RequestIDMiddleware
+type RequestIDOptions struct {
+	HeaderName          string        // Header carrying the request ID, X-Request-ID if empty
+	Generator           func() string // Generates the IDs of requests without one, random UUIDs if nil
+	PropagateToResponse bool          // Whether the ID is echoed back in the response header
+}
+
+// RequestIDMiddleware gives every request an ID, taken from the request header or
+// generated, stored under RequestIDKey. Error responses and log entries carry it, and
+// so do the spans of the request when tracing is enabled.
+func RequestIDMiddleware(opts RequestIDOptions) gin.HandlerFunc {
+	header := opts.HeaderName
+	if header == "" {
+		header = requestIDHeader
+	}
+	generate := opts.Generator
+	if generate == nil {
+		generate = uuid.NewString
+	}
+
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(header)
+		if !validRequestID(requestID) {
+			requestID = generate()
+		}
+		c.Set(RequestIDKey, requestID)
+		if opts.PropagateToResponse {
+			c.Header(header, requestID)
+		}
+		trace.SpanFromContext(c.Request.Context()).SetAttributes(attribute.String(requestIDAttribute, requestID))
+		c.Next()
+	}
+}
+
+// validRequestID tells whether a client supplied request ID is safe to log and echo:
+// not empty, not too long, and printable ASCII only
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// withRequestID adds the request ID, if any, to an error response body built as a map
+func withRequestID(c *gin.Context, body any) any {
+	requestID := c.GetString(RequestIDKey)
+	if requestID == "" {
+		return body
+	}
+	var values map[string]any
+	switch body := body.(type) {
+	case gin.H:
+		values = body
+	case map[string]any:
+		values = body
+	default:
+		return body
+	}
+	if _, exists := values["request_id"]; !exists {
+		values["request_id"] = requestID
+	}
+	return body
+}
@@ -0,0 +1,51 @@
+package apigen
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	_, router := newTestAPI(t, func(g *APIGenerator) {
+		g.Group.Use(RequestIDMiddleware(RequestIDOptions{PropagateToResponse: true}))
+	}, &testUser{})
+
+	w := serve(router, http.MethodGet, "/api/test_users/1", "", "X-Request-ID", "req-42")
+	if got := w.Header().Get("X-Request-ID"); got != "req-42" {
+		t.Errorf("echoed ID: got %q", got)
+	}
+	if body := decode[map[string]any](t, w); w.Code != http.StatusNotFound || body["request_id"] != "req-42" {
+		t.Errorf("error response: got %d %v", w.Code, body)
+	}
+
+	w = serve(router, http.MethodGet, "/api/test_users", "")
+	if _, err := uuid.Parse(w.Header().Get("X-Request-ID")); err != nil {
+		t.Errorf("generated ID: %v", err)
+	}
+
+	// IDs unsafe to log are replaced
+	w = serve(router, http.MethodGet, "/api/test_users", "", "X-Request-ID", strings.Repeat("x", 200))
+	if _, err := uuid.Parse(w.Header().Get("X-Request-ID")); err != nil {
+		t.Errorf("replaced ID: %v", err)
+	}
+}
+
+func TestRequestIDOptions(t *testing.T) {
+	_, router := newTestAPI(t, func(g *APIGenerator) {
+		g.Group.Use(RequestIDMiddleware(RequestIDOptions{
+			HeaderName: "X-Correlation-ID",
+			Generator:  func() string { return "fixed" },
+		}))
+	}, &testUser{})
+
+	w := serve(router, http.MethodGet, "/api/test_users/1", "")
+	if got := w.Header().Get("X-Correlation-ID"); got != "" {
+		t.Errorf("the ID was propagated: %q", got)
+	}
+	if body := decode[map[string]any](t, w); body["request_id"] != "fixed" {
+		t.Errorf("error response: got %v", body)
+	}
+}
@@ -0,0 +1,161 @@
+package apigen
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Envelope wraps every response in an object such as
+// {"status": "success", "data": {...}, "meta": {...}}. Empty keys take their default names.
+type Envelope struct {
+	DataKey   string // Key of the payload, "data" by default
+	StatusKey string // Key of "success" or "error", "status" by default
+	MetaKey   string // Key of the pagination info, "meta" by default
+	ErrorKey  string // Key of the error object, "error" by default
+}
+
+// withDefaults fills in the default names of the empty keys
+func (e Envelope) withDefaults() Envelope {
+	if e.DataKey == "" {
+		e.DataKey = "data"
+	}
+	if e.StatusKey == "" {
+		e.StatusKey = "status"
+	}
+	if e.MetaKey == "" {
+		e.MetaKey = "meta"
+	}
+	if e.ErrorKey == "" {
+		e.ErrorKey = "error"
+	}
+	return e
+}
+
+// WithEnvelope wraps all responses of the generated endpoints in the envelope
+func (g *APIGenerator) WithEnvelope(e Envelope) {
+	e = e.withDefaults()
+	g.envelope = &e
+}
+
+// respond writes a successful response, formatting it as a JSON:API document when
+// the client asked for one. A slice payload is returned as a resource collection.
+func (g *APIGenerator) respond(c *gin.Context, status int, modelInfo ModelInfo, payload any) {
+	g.respondWithMeta(c, status, modelInfo, payload, nil)
+}
+
+// respondWithMeta writes a successful response like respond, along with metadata such
+// as pagination info that is placed in the envelope or the JSON:API document
+func (g *APIGenerator) respondWithMeta(c *gin.Context, status int, modelInfo ModelInfo, payload any, meta map[string]any) {
+	g.respondWithFields(c, status, modelInfo, payload, meta, nil)
+}
+
+// respondWithFields writes a successful response like respondWithMeta, adding the
+// model's computed fields and keeping only the given fields of the records when
+// fields isn't nil
+func (g *APIGenerator) respondWithFields(c *gin.Context, status int, modelInfo ModelInfo, payload any, meta map[string]any, fields []string) {
+	g.respondWithLinks(c, status, modelInfo, payload, meta, fields, nil)
+}
+
+// respondWithLinks writes a successful response like respondWithFields, adding the HAL
+// links of a single record under _links when links isn't nil
+func (g *APIGenerator) respondWithLinks(c *gin.Context, status int, modelInfo ModelInfo, payload any, meta map[string]any, fields []string, links map[string]any) {
+	computed, err := g.computeFields(c, modelInfo, payload)
+	if err != nil {
+		g.respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	logDeprecatedFields(modelInfo, payload, fields)
+
+	if wantsJSONAPI(c) {
+		document := g.jsonAPIDocument(payload, modelInfo, meta)
+		if computed != nil || fields != nil {
+			selectAttributes(document, computed, fields)
+		}
+		if modelInfo.TransformResponse != nil {
+			if err := g.transformAttributes(c, modelInfo, document); err != nil {
+				g.respondError(c, http.StatusInternalServerError, err)
+				return
+			}
+		}
+		c.Header("Content-Type", JSONAPIMediaType)
+		c.JSON(status, document)
+		return
+	}
+
+	body, err := g.responseBody(c, modelInfo, payload, computed, fields, links)
+	if err != nil {
+		g.respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	if g.envelope == nil {
+		c.JSON(status, body)
+		return
+	}
+
+	wrapped := gin.H{
+		g.envelope.StatusKey: "success",
+		g.envelope.DataKey:   body,
+	}
+	if meta != nil {
+		wrapped[g.envelope.MetaKey] = meta
+	}
+	c.JSON(status, wrapped)
+}
+
+// responseBody builds the JSON body of an instance or a slice of instances: the
+// records keyed by their API field names, with their computed fields, passed through
+// the model's TransformResponse
+func (g *APIGenerator) responseBody(c *gin.Context, modelInfo ModelInfo, payload any, computed []map[string]any, fields []string, links map[string]any) (any, error) {
+	body := apiPayload(payload, modelInfo)
+	if computed != nil || fields != nil || modelInfo.TransformResponse != nil || links != nil {
+		body = selectFields(payload, modelInfo, computed, fields)
+	}
+	if modelInfo.TransformResponse != nil {
+		var err error
+		if body, err = g.transformBody(c, modelInfo, body); err != nil {
+			return nil, err
+		}
+	}
+	if record, ok := body.(map[string]any); ok && links != nil {
+		record[halLinksKey] = links
+	}
+	return body, nil
+}
+
+// respondError aborts the request with the error response built by the ErrorFormatter,
+// or by DefaultErrorFormatter wrapped in the envelope if one is set
+func (g *APIGenerator) respondError(c *gin.Context, status int, err error) {
+	_ = c.Error(err) // Recorded for the request logger
+	g.deleteUploads(c)
+	if status >= http.StatusInternalServerError {
+		// Queries cut short by the request context didn't fail on their own
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			status, err = http.StatusServiceUnavailable, errQueryTimeout
+		case errors.Is(err, context.Canceled):
+			status = statusClientClosedRequest
+		}
+	}
+
+	if g.ErrorFormatter != nil {
+		c.AbortWithStatusJSON(status, withRequestID(c, g.ErrorFormatter(c, status, err)))
+		return
+	}
+	if g.envelope == nil {
+		c.AbortWithStatusJSON(status, withRequestID(c, DefaultErrorFormatter(c, status, err)))
+		return
+	}
+
+	body := gin.H{"message": err.Error()}
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		body["errors"] = validationErr.Errors
+	}
+	c.AbortWithStatusJSON(status, withRequestID(c, gin.H{
+		g.envelope.StatusKey: "error",
+		g.envelope.ErrorKey:  body,
+	}))
+}
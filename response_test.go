@@ -0,0 +1,50 @@
+package apigen
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestEnvelope(t *testing.T) {
+	t.Run("wrapped", func(t *testing.T) {
+		g, router := newTestAPI(t, func(g *APIGenerator) {
+			g.WithEnvelope(Envelope{DataKey: "result"})
+		}, &testUser{})
+		g.DB.Create(&testUser{Name: "Ada"})
+
+		body := decode[map[string]any](t, serve(router, http.MethodGet, "/api/test_users/1", ""))
+		if body["status"] != "success" || body["result"].(map[string]any)["name"] != "Ada" {
+			t.Errorf("get: got %v", body)
+		}
+
+		body = decode[map[string]any](t, serve(router, http.MethodGet, "/api/test_users?page=1&limit=10", ""))
+		if records, ok := body["result"].([]any); !ok || len(records) != 1 || body["meta"] == nil {
+			t.Errorf("list: got %v", body)
+		}
+
+		w := serve(router, http.MethodGet, "/api/test_users/2", "")
+		body = decode[map[string]any](t, w)
+		if w.Code != http.StatusNotFound || body["status"] != "error" || body["error"].(map[string]any)["message"] == nil {
+			t.Errorf("missing record: got %d %v", w.Code, body)
+		}
+
+		swagger := NewSwaggerGenerator(g.Models)
+		envelope := Envelope{DataKey: "result"}.withDefaults()
+		swagger.Envelope = &envelope
+		swagger.BuildPathsForAllModels()
+		get := swagger.GenerateAllPaths()["/api/test_users/{id}"].(map[string]any)["get"].(map[string]any)
+		schema := get["responses"].(map[string]any)["200"].(map[string]any)["schema"].(map[string]any)
+		if _, ok := schema["properties"].(map[string]any)["result"]; !ok {
+			t.Errorf("swagger response is not wrapped: %v", schema)
+		}
+	})
+
+	t.Run("unwrapped", func(t *testing.T) {
+		g, router := newTestAPI(t, nil, &testUser{})
+		g.DB.Create(&testUser{Name: "Ada"})
+
+		if user := decode[testUser](t, serve(router, http.MethodGet, "/api/test_users/1", "")); user.Name != "Ada" {
+			t.Errorf("get: got %+v", user)
+		}
+	})
+}
@@ -0,0 +1,121 @@
+package apigen
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Route verbs describe what a registered endpoint does
+const (
+	VerbList           = "list"
+	VerbGet            = "get"
+	VerbCreate         = "create"
+	VerbUpdate         = "update"
+	VerbUpsert         = "upsert"
+	VerbDelete         = "delete"
+	VerbBulkDelete     = "bulk_delete"
+	VerbBulkPatch      = "bulk_patch"
+	VerbSearch         = "search"
+	VerbQuery          = "query"
+	VerbImport         = "import"
+	VerbCount          = "count"
+	VerbRelated        = "related"
+	VerbCreateRelated  = "create_related"
+	VerbReplaceRelated = "replace_related"
+	VerbLink           = "link"
+	VerbUnlink         = "unlink"
+	VerbCustom         = "custom"
+	VerbOptions        = "options"
+	VerbRestore        = "restore"
+	VerbDestroy        = "destroy"
+	VerbStream         = "stream"
+)
+
+// RouteInfo describes an endpoint registered by the generator
+type RouteInfo struct {
+	Method       string
+	Path         string
+	ModelName    string
+	RelatedModel string   // Model of the related or parent records the route reads or writes too, if any
+	Verb         string   // One of the Verb constants
+	Middleware   []string // Names of the middleware run before the handler, in order
+}
+
+// Routes returns the endpoints registered so far, in registration order
+func (g *APIGenerator) Routes() []RouteInfo {
+	return append([]RouteInfo(nil), g.routes...)
+}
+
+// PrintRoutes writes the registered endpoints to w as a table
+func (g *APIGenerator) PrintRoutes(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "METHOD\tPATH\tMODEL\tVERB\tMIDDLEWARE")
+	for _, route := range g.routes {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", route.Method, route.Path, route.ModelName, route.Verb, strings.Join(route.Middleware, ","))
+	}
+	return tw.Flush()
+}
+
+// handleOptions registers OPTIONS path answering 204 with the methods registered for
+// the path in the Allow header. It runs no model middleware and never hits the database.
+func (g *APIGenerator) handleOptions(modelInfo ModelInfo, path string) {
+	g.Group.OPTIONS(path, func(c *gin.Context) {
+		c.Header("Allow", strings.Join(g.allowedMethods(path), ", "))
+		c.Status(http.StatusNoContent)
+	})
+	g.routes = append(g.routes, RouteInfo{
+		Method:    http.MethodOptions,
+		Path:      path,
+		ModelName: modelInfo.Type.Name(),
+		Verb:      VerbOptions,
+	})
+}
+
+// handleDisabledVerbs registers the disabled verbs of a model on path, answering 405
+// with the methods registered for the path in the Allow header. Only the paths of the
+// model answer 405, leaving the engine's HandleMethodNotAllowed setting alone.
+func (g *APIGenerator) handleDisabledVerbs(modelInfo ModelInfo, path string) {
+	allowed := g.allowedMethods(path)
+	if len(allowed) == 0 {
+		return
+	}
+
+	header := strings.Join(allowed, ", ")
+	var disabled []string
+	for _, verb := range modelInfo.DisabledVerbs {
+		disabled = append(disabled, verb)
+		if verb == http.MethodGet {
+			disabled = append(disabled, http.MethodHead)
+		}
+	}
+	for i, method := range disabled {
+		// Each verb is registered once, and never over a route serving it
+		if !slices.Contains(crudMethods, method) || slices.Contains(allowed, method) || slices.Contains(disabled[:i], method) {
+			continue
+		}
+		g.Group.Handle(method, path, func(c *gin.Context) {
+			c.Header("Allow", header)
+			g.respondError(c, http.StatusMethodNotAllowed, fmt.Errorf("Method %s is not allowed", c.Request.Method))
+		})
+	}
+}
+
+// crudMethods are the HTTP methods WithDisabledVerbs can disable
+var crudMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// allowedMethods returns the methods registered for a path, in registration order
+func (g *APIGenerator) allowedMethods(path string) []string {
+	var methods []string
+	for _, route := range g.routes {
+		if route.Path == path && !slices.Contains(methods, route.Method) {
+			methods = append(methods, route.Method)
+		}
+	}
+	return methods
+}
@@ -0,0 +1,105 @@
+package apigen
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestDisabledVerbs(t *testing.T) {
+	var engine *gin.Engine
+	_, router := newTestAPI(t, func(g *APIGenerator) {
+		engine = g.Router
+		engine.GET("/other", func(c *gin.Context) { c.Status(http.StatusOK) })
+		if err := g.RegisterModelWithOptions(&testUser{}, WithDisabledVerbs("delete", "PUT")); err != nil {
+			t.Fatal(err)
+		}
+	}, &testUser{})
+
+	w := serve(router, http.MethodDelete, "/api/test_users/1", "")
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("DELETE disabled: got %d, want 405", w.Code)
+	}
+	if allow := w.Header().Get("Allow"); allow != "GET, HEAD, PATCH, OPTIONS" {
+		t.Errorf("Allow: got %q", allow)
+	}
+	if w := serve(router, http.MethodPut, "/api/test_users", `[]`); w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("PUT disabled on the collection: got %d, want 405", w.Code)
+	}
+
+	// Routes apigen doesn't own keep the engine's behaviour
+	if engine.HandleMethodNotAllowed {
+		t.Error("the engine's HandleMethodNotAllowed was turned on")
+	}
+	if w := serve(router, http.MethodDelete, "/other", ""); w.Code != http.StatusNotFound {
+		t.Errorf("DELETE on a route of the application: got %d, want 404", w.Code)
+	}
+}
+
+func TestRoutes(t *testing.T) {
+	g, _ := newTestAPI(t, func(g *APIGenerator) {
+		g.RegisterModelWithOptions(&testUser{}, WithAuthMiddleware(func(c *gin.Context) { c.Next() }))
+		g.RegisterModelWithOptions(&testStory{})
+	}, &testUser{}, &testStory{})
+
+	routes := g.Routes()
+	seen := map[string]RouteInfo{}
+	for _, route := range routes {
+		key := route.Method + " " + route.Path
+		if _, duplicate := seen[key]; duplicate {
+			t.Errorf("duplicate route %s", key)
+		}
+		seen[key] = route
+	}
+
+	for _, want := range []RouteInfo{
+		{Method: http.MethodGet, Path: "/api/test_users", ModelName: "testUser", Verb: VerbList},
+		{Method: http.MethodGet, Path: "/api/test_users/:id", ModelName: "testUser", Verb: VerbGet},
+		{Method: http.MethodPost, Path: "/api/test_users", ModelName: "testUser", Verb: VerbCreate},
+		{Method: http.MethodDelete, Path: "/api/test_users/:id", ModelName: "testUser", Verb: VerbDelete},
+		{Method: http.MethodGet, Path: "/api/test_users/count", ModelName: "testUser", Verb: VerbCount},
+		{Method: http.MethodGet, Path: "/api/stories/search", ModelName: "testStory", Verb: VerbSearch},
+		{Method: http.MethodPatch, Path: "/api/stories/:id", ModelName: "testStory", Verb: VerbUpdate},
+	} {
+		route, ok := seen[want.Method+" "+want.Path]
+		if !ok || route.ModelName != want.ModelName || route.Verb != want.Verb {
+			t.Errorf("route %s %s: got %+v", want.Method, want.Path, route)
+		}
+	}
+	if route := seen["GET /api/test_users"]; !slices.Contains(route.Middleware, "auth") {
+		t.Errorf("user route middleware: got %v", route.Middleware)
+	}
+	if route := seen["GET /api/stories"]; slices.Contains(route.Middleware, "auth") {
+		t.Errorf("story route middleware: got %v", route.Middleware)
+	}
+
+	var table strings.Builder
+	if err := g.PrintRoutes(&table); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(table.String(), "\n"); lines != len(routes)+1 {
+		t.Errorf("printed %d lines for %d routes:\n%s", lines, len(routes), table.String())
+	}
+}
+
+func TestOptions(t *testing.T) {
+	_, router := newTestAPI(t, func(g *APIGenerator) {
+		g.RegisterModelWithOptions(&testUser{})
+		g.RegisterModelWithOptions(&testStory{}, WithDisabledVerbs("POST", "DELETE"))
+	}, &testUser{}, &testStory{})
+
+	for path, want := range map[string]string{
+		"/api/test_users":   "GET, HEAD, POST, PUT, OPTIONS",
+		"/api/test_users/1": "GET, HEAD, PUT, PATCH, DELETE, OPTIONS",
+		"/api/stories":      "GET, HEAD, PUT, OPTIONS",
+		"/api/stories/1":    "GET, HEAD, PUT, PATCH, OPTIONS",
+	} {
+		w := serve(router, http.MethodOptions, path, "")
+		if w.Code != http.StatusNoContent || w.Header().Get("Allow") != want {
+			t.Errorf("OPTIONS %s: got %d with Allow %q, want %q", path, w.Code, w.Header().Get("Allow"), want)
+		}
+	}
+}
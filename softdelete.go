@@ -0,0 +1,124 @@
+package apigen
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// deletedAtType is the reflect.Type of the soft delete timestamp of GORM models
+var deletedAtType = reflect.TypeOf(gorm.DeletedAt{})
+
+// hasSoftDelete reports whether a model is soft deleted through a gorm.DeletedAt
+// field, e.g. one promoted from gorm.Model
+func hasSoftDelete(modelType reflect.Type) bool {
+	field, ok := modelType.FieldByName("DeletedAt")
+	return ok && field.Type == deletedAtType
+}
+
+// WithRestore registers POST /api/{plural}/{id}/restore, undeleting a soft deleted
+// record, and lets the list and get endpoints return soft deleted records when
+// called with ?include_deleted=true. It has no effect on models without soft delete.
+func WithRestore() ModelOption {
+	return func(info *ModelInfo) {
+		info.EnableRestore = true
+	}
+}
+
+// WithHardDelete registers DELETE /api/{plural}/{id}/destroy, removing a record for
+// good even when the model is soft deleted
+func WithHardDelete() ModelOption {
+	return func(info *ModelInfo) {
+		info.EnableHardDelete = true
+	}
+}
+
+// unscoped lifts GORM's soft delete filter
+func unscoped(db *gorm.DB) *gorm.DB {
+	return db.Unscoped()
+}
+
+// deletedScope returns a scope lifting the soft delete filter when the client asked
+// for soft deleted records with ?include_deleted=true and the model allows it
+func deletedScope(c *gin.Context, modelInfo ModelInfo) func(*gorm.DB) *gorm.DB {
+	include, _ := strconv.ParseBool(c.Query("include_deleted"))
+	if !include || !modelInfo.SoftDelete || !modelInfo.EnableRestore {
+		return func(db *gorm.DB) *gorm.DB { return db }
+	}
+	return unscoped
+}
+
+// restoreHandler returns a handler function for restoring a soft deleted instance of a model
+// @Summary Restore a model instance
+// @Description Restore a soft deleted instance of a model
+// @Tags API
+// @Produce json,application/vnd.api+json
+// @Param id path string true "ID of the model instance"
+// @Success 200 {object} any
+// @Failure 404 {object} map[string]string
+// @Router /api/{model}/{id}/restore [post]
+func (g *APIGenerator) restoreHandler(modelInfo ModelInfo) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Look the record up among the deleted ones too
+		instance, ok := g.loadInstance(c, modelInfo, unscoped)
+		if !ok {
+			return
+		}
+		before := snapshot(instance, modelInfo)
+
+		// Clear the deletion timestamp
+		column := g.DB.NamingStrategy.ColumnName("", "DeletedAt")
+		if err := g.writeDB(c, modelInfo).Unscoped().Model(instance).Update(column, nil).Error; err != nil {
+			g.respondDBError(c, err)
+			return
+		}
+		reflect.ValueOf(instance).Elem().FieldByName("DeletedAt").Set(reflect.Zero(deletedAtType))
+		g.audit(c, modelInfo, before, instance)
+
+		// Return the restored instance
+		g.respond(c, http.StatusOK, modelInfo, instance)
+	}
+}
+
+// destroyHandler returns a handler function for permanently deleting an instance of a model
+// @Summary Permanently delete a model instance
+// @Description Delete an instance of a model for good, bypassing soft delete
+// @Tags API
+// @Produce json
+// @Param id path string true "ID of the model instance"
+// @Success 204 {object} nil
+// @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Router /api/{model}/{id}/destroy [delete]
+func (g *APIGenerator) destroyHandler(modelInfo ModelInfo) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Soft deleted records can be destroyed too
+		instance, ok := g.loadInstance(c, modelInfo, unscoped)
+		if !ok {
+			return
+		}
+
+		if err := runHook(modelInfo.Hooks.BeforeDelete, c, instance); err != nil {
+			g.respondError(c, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		// Delete the row from the database
+		if err := g.writeDB(c, modelInfo).Unscoped().Delete(instance).Error; err != nil {
+			g.respondDBError(c, err)
+			return
+		}
+
+		if err := runHook(modelInfo.Hooks.AfterDelete, c, instance); err != nil {
+			g.respondError(c, http.StatusInternalServerError, err)
+			return
+		}
+		g.audit(c, modelInfo, nil, instance)
+
+		// Return no content
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,108 @@
+package apigen
+
+import (
+	"net/http"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// testComment is soft deleted
+type testComment struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	Body      string         `json:"body"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at"`
+}
+
+// countComments returns the number of comments listed by the API
+func countComments(t *testing.T, router http.Handler, query string) int {
+	t.Helper()
+	return len(decode[[]testComment](t, serve(router, http.MethodGet, "/api/test_comments"+query, "")))
+}
+
+func TestRestoreAndDestroy(t *testing.T) {
+	g, router := newTestAPI(t, func(g *APIGenerator) {
+		g.RegisterModelWithOptions(&testComment{}, WithRestore(), WithHardDelete())
+	}, &testComment{})
+	g.DB.Create(&testComment{Body: "first"})
+
+	if w := serve(router, http.MethodDelete, "/api/test_comments/1", ""); w.Code >= http.StatusBadRequest {
+		t.Fatalf("delete: got %d %s", w.Code, w.Body)
+	}
+	if n := countComments(t, router, ""); n != 0 {
+		t.Errorf("list after soft delete: got %d comments", n)
+	}
+	if n := countComments(t, router, "?include_deleted=true"); n != 1 {
+		t.Errorf("list with deleted after soft delete: got %d comments", n)
+	}
+
+	if w := serve(router, http.MethodPost, "/api/test_comments/1/restore", ""); w.Code != http.StatusOK {
+		t.Fatalf("restore: got %d %s", w.Code, w.Body)
+	}
+	if n := countComments(t, router, ""); n != 1 {
+		t.Errorf("list after restore: got %d comments", n)
+	}
+
+	if w := serve(router, http.MethodDelete, "/api/test_comments/1/destroy", ""); w.Code >= http.StatusBadRequest {
+		t.Fatalf("destroy: got %d %s", w.Code, w.Body)
+	}
+	if n := countComments(t, router, "?include_deleted=true"); n != 0 {
+		t.Errorf("list with deleted after destroy: got %d comments", n)
+	}
+	if w := serve(router, http.MethodPost, "/api/test_comments/1/restore", ""); w.Code != http.StatusNotFound {
+		t.Errorf("restore after destroy: got %d, want 404", w.Code)
+	}
+}
+
+func TestRestoreDisabled(t *testing.T) {
+	_, router := newTestAPI(t, nil, &testComment{})
+
+	if w := serve(router, http.MethodPost, "/api/test_comments/1/restore", ""); w.Code != http.StatusNotFound {
+		t.Errorf("restore without the option: got %d, want 404", w.Code)
+	}
+	if w := serve(router, http.MethodDelete, "/api/test_comments/1/destroy", ""); w.Code != http.StatusNotFound {
+		t.Errorf("destroy without the option: got %d, want 404", w.Code)
+	}
+}
+
+// testMemo is soft deleted through its embedded gorm.Model
+type testMemo struct {
+	gorm.Model
+	Text string `json:"text"`
+}
+
+func TestDeletedAtField(t *testing.T) {
+	g, router := newTestAPI(t, nil, &testMemo{}, &testComment{})
+	if !g.Models["testMemo"].SoftDelete || !g.Models["testComment"].SoftDelete {
+		t.Fatal("gorm.DeletedAt fields don't mark the models soft deleted")
+	}
+
+	g.DB.Create(&testMemo{Text: "remember"})
+	if w := serve(router, http.MethodDelete, "/api/test_memos/1", ""); w.Code >= http.StatusBadRequest {
+		t.Fatalf("delete: got %d %s", w.Code, w.Body)
+	}
+	if w := serve(router, http.MethodGet, "/api/test_memos/1", ""); w.Code != http.StatusNotFound {
+		t.Errorf("get after delete: got %d, want 404", w.Code)
+	}
+	var memo testMemo
+	if err := g.DB.Unscoped().First(&memo, 1).Error; err != nil || !memo.DeletedAt.Valid {
+		t.Errorf("the memo wasn't soft deleted: %+v %v", memo, err)
+	}
+
+	// The timestamp is documented, but never accepted in request bodies
+	swagger := NewSwaggerGenerator(g.Models)
+	if schema := swagger.getSwaggerType(deletedAtType); schema["type"] != "string" || schema["format"] != "date-time" || schema["x-nullable"] != true {
+		t.Errorf("DeletedAt schema: got %v", schema)
+	}
+	comment := g.Models["testComment"]
+	request := swagger.GenerateRequestBody(comment, true)["properties"].(*OrderedProperties)
+	if _, ok := request.Get("deleted_at"); ok {
+		t.Errorf("the request body accepts deleted_at: %v", request.Keys())
+	}
+	if w := serve(router, http.MethodPost, "/api/test_comments", `{"body":"hi","deleted_at":"2024-01-01T00:00:00Z"}`); w.Code != http.StatusCreated {
+		t.Fatalf("create: got %d %s", w.Code, w.Body)
+	}
+	if n := countComments(t, router, ""); n != 1 {
+		t.Errorf("the client soft deleted its comment: got %d comments", n)
+	}
+}
@@ -0,0 +1,169 @@
+package apigen
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	defaultSSEPollInterval = 2 * time.Second
+	defaultSSEConnections  = 100
+)
+
+// SSEOptions configures an endpoint streaming the new records of a model
+type SSEOptions struct {
+	PollInterval   time.Duration           // How often the table is polled, 2 seconds by default
+	MaxConnections int                     // Concurrent clients, 100 by default; others get 503
+	FilterFunc     func(*gorm.DB) *gorm.DB // Restricts the streamed records, if set
+}
+
+// RegisterSSEEndpoint registers GET path streaming the records of a model created after
+// the client connected as server-sent events, one "data: {json}" event per record.
+// Clients reconnecting with a Last-Event-ID header resume after that record. The model
+// must have a single integer primary key, which orders the records.
+func (g *APIGenerator) RegisterSSEEndpoint(modelName, path string, opts SSEOptions) error {
+	modelInfo, exists := g.Models[modelName]
+	if !exists {
+		return fmt.Errorf("model %s is not registered", modelName)
+	}
+	if _, ok := streamKey(modelInfo); !ok {
+		return fmt.Errorf("model %s needs a single integer primary key to be streamed", modelName)
+	}
+
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaultSSEPollInterval
+	}
+	if opts.MaxConnections <= 0 {
+		opts.MaxConnections = defaultSSEConnections
+	}
+	g.handle(modelInfo, VerbStream, http.MethodGet, path, g.sseHandler(modelInfo, opts))
+	return nil
+}
+
+// streamKey returns the integer primary key ordering the streamed records of a model,
+// which may be promoted from an embedded gorm.Model
+func streamKey(modelInfo ModelInfo) (FieldInfo, bool) {
+	key := modelInfo.PrimaryKeyField
+	if key.Type == nil || !isIntegerKind(key.Type.Kind()) {
+		return FieldInfo{}, false
+	}
+	return key, true
+}
+
+// sseHandler returns a handler function streaming the new records of a model
+// @Summary Stream new model instances
+// @Description Stream the instances of a model created after connecting, as server-sent events
+// @Tags API
+// @Produce text/event-stream
+// @Param Last-Event-ID header string false "Resume after the record with this ID"
+// @Success 200 {string} string
+// @Failure 503 {object} map[string]string
+// @Router /api/{model}/stream [get]
+func (g *APIGenerator) sseHandler(modelInfo ModelInfo, opts SSEOptions) gin.HandlerFunc {
+	connections := make(chan struct{}, opts.MaxConnections)
+	keyField, _ := streamKey(modelInfo)
+	column := clause.Column{Table: modelInfo.TableName, Name: g.columnName(keyField)}
+	filter := opts.FilterFunc
+	if filter == nil {
+		filter = func(db *gorm.DB) *gorm.DB { return db }
+	}
+
+	return func(c *gin.Context) {
+		select {
+		case connections <- struct{}{}:
+			defer func() { <-connections }()
+		default:
+			g.respondError(c, http.StatusServiceUnavailable, errors.New("too many event stream connections"))
+			return
+		}
+		ctx := c.Request.Context()
+
+		// Start after the latest record, or the last one the client received
+		var lastSeenID int64
+		if id, err := strconv.ParseInt(c.GetHeader("Last-Event-ID"), 10, 64); err == nil {
+			lastSeenID = id
+		} else if err := g.modelDB(c, modelInfo).Model(reflect.New(modelInfo.Type).Interface()).Scopes(filter).
+			Select("COALESCE(MAX(?), 0)", column).Scan(&lastSeenID).Error; err != nil {
+			g.respondError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		c.Status(http.StatusOK)
+		fmt.Fprint(c.Writer, ": connected\n\n")
+		c.Writer.Flush()
+
+		ticker := time.NewTicker(opts.PollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return // The client disconnected
+			case <-ticker.C:
+			}
+
+			// Send the records created since the last poll, oldest first
+			results := reflect.New(reflect.SliceOf(modelInfo.Type))
+			err := g.modelDB(c, modelInfo).Scopes(filter).
+				Where(clause.Gt{Column: column, Value: lastSeenID}).
+				Order(clause.OrderByColumn{Column: column}).
+				Find(results.Interface()).Error
+			if err != nil {
+				if ctx.Err() == nil {
+					fmt.Fprintf(c.Writer, "event: error\ndata: %q\n\n", err.Error())
+					c.Writer.Flush()
+				}
+				return
+			}
+
+			records := results.Elem()
+			for i := 0; i < records.Len(); i++ {
+				record := records.Index(i)
+				lastSeenID = reflect.Indirect(record.FieldByName(keyField.Name)).Convert(reflect.TypeOf(lastSeenID)).Int()
+				data, err := g.streamedRecord(c, modelInfo, record)
+				if err != nil || data == nil {
+					continue // The record is left out of the stream
+				}
+				fmt.Fprintf(c.Writer, "id: %d\ndata: %s\n\n", lastSeenID, data)
+			}
+			if records.Len() > 0 {
+				c.Writer.Flush()
+			}
+		}
+	}
+}
+
+// streamedRecord serialises a streamed record like the list endpoint does, or returns
+// nil when the model's TransformResponse drops it
+func (g *APIGenerator) streamedRecord(c *gin.Context, modelInfo ModelInfo, record reflect.Value) ([]byte, error) {
+	records := reflect.Append(reflect.MakeSlice(reflect.SliceOf(modelInfo.Type), 0, 1), record)
+	computed, err := g.computeFields(c, modelInfo, records.Interface())
+	if err != nil {
+		return nil, err
+	}
+	logDeprecatedFields(modelInfo, records.Interface(), nil)
+
+	body, err := g.responseBody(c, modelInfo, records.Interface(), computed, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	items, ok := body.([]map[string]any)
+	if !ok {
+		return json.Marshal(record.Interface())
+	}
+	if len(items) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(items[0])
+}
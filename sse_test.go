@@ -0,0 +1,60 @@
+package apigen
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestSSEEndpoint(t *testing.T) {
+	g, router := newTestAPI(t, func(g *APIGenerator) {
+		g.RegisterModelWithOptions(&testUser{},
+			WithComputedFields(ComputedField{
+				JSONName: "greeting",
+				Compute: func(ctx context.Context, db *gorm.DB, instance any) (any, error) {
+					return "Hello " + instance.(*testUser).Name, nil
+				},
+			}),
+			WithTransformResponse(func(ctx context.Context, modelName string, instances []map[string]any) ([]map[string]any, error) {
+				for _, instance := range instances {
+					delete(instance, "email")
+				}
+				return instances, nil
+			}),
+		)
+		if err := g.RegisterSSEEndpoint("testUser", "/api/test_users/stream", SSEOptions{PollInterval: 10 * time.Millisecond}); err != nil {
+			t.Fatalf("register SSE endpoint: %v", err)
+		}
+	}, &testUser{})
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/test_users/stream", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer resp.Body.Close()
+
+	g.DB.Create(&testUser{Name: "Alice", Email: "alice@example.com"})
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		if want := `{"greeting":"Hello Alice","id":1,"name":"Alice"}`; data != want {
+			t.Errorf("event: got %s, want %s", data, want)
+		}
+		return
+	}
+	t.Fatalf("no event received: %v", scanner.Err())
+}
@@ -1,47 +1,108 @@
 package apigen
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
 	"reflect"
+	"slices"
 	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
 )
 
+// SwaggerInfo describes the API in the generated Swagger document
+type SwaggerInfo struct {
+	Title       string
+	Version     string
+	Description string
+	Host        string
+	BasePath    string
+}
+
 // SwaggerGenerator generates Swagger documentation for the API
 type SwaggerGenerator struct {
-	Models map[string]ModelInfo
-	paths  map[string]any // internal storage for Swagger paths
+	Models   map[string]ModelInfo
+	Envelope *Envelope // Response envelope the schemas are wrapped in, if any
+
+	// TotalCountHeader documents the X-Total-Count header of the list responses
+	TotalCountHeader bool
+
+	// MaxDepth caps the nesting of the structs expanded inline in a schema, deeper
+	// structs are documented as plain objects. It is 3 by default; see WithMaxDepth.
+	MaxDepth int
+
+	// CircularRefDetected is set when a struct was found to contain itself. Its schema
+	// is then given a definition of its own, which it refers to.
+	CircularRefDetected bool
+
+	paths    map[string]any          // internal storage for Swagger paths
+	circular map[string]reflect.Type // Structs containing themselves, by name
+}
+
+// defaultSwaggerMaxDepth is the MaxDepth of a SwaggerGenerator when it is unset
+const defaultSwaggerMaxDepth = 3
+
+// SwaggerOption configures a SwaggerGenerator
+type SwaggerOption func(*SwaggerGenerator)
+
+// WithMaxDepth caps the nesting of the structs expanded inline in a schema at n
+func WithMaxDepth(n int) SwaggerOption {
+	return func(g *SwaggerGenerator) {
+		g.MaxDepth = n
+	}
 }
 
 // NewSwaggerGenerator creates a new SwaggerGenerator
-func NewSwaggerGenerator(models map[string]ModelInfo) *SwaggerGenerator {
-	return &SwaggerGenerator{
-		Models: models,
-		paths:  make(map[string]any),
+func NewSwaggerGenerator(models map[string]ModelInfo, opts ...SwaggerOption) *SwaggerGenerator {
+	g := &SwaggerGenerator{
+		Models:   models,
+		MaxDepth: defaultSwaggerMaxDepth,
+		paths:    make(map[string]any),
+	}
+	for _, opt := range opts {
+		opt(g)
 	}
+	return g
 }
 
 // BuildPathsForAllModels builds the Swagger paths for all CRUD endpoints (internal use)
 func (g *SwaggerGenerator) BuildPathsForAllModels() {
 	paths := make(map[string]any)
+
+	// addPath stores a path item without the operations of disabled verbs
+	addPath := func(modelInfo ModelInfo, path string, item map[string]any) {
+		for verb := range item {
+			if !modelInfo.verbEnabled(verb) {
+				delete(item, verb)
+			}
+		}
+		if len(item) > 0 {
+			paths[path] = item
+		}
+	}
+
 	for _, modelInfo := range g.Models {
 		plural := modelInfo.PluralName
 		modelName := modelInfo.Type.Name()
 		// List endpoint
-		paths["/api/"+plural] = map[string]any{
+		addPath(modelInfo, "/api/"+plural, map[string]any{
 			"get": map[string]any{
-				"summary":     "List all " + plural,
+				"summary":    "List all " + plural,
+				"parameters": append(append(listQueryParameters(), preloadParameter(), fieldsParameter(), rangeParameter()), includeDeletedParameter(modelInfo)...),
 				"responses": map[string]any{
-					"200": map[string]any{
-						"description": "List response",
-						"schema": map[string]any{
-							"type":  "array",
-							"items": map[string]any{"$ref": "#/definitions/" + modelName},
-						},
-					},
+					"200": g.listResponse(modelName),
+					"206": rangeResponse(modelName),
+					"416": map[string]any{"description": "Range starts past the matching records"},
 				},
 			},
 			"post": map[string]any{
-				"summary":     "Create a new " + modelInfo.ResourceName,
+				"summary": "Create a new " + modelInfo.ResourceName,
 				"parameters": []map[string]any{
 					{
 						"in":          "body",
@@ -58,74 +119,680 @@ func (g *SwaggerGenerator) BuildPathsForAllModels() {
 					},
 				},
 			},
-		}
-		// Single instance endpoints
-		paths["/api/"+plural+"/{id}"] = map[string]any{
-			"get": map[string]any{
-				"summary":     "Get a " + modelInfo.ResourceName,
+			"put": map[string]any{
+				"summary": "Create or update a " + modelInfo.ResourceName,
 				"parameters": []map[string]any{
-					{"name": "id", "in": "path", "required": true, "type": "string"},
+					{
+						"in":          "body",
+						"name":        modelInfo.ResourceName,
+						"description": "Upsert request",
+						"required":    true,
+						"schema":      g.GenerateRequestBody(modelInfo, true),
+					},
 				},
 				"responses": map[string]any{
 					"200": map[string]any{
-						"description": "Success",
+						"description": "Updated",
+						"schema":      g.GenerateResponseBody(modelInfo),
+					},
+					"201": map[string]any{
+						"description": "Created",
 						"schema":      g.GenerateResponseBody(modelInfo),
 					},
-					"404": map[string]any{"description": "Not found"},
 				},
 			},
-			"put": map[string]any{
-				"summary":     "Update a " + modelInfo.ResourceName,
+		})
+		// Search endpoint
+		addPath(modelInfo, "/api/"+plural+"/search", map[string]any{
+			"get": map[string]any{
+				"summary": "Search " + plural,
+				"parameters": append([]map[string]any{
+					{"name": "q", "in": "query", "required": true, "type": "string"},
+				}, listQueryParameters()...),
+				"responses": map[string]any{
+					"200": g.listResponse(modelName),
+					"400": map[string]any{"description": "Invalid search term"},
+				},
+			},
+		})
+		// Query endpoint, a read even though it is a POST
+		if modelInfo.verbEnabled(http.MethodGet) {
+			paths["/api/"+plural+"/query"] = map[string]any{
+				"post": map[string]any{
+					"summary": "Query " + plural + " with nested filters",
+					"parameters": []map[string]any{
+						{
+							"in":          "body",
+							"name":        "query",
+							"description": "Filter tree, sort and page",
+							"required":    true,
+							"schema":      queryRequestSchema(),
+						},
+						preloadParameter(),
+						fieldsParameter(),
+					},
+					"responses": map[string]any{
+						"200": g.listResponse(modelName),
+						"400": map[string]any{"description": "Invalid query"},
+					},
+				},
+			}
+		}
+		// Count endpoint
+		if !modelInfo.DisableCount {
+			addPath(modelInfo, "/api/"+plural+"/count", map[string]any{
+				"get": map[string]any{
+					"summary": "Count " + plural,
+					"responses": map[string]any{
+						"200": map[string]any{
+							"description": "Count response",
+							"schema": map[string]any{
+								"type": "object",
+								"properties": map[string]any{
+									"count": map[string]any{"type": "integer", "format": "int64"},
+								},
+							},
+						},
+						"400": map[string]any{"description": "Invalid filter"},
+					},
+				},
+			})
+		}
+		// Bulk endpoints
+		bulk := map[string]any{}
+		if modelInfo.EnableBulkDelete && !modelInfo.hasCompositePrimaryKey() {
+			bulk["delete"] = map[string]any{
+				"summary": "Delete several " + plural,
 				"parameters": []map[string]any{
-					{"name": "id", "in": "path", "required": true, "type": "string"},
 					{
 						"in":          "body",
-						"name":        modelInfo.ResourceName,
-						"description": "Update request",
+						"name":        "ids",
+						"description": "IDs to delete",
 						"required":    true,
-						"schema":      g.GenerateRequestBody(modelInfo, false),
+						"schema":      g.bulkIDsSchema(modelInfo),
+					},
+				},
+				"responses": map[string]any{
+					"200": map[string]any{
+						"description": "Deleted",
+						"schema": map[string]any{
+							"type": "object",
+							"properties": map[string]any{
+								"deleted":   map[string]any{"type": "integer", "format": "int64"},
+								"not_found": map[string]any{"type": "array", "items": g.bulkIDType(modelInfo)},
+							},
+						},
+					},
+					"400": map[string]any{"description": "Invalid IDs"},
+				},
+			}
+		}
+		if modelInfo.EnableBulkPatch && !modelInfo.hasCompositePrimaryKey() {
+			bulk["patch"] = map[string]any{
+				"summary": "Patch several " + plural,
+				"parameters": []map[string]any{
+					{
+						"in":          "body",
+						"name":        "body",
+						"description": "IDs to update and the fields to set",
+						"required":    true,
+						"schema":      g.bulkPatchSchema(modelInfo),
 					},
 				},
 				"responses": map[string]any{
 					"200": map[string]any{
 						"description": "Updated",
+						"schema": map[string]any{
+							"type": "object",
+							"properties": map[string]any{
+								"updated": map[string]any{"type": "integer", "format": "int64"},
+							},
+						},
+					},
+					"400": map[string]any{"description": "Invalid IDs or patch"},
+				},
+			}
+		}
+		addPath(modelInfo, "/api/"+plural+"/bulk", bulk)
+		// CSV import endpoint
+		if modelInfo.EnableCSVImport {
+			addPath(modelInfo, "/api/"+plural+"/import", map[string]any{
+				"post": map[string]any{
+					"summary":  "Import " + plural + " from a CSV file",
+					"consumes": []string{"multipart/form-data"},
+					"parameters": []map[string]any{
+						{"name": "file", "in": "formData", "required": true, "type": "file", "description": "CSV file whose header row names the fields"},
+					},
+					"responses": map[string]any{
+						"200": map[string]any{
+							"description": "Imported",
+							"schema": map[string]any{
+								"type": "object",
+								"properties": map[string]any{
+									"imported": map[string]any{"type": "integer"},
+									"errors": map[string]any{
+										"type": "array",
+										"items": map[string]any{
+											"type": "object",
+											"properties": map[string]any{
+												"row":   map[string]any{"type": "integer"},
+												"error": map[string]any{"type": "string"},
+											},
+										},
+									},
+								},
+							},
+						},
+						"400": map[string]any{"description": "Missing or unreadable CSV file"},
+						"413": map[string]any{"description": "File larger than MaxBodySize"},
+					},
+				},
+			})
+		}
+		// Single instance endpoints
+		addPath(modelInfo, "/api/"+plural+instanceSwaggerPath(modelInfo), map[string]any{
+			"get": map[string]any{
+				"summary":    "Get a " + modelInfo.ResourceName,
+				"parameters": append(append(g.pathKeyParameters(modelInfo), preloadParameter(), fieldsParameter()), includeDeletedParameter(modelInfo)...),
+				"responses": map[string]any{
+					"200": map[string]any{
+						"description": "Success",
 						"schema":      g.GenerateResponseBody(modelInfo),
 					},
 					"404": map[string]any{"description": "Not found"},
 				},
 			},
-			"delete": map[string]any{
-				"summary":     "Delete a " + modelInfo.ResourceName,
-				"parameters": []map[string]any{
-					{"name": "id", "in": "path", "required": true, "type": "string"},
+			"put": map[string]any{
+				"summary": "Update a " + modelInfo.ResourceName,
+				"parameters": append(g.pathKeyParameters(modelInfo), map[string]any{
+					"in":          "body",
+					"name":        modelInfo.ResourceName,
+					"description": "Update request",
+					"required":    true,
+					"schema":      g.GenerateRequestBody(modelInfo, false),
+				}),
+				"responses": map[string]any{
+					"200": map[string]any{
+						"description": "Updated",
+						"schema":      g.GenerateResponseBody(modelInfo),
+					},
+					"404": map[string]any{"description": "Not found"},
+					"409": map[string]any{"description": "Version conflict or ID mismatch"},
+				},
+			},
+			"patch": map[string]any{
+				"summary":     "Patch a " + modelInfo.ResourceName,
+				"description": "Apply a JSON merge patch (RFC 7396): null resets a field, absent fields are left unchanged",
+				"consumes":    []string{mergePatchContentType, "application/json"},
+				"parameters": append(g.pathKeyParameters(modelInfo), map[string]any{
+					"in":          "body",
+					"name":        "patch",
+					"description": "Merge patch",
+					"required":    true,
+					"schema":      g.GenerateRequestBody(modelInfo, false),
+				}),
+				"responses": map[string]any{
+					"200": map[string]any{
+						"description": "Updated",
+						"schema":      g.GenerateResponseBody(modelInfo),
+					},
+					"404": map[string]any{"description": "Not found"},
+					"409": map[string]any{"description": "Version conflict or ID mismatch"},
+					"415": map[string]any{"description": "Not a merge patch"},
 				},
+			},
+			"delete": map[string]any{
+				"summary":    "Delete a " + modelInfo.ResourceName,
+				"parameters": g.pathKeyParameters(modelInfo),
 				"responses": map[string]any{
 					"204": map[string]any{"description": "Deleted"},
 					"404": map[string]any{"description": "Not found"},
 				},
 			},
+		})
+		// Soft delete endpoints, enabled independently of the CRUD verbs
+		if modelInfo.EnableHardDelete && modelInfo.verbEnabled(http.MethodDelete) {
+			paths["/api/"+plural+instanceSwaggerPath(modelInfo)+"/destroy"] = map[string]any{
+				"delete": map[string]any{
+					"summary":    "Permanently delete a " + modelInfo.ResourceName,
+					"parameters": g.pathKeyParameters(modelInfo),
+					"responses": map[string]any{
+						"204": map[string]any{"description": "Deleted"},
+						"404": map[string]any{"description": "Not found"},
+					},
+				},
+			}
+		}
+		if modelInfo.SoftDelete && modelInfo.EnableRestore {
+			paths["/api/"+plural+instanceSwaggerPath(modelInfo)+"/restore"] = map[string]any{
+				"post": map[string]any{
+					"summary":    "Restore a deleted " + modelInfo.ResourceName,
+					"parameters": g.pathKeyParameters(modelInfo),
+					"responses": map[string]any{
+						"200": map[string]any{
+							"description": "Restored",
+							"schema":      g.GenerateResponseBody(modelInfo),
+						},
+						"404": map[string]any{"description": "Not found"},
+					},
+				},
+			}
 		}
 		// Foreign key relationships
 		for _, fk := range modelInfo.ForeignKeys {
+			if modelInfo.hasCompositePrimaryKey() {
+				break
+			}
+			if _, registered := g.Models[fk.RelatedModel]; !registered && fk.RelationType == RelationPolymorphic {
+				continue
+			}
+			if fk.RelationType == RelationHasOne {
+				addPath(modelInfo, fmt.Sprintf("/api/%s/{id}/%s", plural, fk.routeName()), g.hasOnePathItem(modelInfo, fk))
+				continue
+			}
 			if fk.RelatedModel != "" {
-				relatedPath := fmt.Sprintf("/api/%s/{id}/%s", plural, toSnakeCase(fk.RelatedModel))
-				paths[relatedPath] = map[string]any{
+				relatedPath := fmt.Sprintf("/api/%s/{id}/%s", plural, fk.routeName())
+				item := map[string]any{
 					"get": map[string]any{
 						"summary": fmt.Sprintf("Get related %s for %s", fk.RelatedModel, modelInfo.ResourceName),
 						"parameters": []map[string]any{
 							{"name": "id", "in": "path", "required": true, "type": "string"},
+							{"name": "page", "in": "query", "required": false, "type": "integer"},
+							{"name": "limit", "in": "query", "required": false, "type": "integer"},
 						},
 						"responses": map[string]any{
 							"200": map[string]any{"description": "List response"},
 						},
 					},
 				}
+				if canCreateRelated(g.Models, modelInfo, fk) {
+					relatedModelInfo := g.Models[fk.RelatedModel]
+					item["post"] = map[string]any{
+						"summary": fmt.Sprintf("Create a %s for %s", relatedModelInfo.ResourceName, modelInfo.ResourceName),
+						"parameters": []map[string]any{
+							{"name": "id", "in": "path", "required": true, "type": "string"},
+							{
+								"in":          "body",
+								"name":        relatedModelInfo.ResourceName,
+								"description": "Create request",
+								"required":    true,
+								"schema":      g.GenerateRequestBody(relatedModelInfo, true),
+							},
+						},
+						"responses": map[string]any{
+							"201": map[string]any{
+								"description": "Created",
+								"schema":      g.GenerateResponseBody(relatedModelInfo),
+							},
+							"404": map[string]any{"description": "Parent not found"},
+						},
+					}
+				}
+				addPath(modelInfo, relatedPath, item)
 			}
 		}
+		// Nested resources, reachable through their parent
+		for _, nested := range modelInfo.NestedResources {
+			childInfo := g.Models[nested.Child]
+			parameters := []map[string]any{
+				{"name": "id", "in": "path", "required": true, "type": "string", "description": "ID of the " + modelInfo.ResourceName},
+				{"name": "child_id", "in": "path", "required": true, "type": "string", "description": "ID of the " + childInfo.ResourceName},
+			}
+			addPath(childInfo, swaggerPath(nestedRoutePath(modelInfo, childInfo)), map[string]any{
+				"get": map[string]any{
+					"summary":    fmt.Sprintf("Get a %s of a %s", childInfo.ResourceName, modelInfo.ResourceName),
+					"parameters": parameters,
+					"responses": map[string]any{
+						"200": map[string]any{
+							"description": "Success",
+							"schema":      g.GenerateResponseBody(childInfo),
+						},
+						"404": map[string]any{"description": "Not found"},
+					},
+				},
+				"put": map[string]any{
+					"summary": fmt.Sprintf("Update a %s of a %s", childInfo.ResourceName, modelInfo.ResourceName),
+					"parameters": append(slices.Clone(parameters), map[string]any{
+						"in":          "body",
+						"name":        childInfo.ResourceName,
+						"description": "Update request",
+						"required":    true,
+						"schema":      g.GenerateRequestBody(childInfo, false),
+					}),
+					"responses": map[string]any{
+						"200": map[string]any{
+							"description": "Updated",
+							"schema":      g.GenerateResponseBody(childInfo),
+						},
+						"404": map[string]any{"description": "Not found"},
+					},
+				},
+				"delete": map[string]any{
+					"summary":    fmt.Sprintf("Delete a %s of a %s", childInfo.ResourceName, modelInfo.ResourceName),
+					"parameters": parameters,
+					"responses": map[string]any{
+						"204": map[string]any{"description": "Deleted"},
+						"404": map[string]any{"description": "Not found"},
+					},
+				},
+			})
+		}
 	}
+	// Custom actions, which may share a path with the generated endpoints
+	for _, modelInfo := range g.Models {
+		for _, action := range modelInfo.Actions {
+			path := swaggerPath("/api/" + modelInfo.PluralName + action.ActionPath)
+			item, ok := paths[path].(map[string]any)
+			if !ok {
+				item = make(map[string]any)
+				paths[path] = item
+			}
+			item[strings.ToLower(action.Verb)] = swaggerAction(modelInfo, action)
+		}
+	}
+
+	if g.Envelope != nil {
+		wrapResponses(paths, *g.Envelope)
+	}
+
 	g.paths = paths
 }
 
+// wrapResponses places the response schemas of all operations inside the envelope
+func wrapResponses(paths map[string]any, envelope Envelope) {
+	for _, item := range paths {
+		for _, operation := range item.(map[string]any) {
+			responses, _ := operation.(map[string]any)["responses"].(map[string]any)
+			for code, response := range responses {
+				response, ok := response.(map[string]any)
+				if !ok {
+					continue
+				}
+
+				if !strings.HasPrefix(code, "2") {
+					response["schema"] = map[string]any{
+						"type": "object",
+						"properties": map[string]any{
+							envelope.StatusKey: map[string]any{"type": "string", "example": "error"},
+							envelope.ErrorKey: map[string]any{
+								"type":       "object",
+								"properties": map[string]any{"message": map[string]any{"type": "string"}},
+							},
+						},
+					}
+					continue
+				}
+
+				if schema, ok := response["schema"]; ok {
+					response["schema"] = map[string]any{
+						"type": "object",
+						"properties": map[string]any{
+							envelope.StatusKey: map[string]any{"type": "string", "example": "success"},
+							envelope.DataKey:   schema,
+							envelope.MetaKey:   map[string]any{"type": "object"},
+						},
+					}
+				}
+			}
+		}
+	}
+}
+
+// listQueryParameters returns the pagination and sorting parameters shared by list endpoints
+func listQueryParameters() []map[string]any {
+	return []map[string]any{
+		{"name": "page", "in": "query", "required": false, "type": "integer"},
+		{"name": "limit", "in": "query", "required": false, "type": "integer"},
+		{"name": "sort", "in": "query", "required": false, "type": "string"},
+	}
+}
+
+// instanceSwaggerPath returns the path suffix addressing a single record, e.g. "/{id}"
+func instanceSwaggerPath(modelInfo ModelInfo) string {
+	if !modelInfo.hasCompositePrimaryKey() {
+		return "/{id}"
+	}
+
+	var path strings.Builder
+	for _, field := range modelInfo.PrimaryKeyFields {
+		path.WriteString("/{" + field.JSONName + "}")
+	}
+	return path.String()
+}
+
+// pathKeyParameters returns the path parameters addressing a single record
+func (g *SwaggerGenerator) pathKeyParameters(modelInfo ModelInfo) []map[string]any {
+	if !modelInfo.hasCompositePrimaryKey() {
+		return []map[string]any{
+			{"name": "id", "in": "path", "required": true, "type": "string"},
+		}
+	}
+
+	parameters := make([]map[string]any, 0, len(modelInfo.PrimaryKeyFields))
+	for _, field := range modelInfo.PrimaryKeyFields {
+		parameter := map[string]any{"name": field.JSONName, "in": "path", "required": true}
+		for key, value := range g.getSwaggerType(field.Type) {
+			parameter[key] = value
+		}
+		parameters = append(parameters, parameter)
+	}
+	return parameters
+}
+
+// bulkIDsSchema returns the schema of a bulk request body listing record IDs
+func (g *SwaggerGenerator) bulkIDsSchema(modelInfo ModelInfo) map[string]any {
+	return map[string]any{
+		"type":     "object",
+		"required": []string{"ids"},
+		"properties": map[string]any{
+			"ids": map[string]any{"type": "array", "items": g.bulkIDType(modelInfo)},
+		},
+	}
+}
+
+// queryRequestSchema returns the schema of the body of a query request. Filters are
+// trees, described rather than spelled out since Swagger 2.0 schemas can't recurse inline.
+func queryRequestSchema() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"filter": map[string]any{
+				"type":        "object",
+				"description": `{"and": [filters]}, {"or": [filters]}, or {"field": "age", "op": "gte", "value": 18}; op is one of eq, ne, gt, gte, lt, lte, like, in`,
+			},
+			"sort": map[string]any{
+				"type": "array",
+				"items": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"field": map[string]any{"type": "string"},
+						"order": map[string]any{"type": "string", "enum": []string{"asc", "desc"}},
+					},
+				},
+			},
+			"page":  map[string]any{"type": "integer", "minimum": 1},
+			"limit": map[string]any{"type": "integer", "minimum": 1, "maximum": maxPageSize},
+		},
+	}
+}
+
+// bulkPatchSchema returns the schema of the body of a bulk patch: the IDs, and the
+// fields to set without the protected ones
+func (g *SwaggerGenerator) bulkPatchSchema(modelInfo ModelInfo) map[string]any {
+	properties := make(map[string]any)
+	for _, field := range modelInfo.Fields {
+		if !modelInfo.patchProtected(field) {
+			properties[field.JSONName] = g.fieldSchema(field)
+		}
+	}
+
+	schema := g.bulkIDsSchema(modelInfo)
+	schema["required"] = []string{"ids", "patch"}
+	schema["properties"].(map[string]any)["patch"] = map[string]any{"type": "object", "properties": properties}
+	return schema
+}
+
+// bulkIDType returns the schema of a model's primary key, assuming the conventional
+// integer ID when it isn't a declared field
+func (g *SwaggerGenerator) bulkIDType(modelInfo ModelInfo) map[string]any {
+	if len(modelInfo.PrimaryKeyFields) == 0 {
+		return map[string]any{"type": "integer", "format": "int64"}
+	}
+	return g.getSwaggerType(modelInfo.PrimaryKeyFields[0].Type)
+}
+
+// preloadParameter returns the parameter selecting the associations loaded with the records
+func preloadParameter() map[string]any {
+	return map[string]any{
+		"name":        "preload",
+		"in":          "query",
+		"required":    false,
+		"type":        "string",
+		"description": "Comma separated associations to load",
+	}
+}
+
+// fieldsParameter returns the parameter selecting the fields included in the response
+func fieldsParameter() map[string]any {
+	return map[string]any{
+		"name":        "fields",
+		"in":          "query",
+		"required":    false,
+		"type":        "string",
+		"description": "Comma separated fields to include in the response, e.g. id,name",
+	}
+}
+
+// listResponse returns the successful response of the list and search endpoints
+func (g *SwaggerGenerator) listResponse(modelName string) map[string]any {
+	response := map[string]any{
+		"description": "List response",
+		"schema": map[string]any{
+			"type":  "array",
+			"items": map[string]any{"$ref": "#/definitions/" + modelName},
+		},
+	}
+	if g.TotalCountHeader {
+		response["headers"] = map[string]any{
+			totalCountHeader: map[string]any{
+				"type":        "integer",
+				"format":      "int64",
+				"description": "Number of records matching the query, across all pages",
+			},
+		}
+	}
+	return response
+}
+
+// hasOnePathItem documents the route reading and replacing the has-one record of a model
+func (g *SwaggerGenerator) hasOnePathItem(modelInfo ModelInfo, fk ForeignKeyInfo) map[string]any {
+	idParameter := map[string]any{"name": "id", "in": "path", "required": true, "type": "string"}
+	response := map[string]any{"description": "Related " + fk.RelatedModel}
+	relatedModelInfo, registered := g.Models[fk.RelatedModel]
+	if registered {
+		response["schema"] = g.GenerateResponseBody(relatedModelInfo)
+	}
+
+	item := map[string]any{
+		"get": map[string]any{
+			"summary":    fmt.Sprintf("Get the %s of a %s", fk.routeName(), modelInfo.ResourceName),
+			"parameters": []map[string]any{idParameter},
+			"responses": map[string]any{
+				"200": response,
+				"404": map[string]any{"description": "Parent or related record not found"},
+			},
+		},
+	}
+	if canReplaceRelated(g.Models, modelInfo, fk) {
+		item["put"] = map[string]any{
+			"summary": fmt.Sprintf("Create or replace the %s of a %s", fk.routeName(), modelInfo.ResourceName),
+			"parameters": []map[string]any{
+				idParameter,
+				{
+					"in":          "body",
+					"name":        relatedModelInfo.ResourceName,
+					"description": "Create or replace request",
+					"required":    true,
+					"schema":      g.GenerateRequestBody(relatedModelInfo, true),
+				},
+			},
+			"responses": map[string]any{
+				"200": map[string]any{"description": "Replaced", "schema": g.GenerateResponseBody(relatedModelInfo)},
+				"201": map[string]any{"description": "Created", "schema": g.GenerateResponseBody(relatedModelInfo)},
+				"404": map[string]any{"description": "Parent not found"},
+			},
+		}
+	}
+	return item
+}
+
+// rangeParameter returns the Range header selecting records by position
+func rangeParameter() map[string]any {
+	return map[string]any{
+		"name":        "Range",
+		"in":          "header",
+		"required":    false,
+		"type":        "string",
+		"description": "Records to return when page and limit are absent, e.g. records=0-24",
+	}
+}
+
+// rangeResponse returns the response to a list request with a Range header
+func rangeResponse(modelName string) map[string]any {
+	return map[string]any{
+		"description": "Records of the requested range",
+		"schema": map[string]any{
+			"type":  "array",
+			"items": map[string]any{"$ref": "#/definitions/" + modelName},
+		},
+		"headers": map[string]any{
+			"Content-Range": map[string]any{
+				"type":        "string",
+				"description": "Records returned and matching, e.g. records 0-24/100",
+			},
+		},
+	}
+}
+
+// includeDeletedParameter returns the parameter including soft deleted records, for
+// models that can be restored
+func includeDeletedParameter(modelInfo ModelInfo) []map[string]any {
+	if !modelInfo.SoftDelete || !modelInfo.EnableRestore {
+		return nil
+	}
+	return []map[string]any{{
+		"name":        "include_deleted",
+		"in":          "query",
+		"required":    false,
+		"type":        "boolean",
+		"description": "Include soft deleted records",
+	}}
+}
+
+// GenerateDocument builds the complete Swagger 2.0 document of all models
+func (g *SwaggerGenerator) GenerateDocument(info SwaggerInfo) map[string]any {
+	g.BuildPathsForAllModels()
+
+	document := map[string]any{
+		"swagger": "2.0",
+		"info": map[string]any{
+			"title":       info.Title,
+			"version":     info.Version,
+			"description": info.Description,
+		},
+		"paths":       g.GenerateAllPaths(),
+		"definitions": g.GenerateModelDefinitions(),
+	}
+	if info.Host != "" {
+		document["host"] = info.Host
+	}
+	if info.BasePath != "" {
+		document["basePath"] = info.BasePath
+	}
+
+	return document
+}
+
 // GenerateAllPaths returns the internally built paths map
 func (g *SwaggerGenerator) GenerateAllPaths() map[string]any {
 	return g.paths
@@ -139,12 +806,24 @@ func (g *SwaggerGenerator) GenerateModelDefinitions() map[string]any {
 		definitions[modelName] = g.generateModelDefinition(modelInfo)
 	}
 
+	// Structs containing themselves are defined once and referred to, which may find
+	// more of them
+	for found := true; found; {
+		found = false
+		for name, t := range g.circular {
+			if _, defined := definitions[name]; !defined {
+				definitions[name] = g.structSchema(t, []reflect.Type{t})
+				found = true
+			}
+		}
+	}
+
 	return definitions
 }
 
 // generateModelDefinition generates a Swagger model definition for a specific model
 func (g *SwaggerGenerator) generateModelDefinition(modelInfo ModelInfo) map[string]any {
-	properties := make(map[string]any)
+	properties := &OrderedProperties{}
 	required := []string{}
 
 	for _, field := range modelInfo.Fields {
@@ -154,13 +833,17 @@ func (g *SwaggerGenerator) generateModelDefinition(modelInfo ModelInfo) map[stri
 		}
 
 		// Add the field to the properties
-		properties[field.JSONName] = g.getSwaggerType(field.Type)
+		properties.Set(field.JSONName, g.fieldSchema(field))
 
 		// Add required fields
 		if !field.OmitEmpty {
 			required = append(required, field.JSONName)
 		}
 	}
+	addComputedProperties(properties, modelInfo)
+	addLinksProperty(properties, modelInfo)
+	markDeprecated(properties, modelInfo)
+	properties.reorder(modelInfo.OrderedFields)
 
 	definition := map[string]any{
 		"type":       "object",
@@ -176,17 +859,17 @@ func (g *SwaggerGenerator) generateModelDefinition(modelInfo ModelInfo) map[stri
 
 // GenerateRequestBody generates a Swagger request body for a model
 func (g *SwaggerGenerator) GenerateRequestBody(modelInfo ModelInfo, isCreate bool) map[string]any {
-	properties := make(map[string]any)
+	properties := &OrderedProperties{}
 	required := []string{}
 
 	for _, field := range modelInfo.Fields {
-		// Skip fields that should be omitted or ID fields for create requests
-		if field.JSONName == "-" || (isCreate && field.IsID) {
+		// Skip fields that should be omitted, read-only fields, or ID fields for create requests
+		if field.JSONName == "-" || modelInfo.isReadOnly(field) || (isCreate && field.IsID) {
 			continue
 		}
 
 		// Add the field to the properties
-		properties[field.JSONName] = g.getSwaggerType(field.Type)
+		properties.Set(field.JSONName, g.fieldSchema(field))
 
 		// Add required fields
 		if !field.OmitEmpty {
@@ -194,6 +877,17 @@ func (g *SwaggerGenerator) GenerateRequestBody(modelInfo ModelInfo, isCreate boo
 		}
 	}
 
+	// Updates of versioned models must state the version they are based on
+	if !isCreate && modelInfo.LockVersion {
+		for _, field := range modelInfo.Fields {
+			if field.Name == versionFieldName && !slices.Contains(required, field.JSONName) {
+				required = append(required, field.JSONName)
+			}
+		}
+	}
+	markDeprecated(properties, modelInfo)
+	properties.reorder(modelInfo.OrderedFields)
+
 	definition := map[string]any{
 		"type":       "object",
 		"properties": properties,
@@ -208,7 +902,7 @@ func (g *SwaggerGenerator) GenerateRequestBody(modelInfo ModelInfo, isCreate boo
 
 // GenerateResponseBody generates a Swagger response body for a model
 func (g *SwaggerGenerator) GenerateResponseBody(modelInfo ModelInfo) map[string]any {
-	properties := make(map[string]any)
+	properties := &OrderedProperties{}
 
 	for _, field := range modelInfo.Fields {
 		// Skip fields that should be omitted
@@ -217,8 +911,16 @@ func (g *SwaggerGenerator) GenerateResponseBody(modelInfo ModelInfo) map[string]
 		}
 
 		// Add the field to the properties
-		properties[field.JSONName] = g.getSwaggerType(field.Type)
+		schema := g.fieldSchema(field)
+		if _, ref := schema["$ref"]; !ref && modelInfo.isReadOnly(field) {
+			schema["readOnly"] = true
+		}
+		properties.Set(field.JSONName, schema)
 	}
+	addComputedProperties(properties, modelInfo)
+	addLinksProperty(properties, modelInfo)
+	markDeprecated(properties, modelInfo)
+	properties.reorder(modelInfo.OrderedFields)
 
 	return map[string]any{
 		"type":       "object",
@@ -226,8 +928,105 @@ func (g *SwaggerGenerator) GenerateResponseBody(modelInfo ModelInfo) map[string]
 	}
 }
 
+// addComputedProperties adds the computed fields of a model as read-only properties
+// of unknown type. Request bodies never include them.
+func addComputedProperties(properties *OrderedProperties, modelInfo ModelInfo) {
+	for _, field := range modelInfo.ComputedFields {
+		properties.Set(field.JSONName, map[string]any{"readOnly": true})
+	}
+}
+
+// OrderedProperties holds the properties of a schema, serialised in the order they were
+// set rather than sorted like the keys of a map, so the document follows the models
+type OrderedProperties struct {
+	keys   []string
+	values map[string]any
+}
+
+// Set adds a property, or replaces it keeping its position
+func (p *OrderedProperties) Set(name string, schema any) {
+	if p.values == nil {
+		p.values = make(map[string]any)
+	}
+	if _, exists := p.values[name]; !exists {
+		p.keys = append(p.keys, name)
+	}
+	p.values[name] = schema
+}
+
+// Get returns the schema of a property
+func (p *OrderedProperties) Get(name string) (any, bool) {
+	schema, ok := p.values[name]
+	return schema, ok
+}
+
+// Keys returns the names of the properties in order
+func (p *OrderedProperties) Keys() []string {
+	return slices.Clone(p.keys)
+}
+
+// reorder moves the given properties first, in that order, keeping the others after them
+func (p *OrderedProperties) reorder(first []string) {
+	if len(first) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(p.keys))
+	for _, name := range first {
+		if _, exists := p.values[name]; exists && !slices.Contains(keys, name) {
+			keys = append(keys, name)
+		}
+	}
+	for _, name := range p.keys {
+		if !slices.Contains(keys, name) {
+			keys = append(keys, name)
+		}
+	}
+	p.keys = keys
+}
+
+// MarshalJSON encodes the properties as a JSON object in order
+func (p *OrderedProperties) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, name := range p.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(p.values[name])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// fieldSchema returns the Swagger schema of a model field, marking pointer fields as nullable
+func (g *SwaggerGenerator) fieldSchema(field FieldInfo) map[string]any {
+	schema := g.getSwaggerType(field.Type)
+	if _, ref := schema["$ref"]; !ref {
+		if field.Nullable {
+			schema["x-nullable"] = true
+		}
+		addBindingConstraints(schema, field)
+	}
+	return schema
+}
+
 // getSwaggerType converts a Go type to a Swagger type
 func (g *SwaggerGenerator) getSwaggerType(t reflect.Type) map[string]any {
+	return g.swaggerType(t, nil)
+}
+
+// swaggerType converts a Go type nested in the inline structs of path to a Swagger type
+func (g *SwaggerGenerator) swaggerType(t reflect.Type, path []reflect.Type) map[string]any {
 	switch t.Kind() {
 	case reflect.Bool:
 		return map[string]any{
@@ -263,36 +1062,58 @@ func (g *SwaggerGenerator) getSwaggerType(t reflect.Type) map[string]any {
 				"format": "date-time",
 			}
 		}
-
-		// For other structs, create an inline definition
-		properties := make(map[string]any)
-		for i := 0; i < t.NumField(); i++ {
-			field := t.Field(i)
-			jsonTag := field.Tag.Get("json")
-			if jsonTag == "" || jsonTag == "-" {
-				continue
+		// gorm.DeletedAt is written as a timestamp, or null until the record is deleted
+		if t == deletedAtType {
+			return map[string]any{
+				"type":       "string",
+				"format":     "date-time",
+				"x-nullable": true,
 			}
-
-			jsonName := strings.Split(jsonTag, ",")[0]
-			properties[jsonName] = g.getSwaggerType(field.Type)
 		}
 
-		return map[string]any{
-			"type":       "object",
-			"properties": properties,
+		// A struct containing itself refers to its own definition
+		if slices.Contains(path, t) {
+			g.CircularRefDetected = true
+			if g.circular == nil {
+				g.circular = make(map[string]reflect.Type)
+			}
+			g.circular[t.Name()] = t
+			return map[string]any{
+				"$ref": fmt.Sprintf("#/definitions/%s", t.Name()),
+			}
+		}
+		if len(path) >= g.maxDepth() {
+			return map[string]any{
+				"type": "object",
+			}
 		}
+
+		// For other structs, create an inline definition
+		return g.structSchema(t, append(path, t))
 	case reflect.Slice, reflect.Array:
+		if t == uuidType {
+			return map[string]any{
+				"type":   "string",
+				"format": "uuid",
+			}
+		}
 		return map[string]any{
 			"type":  "array",
-			"items": g.getSwaggerType(t.Elem()),
+			"items": g.swaggerType(t.Elem(), path),
 		}
 	case reflect.Map:
+		// Arbitrary JSON objects have no schema for their values
+		if isJSONObject(t) {
+			return map[string]any{
+				"type": "object",
+			}
+		}
 		return map[string]any{
 			"type":                 "object",
-			"additionalProperties": g.getSwaggerType(t.Elem()),
+			"additionalProperties": g.swaggerType(t.Elem(), path),
 		}
 	case reflect.Ptr:
-		return g.getSwaggerType(t.Elem())
+		return g.swaggerType(t.Elem(), path)
 	default:
 		return map[string]any{
 			"type": "string",
@@ -300,6 +1121,34 @@ func (g *SwaggerGenerator) getSwaggerType(t reflect.Type) map[string]any {
 	}
 }
 
+// structSchema returns the inline schema of a struct at the end of path
+func (g *SwaggerGenerator) structSchema(t reflect.Type, path []reflect.Type) map[string]any {
+	properties := &OrderedProperties{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		jsonTag := field.Tag.Get("json")
+		if jsonTag == "" || jsonTag == "-" {
+			continue
+		}
+
+		jsonName := strings.Split(jsonTag, ",")[0]
+		properties.Set(jsonName, g.swaggerType(field.Type, path))
+	}
+
+	return map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+}
+
+// maxDepth returns the nesting limit of inline structs
+func (g *SwaggerGenerator) maxDepth() int {
+	if g.MaxDepth <= 0 {
+		return defaultSwaggerMaxDepth
+	}
+	return g.MaxDepth
+}
+
 // getIntegerFormat returns the Swagger format for an integer type
 func (g *SwaggerGenerator) getIntegerFormat(t reflect.Type) string {
 	switch t.Kind() {
@@ -319,3 +1168,79 @@ func (g *SwaggerGenerator) getFloatFormat(t reflect.Type) string {
 		return "float"
 	}
 }
+
+// ServeSwagger serves the Swagger document of the registered models at path. The
+// document is built once, and again by GenerateAPI and by actions registered later on,
+// rather than on each request. Serving the same path twice is a no-op.
+func (g *APIGenerator) ServeSwagger(path string) {
+	if g.RegisteredPaths[path] {
+		return
+	}
+	g.RegisteredPaths[path] = true
+
+	if g.swaggerJSON.Load() == nil {
+		if err := g.buildSwaggerDocument(); err != nil {
+			log.Error().Err(err).Msg("apigen: failed to build swagger document")
+		}
+	}
+	g.Group.GET(path, func(c *gin.Context) {
+		document, _ := g.swaggerJSON.Load().([]byte)
+		if document == nil {
+			g.respondError(c, http.StatusInternalServerError, errors.New("Swagger document is unavailable"))
+			return
+		}
+		c.Data(http.StatusOK, "application/json; charset=utf-8", document)
+	})
+}
+
+// buildSwaggerDocument renders the Swagger document of the registered models for
+// ServeSwagger. Requests keep getting the previous document while it is built.
+func (g *APIGenerator) buildSwaggerDocument() error {
+	data, err := json.Marshal(g.swaggerGenerator().GenerateDocument(g.SwaggerInfo))
+	if err != nil {
+		return err
+	}
+	g.swaggerJSON.Store(data)
+	return nil
+}
+
+// swaggerFileMode lets other users and processes, e.g. a web server, read the Swagger file
+const swaggerFileMode = 0o644
+
+// writeSwaggerFile writes the Swagger document of the registered models to path.
+// The document is written to a temporary file first so readers never see a partial file.
+func (g *APIGenerator) writeSwaggerFile(path string) error {
+	document := g.swaggerGenerator().GenerateDocument(g.SwaggerInfo)
+	data, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
+
+	// CreateTemp makes the file private, but the document is meant to be served
+	if err := tmp.Chmod(swaggerFileMode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// swaggerGenerator returns a SwaggerGenerator for the registered models
+func (g *APIGenerator) swaggerGenerator() *SwaggerGenerator {
+	swaggerGen := NewSwaggerGenerator(g.Models)
+	swaggerGen.Envelope = g.envelope
+	swaggerGen.TotalCountHeader = g.TotalCountHeader
+	return swaggerGen
+}
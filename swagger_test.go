@@ -0,0 +1,201 @@
+package apigen
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSwaggerFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "swagger.json")
+	newTestAPI(t, func(g *APIGenerator) {
+		g.SwaggerFilePath = path
+	}, &testUser{})
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != swaggerFileMode {
+		t.Errorf("swagger file mode: got %o, want %o", mode, swaggerFileMode)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var document map[string]any
+	if err := json.Unmarshal(data, &document); err != nil {
+		t.Fatalf("swagger file is not JSON: %v", err)
+	}
+	if _, ok := document["paths"].(map[string]any)["/api/test_users"]; !ok {
+		t.Errorf("swagger file misses /api/test_users: %v", document["paths"])
+	}
+}
+
+func TestSwaggerFileError(t *testing.T) {
+	g := New(newTestDB(t, &testUser{}), gin.New())
+	g.SwaggerFilePath = filepath.Join(t.TempDir(), "missing", "swagger.json")
+	if err := g.RegisterModelWithOptions(&testUser{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.GenerateAPI("Test API", "1.0.0"); err == nil {
+		t.Error("GenerateAPI succeeded writing to a missing directory")
+	}
+}
+
+func TestServeSwaggerBuiltOnce(t *testing.T) {
+	g, router := newTestAPI(t, nil, &testUser{})
+
+	w := serve(router, http.MethodGet, "/swagger.json", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("swagger.json: got %d", w.Code)
+	}
+	served := w.Body.String()
+
+	// Models registered after GenerateAPI don't change the served document...
+	if err := g.RegisterModelWithOptions(&testSetting{}); err != nil {
+		t.Fatal(err)
+	}
+	if w := serve(router, http.MethodGet, "/swagger.json", ""); w.Body.String() != served {
+		t.Error("the swagger document was rebuilt for a request")
+	}
+
+	// ...but actions registered later on are documented
+	if err := g.RegisterAction("testUser", http.MethodPost, "/:id/activate", func(c *gin.Context) {}); err != nil {
+		t.Fatal(err)
+	}
+	document := decode[map[string]any](t, serve(router, http.MethodGet, "/swagger.json", ""))
+	if _, ok := document["paths"].(map[string]any)["/api/test_users/{id}/activate"]; !ok {
+		t.Error("the action registered after GenerateAPI is not documented")
+	}
+}
+
+// testEvent stores free-form JSON metadata
+type testEvent struct {
+	ID       uint              `json:"id" gorm:"primaryKey"`
+	Metadata map[string]any    `json:"metadata" gorm:"serializer:json"`
+	Labels   map[string]string `json:"labels" gorm:"serializer:json"`
+}
+
+func TestJSONObjectFields(t *testing.T) {
+	g, router := newTestAPI(t, nil, &testEvent{})
+	modelInfo := g.Models["testEvent"]
+
+	swagger := NewSwaggerGenerator(g.Models)
+	metadata, _ := modelInfo.fieldByJSONName("metadata")
+	if schema := swagger.getSwaggerType(metadata.Type); len(schema) != 1 || schema["type"] != "object" {
+		t.Errorf("metadata schema: got %v", schema)
+	}
+	labels, _ := modelInfo.fieldByJSONName("labels")
+	if schema := swagger.getSwaggerType(labels.Type); schema["additionalProperties"] == nil {
+		t.Errorf("labels schema: got %v", schema)
+	}
+
+	code, err := NewModelAnalyzer().GenerateRequestStruct(modelInfo, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(code, "Metadata map[string]any `json:\"metadata\"`") || !strings.Contains(code, "Labels map[string]string") {
+		t.Errorf("request struct:\n%s", code)
+	}
+
+	if w := serve(router, http.MethodPost, "/api/test_events", `{"metadata":{"source":"web","retries":2,"tags":["a"]}}`); w.Code != http.StatusCreated {
+		t.Fatalf("create: got %d %s", w.Code, w.Body)
+	}
+	event := decode[testEvent](t, serve(router, http.MethodGet, "/api/test_events/1", ""))
+	if event.Metadata["source"] != "web" || event.Metadata["retries"] != float64(2) {
+		t.Errorf("get: got %+v", event)
+	}
+}
+
+// testTreeNode contains itself, through its children
+type testTreeNode struct {
+	Name     string         `json:"name"`
+	Children []testTreeNode `json:"children"`
+}
+
+// testOuter nests testInner one level down
+type testOuter struct {
+	Inner testInner `json:"inner"`
+}
+
+type testInner struct {
+	Name string `json:"name"`
+}
+
+func TestSwaggerCircularStructs(t *testing.T) {
+	swagger := NewSwaggerGenerator(nil)
+	schema := swagger.getSwaggerType(reflect.TypeOf(testTreeNode{}))
+	children, _ := schema["properties"].(*OrderedProperties).Get("children")
+	if ref := children.(map[string]any)["items"].(map[string]any)["$ref"]; ref != "#/definitions/testTreeNode" {
+		t.Errorf("children: got %v", children)
+	}
+	if !swagger.CircularRefDetected {
+		t.Error("CircularRefDetected is not set")
+	}
+	if _, ok := swagger.GenerateModelDefinitions()["testTreeNode"]; !ok {
+		t.Error("the circular struct has no definition")
+	}
+
+	swagger = NewSwaggerGenerator(nil)
+	swagger.getSwaggerType(reflect.TypeOf(testOuter{}))
+	if swagger.CircularRefDetected {
+		t.Error("CircularRefDetected is set for a struct without cycles")
+	}
+}
+
+func TestSwaggerMaxDepth(t *testing.T) {
+	schema := NewSwaggerGenerator(nil).getSwaggerType(reflect.TypeOf(testOuter{}))
+	inner, _ := schema["properties"].(*OrderedProperties).Get("inner")
+	if inner.(map[string]any)["properties"] == nil {
+		t.Errorf("default depth: got %v", inner)
+	}
+
+	schema = NewSwaggerGenerator(nil, WithMaxDepth(1)).getSwaggerType(reflect.TypeOf(testOuter{}))
+	inner, _ = schema["properties"].(*OrderedProperties).Get("inner")
+	if inner := inner.(map[string]any); len(inner) != 1 || inner["type"] != "object" {
+		t.Errorf("depth 1: got %v", inner)
+	}
+}
+
+func TestOrderedFields(t *testing.T) {
+	g := New(nil, nil)
+	g.RegisterModelWithOptions(&testTicket{}, WithOrderedFields("status", "title", "status"))
+	g.RegisterModelWithOptions(&testUser{})
+
+	definitions := NewSwaggerGenerator(g.Models).GenerateModelDefinitions()
+	keys := definitions["testTicket"].(map[string]any)["properties"].(*OrderedProperties).Keys()
+	if want := []string{"status", "title", "id", "priority", "opened_at", "closed_at"}; !slices.Equal(keys, want) {
+		t.Errorf("ordered: got %v, want %v", keys, want)
+	}
+	keys = definitions["testUser"].(map[string]any)["properties"].(*OrderedProperties).Keys()
+	if want := []string{"id", "name", "email"}; !slices.Equal(keys, want) {
+		t.Errorf("declaration order: got %v, want %v", keys, want)
+	}
+
+	generate := func() []byte {
+		data, err := json.Marshal(NewSwaggerGenerator(g.Models).GenerateDocument(SwaggerInfo{Title: "Test API"}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+	first := generate()
+	for i := 0; i < 5; i++ {
+		if !bytes.Equal(generate(), first) {
+			t.Fatal("the Swagger document differs between runs")
+		}
+	}
+	if !bytes.Contains(first, []byte(`"properties":{"status":`)) {
+		t.Errorf("the encoded properties aren't ordered: %s", first)
+	}
+}
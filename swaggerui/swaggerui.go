@@ -0,0 +1,69 @@
+// Package swaggerui serves Swagger UI for the API of an apigen.APIGenerator. The
+// swagger-ui-dist assets are embedded, so no CDN is needed, and only the programs
+// importing this package carry them.
+package swaggerui
+
+import (
+	"embed"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"strings"
+
+	"github.com/Glitchfix/apigen"
+	"github.com/gin-gonic/gin"
+)
+
+//go:generate ./update.sh 5.17.14
+
+// Version is the release of swagger-ui-dist embedded in the package
+const Version = "4.15.5"
+
+// assets holds the page template and, under dist, the swagger-ui-dist files served.
+// The license and readme of the assets stay in the source tree.
+//
+//go:embed index.html dist/*.js dist/*.css dist/*.png
+var assets embed.FS
+
+// page renders the Swagger UI page pointed at a spec URL
+var page = template.Must(template.ParseFS(assets, "index.html"))
+
+// Serve serves Swagger UI at uiPath, e.g. "/docs", showing the Swagger document the
+// generator serves at specPath. The document is served there too unless it already
+// is. Both paths are relative to the group the API is mounted on.
+func Serve(g *apigen.APIGenerator, uiPath, specPath string) {
+	uiPath = "/" + strings.Trim(uiPath, "/")
+	g.ServeSwagger(specPath)
+
+	dist, _ := fs.Sub(assets, "dist")
+	render := func(c *gin.Context) {
+		title := g.SwaggerInfo.Title
+		if title == "" {
+			title = "Swagger UI"
+		}
+
+		c.Header("Content-Type", "text/html; charset=utf-8")
+		c.Status(http.StatusOK)
+		_ = page.Execute(c.Writer, map[string]string{
+			"Title":     title,
+			"AssetPath": g.MountPath() + uiPath,
+			"SpecURL":   g.MountPath() + specPath,
+		})
+	}
+
+	g.Group.GET(uiPath, render)
+	g.Group.GET(uiPath+"/*file", func(c *gin.Context) {
+		file := strings.TrimPrefix(c.Param("file"), "/")
+		if file == "" || file == "index.html" {
+			render(c)
+			return
+		}
+
+		// Only the asset files are served, never a directory listing
+		if info, err := fs.Stat(dist, file); err != nil || info.IsDir() {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		http.ServeFileFS(c.Writer, c.Request, dist, file)
+	})
+}
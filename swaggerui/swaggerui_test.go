@@ -0,0 +1,51 @@
+package swaggerui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Glitchfix/apigen"
+	"github.com/gin-gonic/gin"
+)
+
+func TestServe(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	g := apigen.NewWithGroup(nil, router.Group("/v1"))
+	g.SwaggerInfo.Title = "Test API"
+	Serve(g, "/docs", "/swagger.json")
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	w := get("/v1/docs")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "<title>Test API</title>") {
+		t.Fatalf("page: got %d %s", w.Code, w.Body)
+	}
+	for _, want := range []string{`href="/v1/docs/swagger-ui.css"`, `"/v1/swagger.json"`} {
+		if !strings.Contains(w.Body.String(), want) {
+			t.Errorf("page misses %s", want)
+		}
+	}
+
+	for path, status := range map[string]int{
+		"/v1/docs/index.html":           http.StatusOK,
+		"/v1/docs/swagger-ui.css":       http.StatusOK,
+		"/v1/docs/swagger-ui-bundle.js": http.StatusOK,
+		"/v1/docs/favicon-32x32.png":    http.StatusOK,
+		"/v1/swagger.json":              http.StatusOK,
+		"/v1/docs/README.md":            http.StatusNotFound,
+		"/v1/docs/LICENSE":              http.StatusNotFound,
+		"/v1/docs/dist/swagger-ui.css":  http.StatusNotFound,
+		"/v1/docs/swaggerui.go":         http.StatusNotFound,
+	} {
+		if w := get(path); w.Code != status {
+			t.Errorf("GET %s: got %d, want %d", path, w.Code, status)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+#!/bin/sh
+# Replaces the embedded swagger-ui-dist assets with those of another release, e.g.
+#   ./update.sh 5.17.14
+set -eu
+
+version="${1:?usage: update.sh <swagger-ui-dist version>}"
+dir="$(cd "$(dirname "$0")" && pwd)"
+tmp="$(mktemp -d)"
+trap 'rm -rf "$tmp"' EXIT
+
+curl -sSfL "https://registry.npmjs.org/swagger-ui-dist/-/swagger-ui-dist-$version.tgz" | tar -xz -C "$tmp"
+for file in swagger-ui-bundle.js swagger-ui-standalone-preset.js swagger-ui.css favicon-16x16.png favicon-32x32.png; do
+	cp "$tmp/package/$file" "$dir/dist/$file"
+done
+cp "$tmp/package/LICENSE" "$dir/LICENSE"
+sed -i.bak "s/^const Version = \".*\"$/const Version = \"$version\"/" "$dir/swaggerui.go" && rm "$dir/swaggerui.go.bak"
+echo "swagger-ui-dist $version copied to $dir/dist"
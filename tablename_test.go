@@ -0,0 +1,44 @@
+package apigen
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// testInvoice is stored in a table that doesn't follow the naming convention
+type testInvoice struct {
+	ID    uint `json:"id" gorm:"primaryKey"`
+	Total int  `json:"total"`
+}
+
+func (testInvoice) TableName() string { return "legacy_invoices" }
+
+// testCustomer has invoices in the custom table
+type testCustomer struct {
+	ID       uint          `json:"id" gorm:"primaryKey"`
+	Name     string        `json:"name"`
+	Invoices []testInvoice `json:"invoices,omitempty" gorm:"many2many:test_customer_invoices"`
+}
+
+func TestCustomTableName(t *testing.T) {
+	g, router := newTestAPI(t, nil, &testCustomer{}, &testInvoice{})
+	g.DB.Create(&testCustomer{Name: "Ada", Invoices: []testInvoice{{Total: 42}}})
+
+	if table := g.Models["testInvoice"].TableName; table != "legacy_invoices" {
+		t.Errorf("ModelInfo.TableName: got %q", table)
+	}
+	statement := g.DB.Session(&gorm.Session{DryRun: true}).Find(&[]testInvoice{}).Statement
+	if sql := statement.SQL.String(); !strings.Contains(sql, "`legacy_invoices`") {
+		t.Errorf("query: got %s", sql)
+	}
+
+	if invoices := decode[[]testInvoice](t, serve(router, http.MethodGet, "/api/test_invoices", "")); len(invoices) != 1 {
+		t.Errorf("list: got %+v", invoices)
+	}
+	if invoices := decode[[]testInvoice](t, serve(router, http.MethodGet, "/api/test_customers/1/invoices", "")); len(invoices) != 1 || invoices[0].Total != 42 {
+		t.Errorf("related: got %+v", invoices)
+	}
+}
@@ -0,0 +1,171 @@
+package apigen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// TenantIDKey is the gin context key holding the tenant of a request
+	TenantIDKey = "apigen.tenant_id"
+	// defaultTenantIDHeader carries the tenant ID when MultiTenancy.TenantIDHeader is empty
+	defaultTenantIDHeader = "X-Tenant-ID"
+)
+
+// errMissingTenant is returned by queries of isolated models run without a tenant
+var errMissingTenant = errors.New("tenant ID is missing")
+
+// tenantContextKey is the request context key holding the tenant ID read by TenantScope
+type tenantContextKey struct{}
+
+// errForeignTenant answers requests naming a tenant their caller doesn't belong to
+var errForeignTenant = errors.New("access to this tenant is forbidden")
+
+// MultiTenancy isolates the records of some models per tenant. The tenant of a request
+// is read from a header, and the enforced models only see and write records of that tenant.
+type MultiTenancy struct {
+	TenantIDHeader string   // Header carrying the tenant ID, "X-Tenant-ID" by default
+	TenantField    string   // Column holding the tenant ID, e.g. "tenant_id"
+	EnforcedModels []string // Names of the isolated models
+
+	// Authorize checks that the caller belongs to the tenant named by the header, e.g.
+	// with TenantFromClaim. Without it any client can name any tenant, so it should be
+	// set unless a proxy in front of the API sets the header.
+	Authorize TenantAuthorizer
+}
+
+// TenantAuthorizer checks the tenant a request names against the identity its auth
+// middleware established. It runs after the model's auth middleware; an error rejects
+// the request with 403 Forbidden.
+type TenantAuthorizer func(c *gin.Context, tenantID string) error
+
+// TenantFromClaim returns a TenantAuthorizer accepting the tenant held by a claim of the
+// JWT verified by NewJWTMiddleware, e.g. "tenant_id", and rejecting any other
+func TenantFromClaim(claim string) TenantAuthorizer {
+	return func(c *gin.Context, tenantID string) error {
+		value, ok := ClaimsFromContext(c)[claim]
+		if !ok || fmt.Sprint(value) != tenantID {
+			return errForeignTenant
+		}
+		return nil
+	}
+}
+
+// enforced reports whether a model is isolated per tenant
+func (m MultiTenancy) enforced(modelInfo ModelInfo) bool {
+	return m.enforcedModel(modelInfo.Type.Name())
+}
+
+// enforcedModel reports whether the model with the given name is isolated per tenant
+func (m MultiTenancy) enforcedModel(name string) bool {
+	return m.TenantField != "" && name != "" && slices.Contains(m.EnforcedModels, name)
+}
+
+// TenantMiddleware reads the tenant ID of a request from the configured header and stores
+// it under TenantIDKey and in the request context for TenantScope. Requests without a
+// tenant ID are rejected with 401, and those cfg.Authorize refuses with 403.
+func TenantMiddleware(cfg MultiTenancy) gin.HandlerFunc {
+	return newTenantMiddleware(cfg, abortWithError)
+}
+
+// newTenantMiddleware returns TenantMiddleware answering errors with respondError
+func newTenantMiddleware(cfg MultiTenancy, respondError func(c *gin.Context, status int, err error)) gin.HandlerFunc {
+	header := cfg.TenantIDHeader
+	if header == "" {
+		header = defaultTenantIDHeader
+	}
+
+	return func(c *gin.Context) {
+		tenantID := c.GetHeader(header)
+		if tenantID == "" {
+			respondError(c, http.StatusUnauthorized, errMissingTenant)
+			return
+		}
+		if cfg.Authorize != nil {
+			if err := cfg.Authorize(c, tenantID); err != nil {
+				respondError(c, http.StatusForbidden, err)
+				return
+			}
+		}
+
+		c.Set(TenantIDKey, tenantID)
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), tenantContextKey{}, tenantID))
+		c.Next()
+	}
+}
+
+// TenantScope filters queries on column by the tenant ID that TenantMiddleware stored in
+// the request context. The session must carry that context, e.g. db.WithContext(ctx).
+func TenantScope(column string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		tenantID, ok := db.Statement.Context.Value(tenantContextKey{}).(string)
+		if !ok {
+			_ = db.AddError(errMissingTenant)
+			return db
+		}
+		return db.Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: column}, Value: tenantID})
+	}
+}
+
+// tenantMiddleware returns the tenant middleware of an endpoint, or nil when it reads
+// and writes no model isolated per tenant. The routes of shared models need a tenant for
+// the isolated records they reach: those of a related or parent model, and the preloaded
+// associations, which only ask for a tenant when a request loads them.
+func (g *APIGenerator) tenantMiddleware(modelInfo ModelInfo, route RouteInfo) gin.HandlerFunc {
+	if g.MultiTenancy.enforced(modelInfo) || g.MultiTenancy.enforcedModel(route.RelatedModel) {
+		return newTenantMiddleware(g.MultiTenancy, g.respondError)
+	}
+	switch route.Verb {
+	case VerbList, VerbGet, VerbQuery:
+	default:
+		return nil
+	}
+
+	var isolated []string
+	for association, relatedModelInfo := range g.associationModels(modelInfo) {
+		if g.MultiTenancy.enforced(relatedModelInfo) {
+			isolated = append(isolated, association)
+		}
+	}
+	if len(isolated) == 0 {
+		return nil
+	}
+	tenant := newTenantMiddleware(g.MultiTenancy, g.respondError)
+	return func(c *gin.Context) {
+		// Unknown associations are refused by the handler
+		associations, err := requestedPreloads(c, modelInfo)
+		if err == nil && (modelInfo.PreloadAssociations || slices.ContainsFunc(associations, func(association string) bool {
+			return slices.Contains(isolated, association)
+		})) {
+			tenant(c)
+			return
+		}
+		c.Next()
+	}
+}
+
+// setTenant stores the tenant of a request in the tenant field of an instance about to
+// be written, overriding whatever the client sent
+func (g *APIGenerator) setTenant(c *gin.Context, modelInfo ModelInfo, instance any) error {
+	if !g.MultiTenancy.enforced(modelInfo) {
+		return nil
+	}
+
+	stmt := &gorm.Statement{DB: g.DB}
+	if err := stmt.Parse(instance); err != nil {
+		return err
+	}
+	field := stmt.Schema.LookUpField(g.MultiTenancy.TenantField)
+	if field == nil {
+		return errors.New("model has no tenant field " + g.MultiTenancy.TenantField)
+	}
+	return field.Set(c.Request.Context(), reflect.ValueOf(instance), c.GetString(TenantIDKey))
+}
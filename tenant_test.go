@@ -0,0 +1,98 @@
+package apigen
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type testNote struct {
+	ID       uint   `json:"id" gorm:"primaryKey"`
+	TenantID string `json:"tenant_id"`
+	Text     string `json:"text"`
+}
+
+func TestMultiTenancy(t *testing.T) {
+	// Stands in for an auth middleware verifying a token carrying a tenant claim
+	auth := func(c *gin.Context) {
+		c.Set(DefaultClaimsKey, jwt.MapClaims{"tenant_id": c.GetHeader("X-Test-Tenant")})
+		c.Next()
+	}
+	g, router := newTestAPI(t, func(g *APIGenerator) {
+		g.MultiTenancy = MultiTenancy{
+			TenantField:    "tenant_id",
+			EnforcedModels: []string{"testNote"},
+			Authorize:      TenantFromClaim("tenant_id"),
+		}
+		if err := g.RegisterModelWithOptions(&testNote{}, WithAuthMiddleware(auth)); err != nil {
+			t.Fatal(err)
+		}
+	}, &testNote{})
+	g.DB.Create(&[]testNote{{TenantID: "acme", Text: "a"}, {TenantID: "globex", Text: "g"}})
+
+	w := serve(router, http.MethodGet, "/api/test_notes", "", "X-Tenant-ID", "acme", "X-Test-Tenant", "acme")
+	if notes := decode[[]testNote](t, w); w.Code != http.StatusOK || len(notes) != 1 || notes[0].TenantID != "acme" {
+		t.Errorf("own tenant: got %d %s", w.Code, w.Body)
+	}
+	if w := serve(router, http.MethodGet, "/api/test_notes", "", "X-Tenant-ID", "globex", "X-Test-Tenant", "acme"); w.Code != http.StatusForbidden {
+		t.Errorf("other tenant: got %d %s, want 403", w.Code, w.Body)
+	}
+	if w := serve(router, http.MethodGet, "/api/test_notes", "", "X-Test-Tenant", "acme"); w.Code != http.StatusUnauthorized {
+		t.Errorf("no tenant: got %d, want 401", w.Code)
+	}
+
+	w = serve(router, http.MethodPost, "/api/test_notes", `{"tenant_id":"globex","text":"x"}`, "X-Tenant-ID", "acme", "X-Test-Tenant", "acme")
+	if note := decode[testNote](t, w); w.Code != http.StatusCreated || note.TenantID != "acme" {
+		t.Errorf("create naming another tenant: got %d %s", w.Code, w.Body)
+	}
+}
+
+type testBrand struct {
+	ID       uint          `json:"id" gorm:"primaryKey"`
+	Name     string        `json:"name"`
+	Variants []testVariant `json:"variants,omitempty" gorm:"polymorphic:Owner"`
+}
+
+type testVariant struct {
+	ID        uint   `json:"id" gorm:"primaryKey"`
+	TenantID  string `json:"tenant_id"`
+	OwnerID   uint   `json:"owner_id"`
+	OwnerType string `json:"owner_type"`
+	Name      string `json:"name"`
+}
+
+func TestMultiTenancyRelatedRecords(t *testing.T) {
+	// Brands are shared between tenants while their variants are isolated
+	g, router := newTestAPI(t, func(g *APIGenerator) {
+		g.MultiTenancy = MultiTenancy{
+			TenantField:    "tenant_id",
+			EnforcedModels: []string{"testVariant"},
+		}
+	}, &testBrand{}, &testVariant{})
+	g.DB.Create(&testBrand{Name: "b", Variants: []testVariant{{TenantID: "acme", Name: "a"}, {TenantID: "globex", Name: "g"}}})
+
+	w := serve(router, http.MethodGet, "/api/test_brands/1?preload=Variants", "", "X-Tenant-ID", "acme")
+	if brand := decode[testBrand](t, w); w.Code != http.StatusOK || len(brand.Variants) != 1 || brand.Variants[0].TenantID != "acme" {
+		t.Errorf("preload: got %d %s", w.Code, w.Body)
+	}
+	w = serve(router, http.MethodGet, "/api/test_brands?preload=Variants", "", "X-Tenant-ID", "globex")
+	if brands := decode[[]testBrand](t, w); w.Code != http.StatusOK || len(brands) != 1 || len(brands[0].Variants) != 1 || brands[0].Variants[0].TenantID != "globex" {
+		t.Errorf("list preload: got %d %s", w.Code, w.Body)
+	}
+	if w := serve(router, http.MethodGet, "/api/test_brands/1?preload=Variants", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("preload without tenant: got %d, want 401", w.Code)
+	}
+	if w := serve(router, http.MethodGet, "/api/test_brands/1", ""); w.Code != http.StatusOK {
+		t.Errorf("shared record without tenant: got %d %s", w.Code, w.Body)
+	}
+
+	w = serve(router, http.MethodGet, "/api/test_brands/1/variants", "", "X-Tenant-ID", "acme")
+	if variants := decode[[]testVariant](t, w); w.Code != http.StatusOK || len(variants) != 1 || variants[0].TenantID != "acme" {
+		t.Errorf("related: got %d %s", w.Code, w.Body)
+	}
+	if w := serve(router, http.MethodGet, "/api/test_brands/1/variants", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("related without tenant: got %d, want 401", w.Code)
+	}
+}
@@ -0,0 +1,45 @@
+package apigen
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errQueryTimeout is answered with 503 when a query outlives its DB timeout
+var errQueryTimeout = errors.New("query timeout")
+
+// WithDBTimeout cancels the queries of a request to a model's endpoints after d,
+// overriding APIGenerator.DBTimeout. A negative d lifts the timeout.
+func WithDBTimeout(d time.Duration) ModelOption {
+	return func(info *ModelInfo) {
+		info.DBTimeout = d
+	}
+}
+
+// dbTimeout returns the query timeout of a model, 0 when there is none
+func (g *APIGenerator) dbTimeout(modelInfo ModelInfo) time.Duration {
+	timeout := modelInfo.DBTimeout
+	if timeout == 0 {
+		timeout = g.DBTimeout
+	}
+	return max(timeout, 0)
+}
+
+// timeoutMiddleware returns the middleware giving the queries of a request a deadline,
+// through the request context they run with. It returns nil when there is no timeout,
+// and for event streams, which stay open for as long as the client listens.
+func (g *APIGenerator) timeoutMiddleware(modelInfo ModelInfo, verb string) gin.HandlerFunc {
+	timeout := g.dbTimeout(modelInfo)
+	if timeout == 0 || verb == VerbStream {
+		return nil
+	}
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
@@ -0,0 +1,39 @@
+package apigen
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// slowScope makes every query count to a hundred million before returning
+func slowScope(db *gorm.DB) *gorm.DB {
+	return db.Where("(WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM c WHERE x < 100000000) SELECT COUNT(*) FROM c) > 0")
+}
+
+func TestDBTimeout(t *testing.T) {
+	g, router := newTestAPI(t, func(g *APIGenerator) {
+		g.DBTimeout = 50 * time.Millisecond
+		g.RegisterModelWithOptions(&testUser{}, WithScopes(slowScope))
+		g.RegisterModelWithOptions(&testProject{})
+	}, &testUser{}, &testProject{})
+	g.DB.Create(&testUser{Name: "Ada"})
+
+	start := time.Now()
+	w := serve(router, http.MethodGet, "/api/test_users", "")
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("got %d %s, want 503", w.Code, w.Body)
+	}
+	if body := decode[map[string]any](t, w); body["error"] != "query timeout" {
+		t.Errorf("got %v", body)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("the query ran for %s", elapsed)
+	}
+
+	if w := serve(router, http.MethodGet, "/api/test_projects", ""); w.Code != http.StatusOK {
+		t.Errorf("fast query: got %d %s", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,130 @@
+package apigen
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const (
+	// tracerName identifies the spans created by this package
+	tracerName = "github.com/Glitchfix/apigen"
+	// spanInstanceKey stores the active DB span on a GORM statement
+	spanInstanceKey = "apigen:span"
+)
+
+// EnableTracing creates OpenTelemetry spans for every generated endpoint and every
+// database operation issued through the generator's DB. It must be called before
+// GenerateAPI; without it no spans are created and tracing has no overhead.
+func (g *APIGenerator) EnableTracing(tp trace.TracerProvider) error {
+	g.tracer = tp.Tracer(tracerName)
+
+	// registrar is satisfied by the callbacks returned from GORM's Before and After
+	type registrar interface {
+		Register(name string, fn func(*gorm.DB)) error
+	}
+
+	callbacks := g.DB.Callback()
+	hooks := []struct {
+		operation     string
+		before, after registrar
+	}{
+		{"create", callbacks.Create().Before("*"), callbacks.Create().After("*")},
+		{"query", callbacks.Query().Before("*"), callbacks.Query().After("*")},
+		{"update", callbacks.Update().Before("*"), callbacks.Update().After("*")},
+		{"delete", callbacks.Delete().Before("*"), callbacks.Delete().After("*")},
+		{"row", callbacks.Row().Before("*"), callbacks.Row().After("*")},
+		{"raw", callbacks.Raw().Before("*"), callbacks.Raw().After("*")},
+	}
+
+	for _, hook := range hooks {
+		if err := hook.before.Register("apigen:trace_before_"+hook.operation, g.startDBSpan(hook.operation)); err != nil {
+			return err
+		}
+		if err := hook.after.Register("apigen:trace_after_"+hook.operation, endDBSpan); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// startDBSpan returns a GORM callback starting a span named apigen.db.{operation}.{model}
+func (g *APIGenerator) startDBSpan(operation string) func(*gorm.DB) {
+	return func(tx *gorm.DB) {
+		model := ""
+		if tx.Statement.Schema != nil {
+			model = tx.Statement.Schema.Name
+		}
+
+		ctx, span := g.tracer.Start(tx.Statement.Context, fmt.Sprintf("apigen.db.%s.%s", operation, model),
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("db.system", tx.Dialector.Name()),
+				attribute.String("db.operation", operation),
+				attribute.String("apigen.model", model),
+			),
+		)
+		tx.Statement.Context = ctx
+		tx.InstanceSet(spanInstanceKey, span)
+	}
+}
+
+// endDBSpan is the GORM callback finishing the span started by startDBSpan
+func endDBSpan(tx *gorm.DB) {
+	value, ok := tx.InstanceGet(spanInstanceKey)
+	if !ok {
+		return
+	}
+	span := value.(trace.Span)
+
+	span.SetAttributes(
+		attribute.String("db.statement", tx.Statement.SQL.String()),
+		attribute.String("db.table", tx.Statement.Table),
+		attribute.Int64("db.rows_affected", tx.Statement.RowsAffected),
+	)
+	if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(tx.Error)
+		span.SetStatus(codes.Error, tx.Error.Error())
+	}
+	span.End()
+}
+
+// tracingMiddleware returns a middleware wrapping a model endpoint in a span named
+// apigen.http.{verb}.{model}, or nil when tracing is not enabled
+func (g *APIGenerator) tracingMiddleware(modelInfo ModelInfo, method string) gin.HandlerFunc {
+	if g.tracer == nil {
+		return nil
+	}
+
+	name := fmt.Sprintf("apigen.http.%s.%s", strings.ToLower(method), modelInfo.Type.Name())
+	return func(c *gin.Context) {
+		ctx, span := g.tracer.Start(c.Request.Context(), name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.method", method),
+				attribute.String("http.route", c.FullPath()),
+				attribute.String("apigen.model", modelInfo.Type.Name()),
+			),
+		)
+		defer span.End()
+		if requestID := c.GetString(RequestIDKey); requestID != "" {
+			span.SetAttributes(attribute.String(requestIDAttribute, requestID))
+		}
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(attribute.Int("http.status_code", status))
+		if status >= 500 {
+			span.SetStatus(codes.Error, fmt.Sprintf("HTTP %d", status))
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package apigen
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/embedded"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// testTracerProvider records the names of the spans started by its tracers
+type testTracerProvider struct {
+	embedded.TracerProvider
+	mu    sync.Mutex
+	spans []string
+}
+
+func (p *testTracerProvider) Tracer(name string, opts ...trace.TracerOption) trace.Tracer {
+	return testTracer{provider: p}
+}
+
+// testTracer starts no-op spans, recording their names
+type testTracer struct {
+	embedded.Tracer
+	provider *testTracerProvider
+}
+
+func (t testTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	t.provider.mu.Lock()
+	t.provider.spans = append(t.provider.spans, name)
+	t.provider.mu.Unlock()
+	return noop.NewTracerProvider().Tracer("").Start(ctx, name, opts...)
+}
+
+func TestTracing(t *testing.T) {
+	provider := &testTracerProvider{}
+	_, router := newTestAPI(t, func(g *APIGenerator) {
+		if err := g.EnableTracing(provider); err != nil {
+			t.Fatalf("enable tracing: %v", err)
+		}
+	}, &testUser{})
+
+	if w := serve(router, http.MethodGet, "/api/test_users", ""); w.Code != http.StatusOK {
+		t.Fatalf("list: got %d", w.Code)
+	}
+	for _, name := range []string{"apigen.http.get.testUser", "apigen.db.query.testUser"} {
+		if !slices.Contains(provider.spans, name) {
+			t.Errorf("span %s not started, got %v", name, provider.spans)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package apigen
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ResponseTransform post-processes the records of a response before they are
+// serialised, e.g. to redact PII or add computed URLs. instances holds the records as
+// maps keyed by their API field names; single record responses pass one element.
+type ResponseTransform func(ctx context.Context, modelName string, instances []map[string]any) ([]map[string]any, error)
+
+// WithTransformResponse passes the records returned by every endpoint of a model
+// through transform. An error returned by transform results in a 500.
+func WithTransformResponse(transform ResponseTransform) ModelOption {
+	return func(info *ModelInfo) {
+		info.TransformResponse = transform
+	}
+}
+
+// RequestTransform rewrites a record bound from a create or update request before it is
+// written, e.g. to lowercase an email or strip HTML. data holds the record keyed by its
+// API field names; the values of the returned map are set back on the record.
+type RequestTransform func(ctx context.Context, modelName string, data map[string]any) (map[string]any, error)
+
+// WithTransformRequest passes the records bound by the create and update endpoints of
+// a model through transform. An error returned by transform results in a 422.
+func WithTransformRequest(transform RequestTransform) ModelOption {
+	return func(info *ModelInfo) {
+		info.TransformRequest = transform
+	}
+}
+
+// transformRequest passes a bound instance through the model's TransformRequest and
+// applies the values it returns
+func (g *APIGenerator) transformRequest(c *gin.Context, modelInfo ModelInfo, instance any) error {
+	if modelInfo.TransformRequest == nil {
+		return nil
+	}
+	data, err := modelInfo.TransformRequest(c.Request.Context(), modelInfo.Type.Name(), snapshot(instance, modelInfo))
+	if err != nil {
+		return err
+	}
+
+	modelInfo.renameKeys(data, false)
+	body, err := json.Marshal(data)
+	if err == nil {
+		err = json.Unmarshal(body, instance)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to apply the transformed request: %w", err)
+	}
+	return nil
+}
+
+// transformRecords passes records through the model's TransformResponse, checking it
+// returned one record for each when the records can't be dropped
+func (g *APIGenerator) transformRecords(c *gin.Context, modelInfo ModelInfo, records []map[string]any, keepCount bool) ([]map[string]any, error) {
+	transformed, err := modelInfo.TransformResponse(c.Request.Context(), modelInfo.Type.Name(), records)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transform the response: %w", err)
+	}
+	if keepCount && len(transformed) != len(records) {
+		return nil, fmt.Errorf("failed to transform the response: got %d records, want %d", len(transformed), len(records))
+	}
+	return transformed, nil
+}
+
+// transformBody transforms a response body built by selectFields, either one record
+// or a slice of them
+func (g *APIGenerator) transformBody(c *gin.Context, modelInfo ModelInfo, body any) (any, error) {
+	switch body := body.(type) {
+	case []map[string]any:
+		return g.transformRecords(c, modelInfo, body, false)
+	case map[string]any:
+		transformed, err := g.transformRecords(c, modelInfo, []map[string]any{body}, true)
+		if err != nil {
+			return nil, err
+		}
+		return transformed[0], nil
+	}
+	return nil, errors.New("failed to transform the response: unexpected body")
+}
+
+// transformAttributes transforms the attributes of the resources of a JSON:API document
+func (g *APIGenerator) transformAttributes(c *gin.Context, modelInfo ModelInfo, document gin.H) error {
+	resources, ok := document["data"].([]map[string]any)
+	if !ok {
+		resources = []map[string]any{document["data"].(map[string]any)}
+	}
+
+	attributes := make([]map[string]any, 0, len(resources))
+	for _, resource := range resources {
+		values, _ := resource["attributes"].(map[string]any)
+		attributes = append(attributes, values)
+	}
+	transformed, err := g.transformRecords(c, modelInfo, attributes, true)
+	if err != nil {
+		return err
+	}
+	for i, resource := range resources {
+		resource["attributes"] = transformed[i]
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package apigen
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// testPatient has a field redacted from responses
+type testPatient struct {
+	ID   uint   `json:"id" gorm:"primaryKey"`
+	Name string `json:"name"`
+	SSN  string `json:"ssn"`
+}
+
+// redactSSN removes the ssn of every record
+func redactSSN(_ context.Context, _ string, instances []map[string]any) ([]map[string]any, error) {
+	for _, instance := range instances {
+		delete(instance, "ssn")
+	}
+	return instances, nil
+}
+
+func TestTransformResponse(t *testing.T) {
+	g, router := newTestAPI(t, func(g *APIGenerator) {
+		g.RegisterModelWithOptions(&testPatient{}, WithTransformResponse(redactSSN))
+	}, &testPatient{})
+	g.DB.Create(&testPatient{Name: "Ada", SSN: "123-45-6789"})
+
+	patient := decode[map[string]any](t, serve(router, http.MethodGet, "/api/test_patients/1", ""))
+	if _, ok := patient["ssn"]; ok || patient["name"] != "Ada" {
+		t.Errorf("get: got %v", patient)
+	}
+	patients := decode[[]map[string]any](t, serve(router, http.MethodGet, "/api/test_patients", ""))
+	if _, ok := patients[0]["ssn"]; len(patients) != 1 || ok {
+		t.Errorf("list: got %v", patients)
+	}
+	w := serve(router, http.MethodPost, "/api/test_patients", `{"name":"Grace","ssn":"987-65-4321"}`)
+	if created := decode[map[string]any](t, w); w.Code != http.StatusCreated || created["ssn"] != nil {
+		t.Errorf("create: got %d %v", w.Code, created)
+	}
+}
+
+func TestTransformResponseError(t *testing.T) {
+	g, router := newTestAPI(t, func(g *APIGenerator) {
+		g.RegisterModelWithOptions(&testPatient{}, WithTransformResponse(func(context.Context, string, []map[string]any) ([]map[string]any, error) {
+			return nil, errors.New("redaction service unavailable")
+		}))
+	}, &testPatient{})
+	g.DB.Create(&testPatient{Name: "Ada", SSN: "123-45-6789"})
+
+	w := serve(router, http.MethodGet, "/api/test_patients/1", "")
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("get: got %d, want 500", w.Code)
+	}
+}
+
+// lowercaseEmail normalises the email of a record
+func lowercaseEmail(_ context.Context, _ string, data map[string]any) (map[string]any, error) {
+	if email, ok := data["email"].(string); ok {
+		data["email"] = strings.ToLower(email)
+	}
+	return data, nil
+}
+
+func TestTransformRequest(t *testing.T) {
+	g, router := newTestAPI(t, func(g *APIGenerator) {
+		g.RegisterModelWithOptions(&testUser{}, WithTransformRequest(lowercaseEmail))
+	}, &testUser{})
+
+	w := serve(router, http.MethodPost, "/api/test_users", `{"name":"Ada","email":"Ada@Example.COM"}`)
+	if user := decode[testUser](t, w); w.Code != http.StatusCreated || user.Email != "ada@example.com" {
+		t.Errorf("create: got %d %s", w.Code, w.Body)
+	}
+	if w := serve(router, http.MethodPatch, "/api/test_users/1", `{"email":"ADA@LOVELACE.ORG"}`); w.Code != http.StatusOK {
+		t.Errorf("patch: got %d %s", w.Code, w.Body)
+	}
+
+	var stored testUser
+	g.DB.First(&stored, 1)
+	if stored.Email != "ada@lovelace.org" || stored.Name != "Ada" {
+		t.Errorf("stored %+v", stored)
+	}
+}
+
+func TestTransformRequestError(t *testing.T) {
+	g, router := newTestAPI(t, func(g *APIGenerator) {
+		g.RegisterModelWithOptions(&testUser{}, WithTransformRequest(func(context.Context, string, map[string]any) (map[string]any, error) {
+			return nil, errors.New("email domain is not allowed")
+		}))
+	}, &testUser{})
+
+	if w := serve(router, http.MethodPost, "/api/test_users", `{"name":"Ada"}`); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("create: got %d, want 422", w.Code)
+	}
+	var count int64
+	g.DB.Model(&testUser{}).Count(&count)
+	if count != 0 {
+		t.Errorf("got %d users, want 0", count)
+	}
+}
@@ -0,0 +1,176 @@
+package apigen
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// tsIdentifier matches the property names that need no quotes in TypeScript
+var tsIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// TypeScriptGenerator generates TypeScript definitions of the registered models and
+// their request bodies
+type TypeScriptGenerator struct {
+	Models map[string]ModelInfo
+}
+
+// NewTypeScriptGenerator creates a new TypeScriptGenerator
+func NewTypeScriptGenerator(models map[string]ModelInfo) *TypeScriptGenerator {
+	return &TypeScriptGenerator{Models: models}
+}
+
+// Generate returns a TypeScript module with an interface per model, e.g. User, and the
+// CreateUserRequest and UpdateUserRequest types of its request bodies
+func (g *TypeScriptGenerator) Generate() string {
+	var doc strings.Builder
+	doc.WriteString("// Code generated by apigen. DO NOT EDIT.\n")
+
+	// Sort the models so the output is stable
+	names := make([]string, 0, len(g.Models))
+	for name := range g.Models {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		modelInfo := g.Models[name]
+
+		fmt.Fprintf(&doc, "\nexport interface %s {\n", name)
+		g.writeFields(&doc, modelInfo.Fields)
+		doc.WriteString("}\n")
+
+		// Same field sets as GenerateRequestStruct
+		var createFields, updateFields []FieldInfo
+		for _, field := range modelInfo.Fields {
+			if modelInfo.isReadOnly(field) {
+				continue
+			}
+			if !(field.IsID && field.Name == "ID") {
+				createFields = append(createFields, field)
+			}
+			updateFields = append(updateFields, field)
+		}
+		fmt.Fprintf(&doc, "\nexport type Create%sRequest = {\n", name)
+		g.writeFields(&doc, createFields)
+		doc.WriteString("};\n")
+		fmt.Fprintf(&doc, "\nexport type Update%sRequest = {\n", name)
+		g.writeFields(&doc, updateFields)
+		doc.WriteString("};\n")
+	}
+
+	return doc.String()
+}
+
+// writeFields writes one property per field, optional when the field is omitempty
+func (g *TypeScriptGenerator) writeFields(doc *strings.Builder, fields []FieldInfo) {
+	for _, field := range fields {
+		optional := ""
+		if field.OmitEmpty {
+			optional = "?"
+		}
+		fmt.Fprintf(doc, "  %s%s: %s;\n", tsPropertyName(field.JSONName), optional, g.tsType(field.Type, map[reflect.Type]bool{}))
+	}
+}
+
+// tsType returns the TypeScript type of a Go type. Registered models are referenced
+// by name and other structs are inlined; seen guards against recursive structs.
+func (g *TypeScriptGenerator) tsType(t reflect.Type, seen map[reflect.Type]bool) string {
+	switch {
+	case t.String() == "time.Time", t == uuidType:
+		return "string"
+	case t == deletedAtType:
+		return "string | null"
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		return "string" // encoding/json writes []byte as base64
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Ptr:
+		return g.tsType(t.Elem(), seen) + " | null"
+	case reflect.Slice, reflect.Array:
+		elem := g.tsType(t.Elem(), seen)
+		if strings.Contains(elem, " ") {
+			elem = "(" + elem + ")"
+		}
+		return elem + "[]"
+	case reflect.Map:
+		return fmt.Sprintf("Record<string, %s>", g.tsType(t.Elem(), seen))
+	case reflect.Struct:
+		if _, registered := g.Models[t.Name()]; registered {
+			return t.Name()
+		}
+		if seen[t] {
+			return "unknown"
+		}
+		seen[t] = true
+		defer delete(seen, t)
+		return g.tsInline(t, seen)
+	default:
+		return "unknown"
+	}
+}
+
+// tsInline returns the inline object type of a struct, following encoding/json:
+// embedded structs without a json tag are flattened and "-" fields are skipped
+func (g *TypeScriptGenerator) tsInline(t reflect.Type, seen map[reflect.Type]bool) string {
+	var properties []string
+	var collect func(t reflect.Type)
+	collect = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			tag := field.Tag.Get("json")
+			if tag == "-" || (!field.IsExported() && !field.Anonymous) {
+				continue
+			}
+			if field.Anonymous && tag == "" && field.Type.Kind() == reflect.Struct {
+				collect(field.Type)
+				continue
+			}
+
+			name, options, _ := strings.Cut(tag, ",")
+			if name == "" {
+				name = field.Name
+			}
+			optional := ""
+			if strings.Contains(options, "omitempty") {
+				optional = "?"
+			}
+			properties = append(properties, fmt.Sprintf("%s%s: %s", tsPropertyName(name), optional, g.tsType(field.Type, seen)))
+		}
+	}
+	collect(t)
+
+	if len(properties) == 0 {
+		return "{}"
+	}
+	return "{ " + strings.Join(properties, "; ") + " }"
+}
+
+// tsPropertyName quotes property names that aren't valid identifiers
+func tsPropertyName(name string) string {
+	if tsIdentifier.MatchString(name) {
+		return name
+	}
+	return fmt.Sprintf("%q", name)
+}
+
+// ServeTypeScript serves the TypeScript definitions of the registered models at path
+func (g *APIGenerator) ServeTypeScript(path string) {
+	g.Group.GET(path, func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/typescript; charset=utf-8", []byte(NewTypeScriptGenerator(g.Models).Generate()))
+	})
+}
@@ -0,0 +1,57 @@
+package apigen
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testAddress is inlined in the TypeScript definitions
+type testAddress struct {
+	Street string `json:"street"`
+	Zip    string `json:"zip,omitempty"`
+}
+
+// testProfile covers the Go types mapped to TypeScript
+type testProfile struct {
+	ID       uint              `json:"id" gorm:"primaryKey"`
+	Name     string            `json:"name"`
+	Active   bool              `json:"active"`
+	Born     time.Time         `json:"born"`
+	Nickname *string           `json:"nickname,omitempty"`
+	Scores   []float64         `json:"scores" gorm:"serializer:json"`
+	Labels   map[string]string `json:"labels" gorm:"serializer:json"`
+	Address  testAddress       `json:"address" gorm:"serializer:json"`
+	Tags     []testTag         `json:"tags" gorm:"many2many:test_profile_tags"`
+}
+
+func TestTypeScriptGenerator(t *testing.T) {
+	_, router := newTestAPI(t, func(g *APIGenerator) {
+		g.RegisterModelWithOptions(&testProfile{})
+		g.RegisterModelWithOptions(&testTag{})
+		g.ServeTypeScript("/api.ts")
+	}, &testProfile{}, &testTag{})
+
+	w := serve(router, http.MethodGet, "/api.ts", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("serve: got %d", w.Code)
+	}
+	output := w.Body.String()
+
+	for _, want := range []string{
+		"export interface testProfile {\n  id: number;\n  name: string;\n  active: boolean;\n  born: string;\n",
+		"  nickname?: string | null;\n",
+		"  scores: number[];\n",
+		"  labels: Record<string, string>;\n",
+		"  address: { street: string; zip?: string };\n",
+		"  tags: testTag[];\n",
+		"export interface testTag {\n",
+		"export type CreatetestProfileRequest = {\n  name: string;\n",
+		"export type UpdatetestProfileRequest = {\n  id: number;\n",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output misses %q:\n%s", want, output)
+		}
+	}
+}
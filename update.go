@@ -0,0 +1,99 @@
+package apigen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+// requestKeys returns the top-level keys present in the request body, leaving the
+// body in place for binding. Malformed bodies yield no keys, binding reports them.
+func (g *APIGenerator) requestKeys(c *gin.Context) map[string]bool {
+	keys := map[string]bool{}
+	switch c.ContentType() {
+	case binding.MIMEPOSTForm, binding.MIMEMultipartPOSTForm:
+		if c.ContentType() == binding.MIMEMultipartPOSTForm {
+			_ = c.Request.ParseMultipartForm(g.maxMultipartMemory())
+		} else {
+			_ = c.Request.ParseForm()
+		}
+		for key := range c.Request.PostForm {
+			keys[key] = true
+		}
+		if c.Request.MultipartForm != nil {
+			for key := range c.Request.MultipartForm.File {
+				keys[key] = true
+			}
+		}
+	default:
+		if c.Request.Body == nil {
+			return keys
+		}
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			// The error is left for the binding, e.g. a body over its size limit
+			c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), errReader{err}))
+			return keys
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		values := map[string]json.RawMessage{}
+		if json.Unmarshal(body, &values) == nil {
+			for key := range values {
+				keys[key] = true
+			}
+		}
+	}
+	return keys
+}
+
+// cloneInstance returns a shallow copy of a model instance
+func cloneInstance(instance any) any {
+	value := reflect.ValueOf(instance).Elem()
+	clone := reflect.New(value.Type())
+	clone.Elem().Set(value)
+	return clone.Interface()
+}
+
+// updateColumns returns the columns an update writes: those of the fields sent in the
+// request, zero values included, and those changed since the record was loaded, e.g.
+// by a BeforeUpdate hook
+func (g *APIGenerator) updateColumns(modelInfo ModelInfo, keys map[string]bool, original, instance any) []string {
+	var columns []string
+	selected := map[string]bool{}
+	add := func(column string) {
+		if column != "" && !selected[column] {
+			selected[column] = true
+			columns = append(columns, column)
+		}
+	}
+
+	for key := range keys {
+		if field, ok := modelInfo.fieldByJSONName(key); ok && !modelInfo.isReadOnly(field) {
+			add(g.columnName(field))
+		}
+	}
+
+	stmt := &gorm.Statement{DB: g.DB}
+	if err := stmt.Parse(instance); err == nil {
+		before := reflect.ValueOf(original).Elem()
+		after := reflect.ValueOf(instance).Elem()
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName == "" || field.PrimaryKey {
+				continue
+			}
+			old, _ := field.ValueOf(context.Background(), before)
+			current, _ := field.ValueOf(context.Background(), after)
+			if !reflect.DeepEqual(old, current) {
+				add(field.DBName)
+			}
+		}
+	}
+	return columns
+}
@@ -0,0 +1,38 @@
+package apigen
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestUpdateZeroValues(t *testing.T) {
+	g, router := newTestAPI(t, nil, &testAccount{})
+	g.DB.Create(&testAccount{Email: "a@b.co", Password: "12345678", Plan: "pro", Age: 30})
+
+	if w := serve(router, http.MethodPatch, "/api/test_accounts/1", `{"age":0}`); w.Code != http.StatusOK {
+		t.Fatalf("patch: got %d %s", w.Code, w.Body)
+	}
+	var account testAccount
+	g.DB.First(&account, 1)
+	if account.Age != 0 || account.Plan != "pro" || account.Email != "a@b.co" {
+		t.Errorf("after zeroing the age: %+v", account)
+	}
+}
+
+func TestUpdateNullableField(t *testing.T) {
+	g, router := newTestAPI(t, nil, &testProfile{})
+	nickname := "ada"
+	g.DB.Create(&testProfile{Name: "Ada", Nickname: &nickname})
+
+	if field, _ := g.Models["testProfile"].fieldByJSONName("nickname"); !field.Nullable {
+		t.Errorf("nickname is not nullable: %+v", field)
+	}
+	if w := serve(router, http.MethodPatch, "/api/test_profiles/1", `{"nickname":null}`); w.Code != http.StatusOK {
+		t.Fatalf("patch: got %d %s", w.Code, w.Body)
+	}
+	var profile testProfile
+	g.DB.First(&profile, 1)
+	if profile.Nickname != nil || profile.Name != "Ada" {
+		t.Errorf("after clearing the nickname: %+v", profile)
+	}
+}
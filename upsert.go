@@ -0,0 +1,218 @@
+package apigen
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WithUniqueKey sets the JSON names of the fields forming a unique business key. The
+// upsert endpoint matches existing records on this key instead of the primary key,
+// so the key must be backed by a unique index.
+func WithUniqueKey(fields ...string) ModelOption {
+	return func(info *ModelInfo) {
+		info.UniqueKey = append(info.UniqueKey, fields...)
+	}
+}
+
+// errUpsertKeyTaken answers upserts whose key is held by a record they can't update
+var errUpsertKeyTaken = errors.New("a record with this key already exists")
+
+// validateUniqueKey checks that every unique key field names a field of the model
+func validateUniqueKey(modelInfo ModelInfo) error {
+	for _, name := range modelInfo.UniqueKey {
+		if _, ok := modelInfo.fieldByJSONName(name); !ok {
+			return fmt.Errorf("unique key field %q is not a field of model %s", name, modelInfo.Type.Name())
+		}
+	}
+	return nil
+}
+
+// upsertHandler returns a handler function for creating or updating an instance of a model
+// @Summary Create or update a model instance
+// @Description Create an instance of a model, or update the instance with the same unique key or ID
+// @Tags API
+// @Accept json,x-www-form-urlencoded,mpfd
+// @Produce json,application/vnd.api+json
+// @Param model body any true "Model instance"
+// @Success 200 {object} any
+// @Success 201 {object} any
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 413 {object} map[string]string
+// @Router /api/{model} [put]
+func (g *APIGenerator) upsertHandler(modelInfo ModelInfo) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Create a new instance of the model
+		instance := reflect.New(modelInfo.Type).Interface()
+
+		// Bind the request body to the model
+		if err := g.bindBody(c, modelInfo, instance); err != nil {
+			g.respondBindError(c, modelInfo, err)
+			return
+		}
+		if err := g.setTenant(c, modelInfo, instance); err != nil {
+			g.respondError(c, http.StatusInternalServerError, err)
+			return
+		}
+		allowed, err := g.upsertAllowed(c, modelInfo, instance)
+		if err != nil {
+			g.respondError(c, http.StatusInternalServerError, err)
+			return
+		}
+		if !allowed {
+			g.respondError(c, http.StatusForbidden, errForbidden)
+			return
+		}
+
+		// Write the record, telling an insert from an update by the write itself
+		created, err := g.upsert(c, modelInfo, instance)
+		if errors.Is(err, errUpsertKeyTaken) {
+			g.respondError(c, http.StatusConflict, err)
+			return
+		}
+		if err != nil {
+			g.respondDBError(c, err)
+			return
+		}
+
+		// Reload the record so the response holds the stored values
+		if !g.isDryRun(c) {
+			if err := g.modelDB(c, modelInfo).Scopes(g.upsertKeyScope(modelInfo, instance)).First(instance).Error; err != nil {
+				if err == gorm.ErrRecordNotFound {
+					// The record was deleted since it was written
+					g.respondError(c, http.StatusConflict, errUpsertKeyTaken)
+					return
+				}
+				g.respondError(c, http.StatusInternalServerError, err)
+				return
+			}
+		}
+		g.audit(c, modelInfo, nil, instance)
+
+		status := http.StatusOK
+		if created {
+			status = http.StatusCreated
+			if !g.isDryRun(c) {
+				setLocation(c, instance, modelInfo)
+			}
+		}
+		g.respond(c, status, modelInfo, instance)
+	}
+}
+
+// upsertKeyFields returns the unique key fields of a model, or its primary key
+// fields when it has no unique key
+func upsertKeyFields(modelInfo ModelInfo) []FieldInfo {
+	if len(modelInfo.UniqueKey) == 0 {
+		return modelInfo.PrimaryKeyFields
+	}
+
+	fields := make([]FieldInfo, 0, len(modelInfo.UniqueKey))
+	for _, name := range modelInfo.UniqueKey {
+		field, _ := modelInfo.fieldByJSONName(name)
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// upsertKeyScope matches the record sharing the upsert key of an instance
+func (g *APIGenerator) upsertKeyScope(modelInfo ModelInfo, instance any) func(*gorm.DB) *gorm.DB {
+	value := reflect.Indirect(reflect.ValueOf(instance))
+	fields := upsertKeyFields(modelInfo)
+	conditions := make([]clause.Expression, 0, len(fields))
+	for _, field := range fields {
+		conditions = append(conditions, clause.Eq{
+			Column: clause.Column{Name: g.columnName(field)},
+			Value:  value.FieldByName(field.Name).Interface(),
+		})
+	}
+
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(clause.And(conditions...))
+	}
+}
+
+// upsert inserts a record, or updates the record sharing its upsert key, in one
+// transaction. It reports whether the record was inserted, which concurrent upserts of
+// the same key can't make wrong: only one of them inserts, the others update.
+func (g *APIGenerator) upsert(c *gin.Context, modelInfo ModelInfo, instance any) (created bool, err error) {
+	key := g.upsertKeyScope(modelInfo, instance)
+	columns, updates := g.upsertColumns(modelInfo, instance)
+
+	// Nothing is written in dry-run mode, so looking the record up first can't race
+	if g.isDryRun(c) {
+		var count int64
+		if err := g.modelDB(c, modelInfo).Model(reflect.New(modelInfo.Type).Interface()).Scopes(key).Count(&count).Error; err != nil {
+			return false, err
+		}
+		return count == 0, g.writeDB(c, modelInfo).Clauses(clause.OnConflict{Columns: columns, DoNothing: true}).Create(instance).Error
+	}
+
+	err = g.modelDB(c, modelInfo).Transaction(func(tx *gorm.DB) error {
+		insert := tx.Clauses(clause.OnConflict{Columns: columns, DoNothing: true}).Create(instance)
+		if insert.Error != nil || insert.RowsAffected > 0 {
+			created = insert.RowsAffected > 0
+			return insert.Error
+		}
+
+		// The key is taken: update the record holding it, unless the model's scopes,
+		// e.g. the tenant filter, hide that record
+		model := reflect.New(modelInfo.Type).Interface()
+		var matched int64
+		if len(updates) == 0 {
+			// Nothing to update but the key: the record only needs to be visible
+			if err := tx.Model(model).Scopes(key).Count(&matched).Error; err != nil {
+				return err
+			}
+		} else {
+			update := tx.Model(model).Scopes(key).Select(updates).Updates(instance)
+			if update.Error != nil {
+				return update.Error
+			}
+			matched = update.RowsAffected
+		}
+		if matched == 0 {
+			return errUpsertKeyTaken
+		}
+		return nil
+	})
+	return created, err
+}
+
+// upsertColumns returns the columns of the upsert key, and the columns an upsert
+// updates: every column but the primary key, the key itself, the creation time, the
+// lock version and the read-only fields, which the request body can't set
+func (g *APIGenerator) upsertColumns(modelInfo ModelInfo, instance any) ([]clause.Column, []string) {
+	var columns []clause.Column
+	for _, field := range upsertKeyFields(modelInfo) {
+		columns = append(columns, clause.Column{Name: g.columnName(field)})
+	}
+
+	var updates []string
+	stmt := &gorm.Statement{DB: g.DB}
+	if err := stmt.Parse(instance); err == nil {
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName == "" || field.PrimaryKey || field.AutoCreateTime > 0 {
+				continue
+			}
+			if modelInfo.LockVersion && field.Name == versionFieldName {
+				continue
+			}
+			if modelField, ok := modelInfo.fieldByName(field.Name); ok && modelInfo.isReadOnly(modelField) {
+				continue
+			}
+			if slices.ContainsFunc(columns, func(column clause.Column) bool { return column.Name == field.DBName }) {
+				continue
+			}
+			updates = append(updates, field.DBName)
+		}
+	}
+	return columns, updates
+}
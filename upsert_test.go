@@ -0,0 +1,65 @@
+package apigen
+
+import (
+	"net/http"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+type testSetting struct {
+	ID    uint   `json:"id" gorm:"primaryKey"`
+	Key   string `json:"key" gorm:"uniqueIndex"`
+	Value string `json:"value"`
+}
+
+func TestUpsert(t *testing.T) {
+	g, router := newTestAPI(t, func(g *APIGenerator) {
+		if err := g.RegisterModelWithOptions(&testSetting{}, WithUniqueKey("key")); err != nil {
+			t.Fatal(err)
+		}
+	}, &testSetting{})
+
+	w := serve(router, http.MethodPut, "/api/test_settings", `{"key":"theme","value":"dark"}`)
+	if w.Code != http.StatusCreated || w.Header().Get("Location") == "" {
+		t.Fatalf("first upsert: got %d with Location %q, want 201", w.Code, w.Header().Get("Location"))
+	}
+	w = serve(router, http.MethodPut, "/api/test_settings", `{"key":"theme","value":"light"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("second upsert: got %d %s, want 200", w.Code, w.Body)
+	}
+	if setting := decode[testSetting](t, w); setting.Value != "light" || setting.ID == 0 {
+		t.Errorf("second upsert returned %+v", setting)
+	}
+
+	// A record written behind the API's back is updated, not reported as created
+	g.DB.Create(&testSetting{Key: "lang", Value: "en"})
+	if w := serve(router, http.MethodPut, "/api/test_settings", `{"key":"lang","value":"fr"}`); w.Code != http.StatusOK {
+		t.Errorf("upsert of an existing key: got %d, want 200", w.Code)
+	}
+
+	var count int64
+	g.DB.Model(&testSetting{}).Count(&count)
+	if count != 2 {
+		t.Errorf("got %d records, want 2", count)
+	}
+}
+
+func TestUpsertKeyHiddenByScope(t *testing.T) {
+	g, router := newTestAPI(t, func(g *APIGenerator) {
+		hidePrivate := WithScopes(func(db *gorm.DB) *gorm.DB { return db.Where("key <> ?", "private") })
+		if err := g.RegisterModelWithOptions(&testSetting{}, WithUniqueKey("key"), hidePrivate); err != nil {
+			t.Fatal(err)
+		}
+	}, &testSetting{})
+	g.DB.Create(&testSetting{Key: "private", Value: "secret"})
+
+	if w := serve(router, http.MethodPut, "/api/test_settings", `{"key":"private","value":"x"}`); w.Code != http.StatusConflict {
+		t.Errorf("upsert of a key held by a hidden record: got %d, want 409", w.Code)
+	}
+	var setting testSetting
+	g.DB.Where("key = ?", "private").First(&setting)
+	if setting.Value != "secret" {
+		t.Errorf("hidden record was overwritten: %+v", setting)
+	}
+}
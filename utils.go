@@ -4,10 +4,14 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+
+	"gorm.io/gorm/schema"
 )
 
 // ModelAnalyzer analyzes GORM models and extracts metadata
-type ModelAnalyzer struct{}
+type ModelAnalyzer struct {
+	TagKey string // Struct tag naming the fields, "json" if empty
+}
 
 // NewModelAnalyzer creates a new ModelAnalyzer
 func NewModelAnalyzer() *ModelAnalyzer {
@@ -25,11 +29,11 @@ func (a *ModelAnalyzer) AnalyzeModel(model any) (ModelInfo, error) {
 		return ModelInfo{}, fmt.Errorf("model must be a struct, got %s", modelType.Kind())
 	}
 
-	resourceName := toSnakeCase(modelType.Name())
-	pluralName := pluralize(resourceName)
+	resourceName, pluralName := resourceNames(modelType, "", nil)
 
 	modelInfo := ModelInfo{
 		Type:         modelType,
+		TableName:    tableName(modelType, schema.NamingStrategy{}),
 		ResourceName: resourceName,
 		PluralName:   pluralName,
 	}
@@ -37,7 +41,7 @@ func (a *ModelAnalyzer) AnalyzeModel(model any) (ModelInfo, error) {
 	// Process fields
 	for i := 0; i < modelType.NumField(); i++ {
 		field := modelType.Field(i)
-		jsonTag := field.Tag.Get("json")
+		jsonTag := field.Tag.Get(fieldTagKey(a.TagKey))
 		if jsonTag == "" || jsonTag == "-" {
 			continue
 		}
@@ -50,11 +54,22 @@ func (a *ModelAnalyzer) AnalyzeModel(model any) (ModelInfo, error) {
 			JSONName:  jsonName,
 			Type:      field.Type,
 			IsID:      field.Name == "ID" || strings.HasSuffix(field.Name, "ID"),
+			IsUUID:    isUUIDField(field),
 			OmitEmpty: omitEmpty,
+			Column:    gormColumn(field),
+			IsFile:    isFileField(field),
+			Nullable:  field.Type.Kind() == reflect.Ptr,
+			Binding:   field.Tag.Get("binding"),
 		}
 
 		modelInfo.Fields = append(modelInfo.Fields, fieldInfo)
 
+		// Check for polymorphic associations, which point back at this model
+		if fkInfo, ok := polymorphicRelation(field); ok {
+			modelInfo.ForeignKeys = append(modelInfo.ForeignKeys, fkInfo)
+			continue
+		}
+
 		// Check for foreign key relationships
 		if field.Type.Kind() == reflect.Struct && !isBasicType(field.Type) {
 			// This could be a foreign key relationship
@@ -68,7 +83,7 @@ func (a *ModelAnalyzer) AnalyzeModel(model any) (ModelInfo, error) {
 		}
 
 		// Check for foreign key ID fields
-		if strings.HasSuffix(field.Name, "ID") && field.Type.Kind() == reflect.Uint {
+		if strings.HasSuffix(field.Name, "ID") && field.Type.Kind() == reflect.Uint && !isPrimaryKeyField(field) {
 			relatedModel := strings.TrimSuffix(field.Name, "ID")
 			fkInfo := ForeignKeyInfo{
 				FieldName:      field.Name,
@@ -79,6 +94,10 @@ func (a *ModelAnalyzer) AnalyzeModel(model any) (ModelInfo, error) {
 		}
 	}
 
+	modelInfo.PrimaryKeyFields = primaryKeyFields(modelType, modelInfo.Fields)
+	modelInfo.PrimaryKeyField = singlePrimaryKey(modelType, modelInfo.PrimaryKeyFields)
+	modelInfo.SearchableFields = searchableFields(modelInfo.Fields)
+
 	return modelInfo, nil
 }
 
@@ -92,8 +111,8 @@ func (a *ModelAnalyzer) GenerateRequestStruct(modelInfo ModelInfo, isCreate bool
 
 	// Add fields
 	for _, field := range modelInfo.Fields {
-		// Skip ID fields for create requests
-		if isCreate && field.IsID && field.Name == "ID" {
+		// Skip ID fields for create requests, and read-only fields
+		if (isCreate && field.IsID && field.Name == "ID") || modelInfo.isReadOnly(field) {
 			continue
 		}
 
@@ -192,6 +211,9 @@ func getTypeName(t reflect.Type) string {
 		}
 		return t.Name()
 	case reflect.Slice, reflect.Array:
+		if t == uuidType {
+			return "uuid.UUID"
+		}
 		return "[]" + getTypeName(t.Elem())
 	case reflect.Map:
 		return fmt.Sprintf("map[%s]%s", getTypeName(t.Key()), getTypeName(t.Elem()))
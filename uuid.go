@@ -0,0 +1,75 @@
+package apigen
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// uuidPattern matches the canonical textual representation of a UUID
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// uuidType is the reflect.Type of github.com/google/uuid.UUID
+var uuidType = reflect.TypeOf(uuid.UUID{})
+
+// isUUIDField reports whether a struct field stores a UUID, either as a uuid.UUID
+// or as a string column declared with gorm:"type:uuid"
+func isUUIDField(field reflect.StructField) bool {
+	if field.Type == uuidType {
+		return true
+	}
+	if field.Type.Kind() != reflect.String {
+		return false
+	}
+	for _, setting := range strings.Split(field.Tag.Get("gorm"), ";") {
+		if strings.EqualFold(strings.TrimSpace(setting), "type:uuid") {
+			return true
+		}
+	}
+	return false
+}
+
+// hasUUIDPrimaryKey reports whether the model's primary key field stores a UUID
+func (m ModelInfo) hasUUIDPrimaryKey() bool {
+	return m.PrimaryKeyField.IsUUID
+}
+
+// UUIDPrimaryKey returns a GORM callback that assigns a random UUID to empty string
+// primary keys before a record is created. It replaces the BeforeCreate hook that
+// would otherwise be written for every model:
+//
+//	db.Callback().Create().Before("gorm:create").Register("apigen:uuid", apigen.UUIDPrimaryKey())
+func UUIDPrimaryKey() func(*gorm.DB) {
+	return func(tx *gorm.DB) {
+		if tx.Statement.Schema == nil {
+			return
+		}
+
+		field := tx.Statement.Schema.PrioritizedPrimaryField
+		if field == nil || field.FieldType.Kind() != reflect.String {
+			return
+		}
+
+		setUUID := func(rv reflect.Value) {
+			rv = reflect.Indirect(rv)
+			if rv.Kind() != reflect.Struct {
+				return
+			}
+			if _, isZero := field.ValueOf(tx.Statement.Context, rv); isZero {
+				_ = field.Set(tx.Statement.Context, rv, uuid.New().String())
+			}
+		}
+
+		switch rv := tx.Statement.ReflectValue; rv.Kind() {
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < rv.Len(); i++ {
+				setUUID(rv.Index(i))
+			}
+		default:
+			setUUID(rv)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package apigen
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+type testDevice struct {
+	ID   string `json:"id" gorm:"primaryKey;type:uuid"`
+	Name string `json:"name"`
+}
+
+type testKeyless struct {
+	Name string `json:"name"`
+}
+
+func TestUUIDPrimaryKey(t *testing.T) {
+	g, router := newTestAPI(t, nil, &testDevice{})
+	g.DB.Callback().Create().Before("gorm:create").Register("apigen:uuid", UUIDPrimaryKey())
+
+	w := serve(router, http.MethodPost, "/api/test_devices", `{"name":"sensor"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: got %d %s", w.Code, w.Body)
+	}
+	created := decode[testDevice](t, w)
+	if !uuidPattern.MatchString(created.ID) {
+		t.Fatalf("created ID %q is not a UUID", created.ID)
+	}
+
+	if w := serve(router, http.MethodGet, "/api/test_devices/"+created.ID, ""); w.Code != http.StatusOK {
+		t.Errorf("get by UUID: got %d %s", w.Code, w.Body)
+	}
+	if w := serve(router, http.MethodGet, "/api/test_devices/42", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("get by malformed UUID: got %d, want 400", w.Code)
+	}
+}
+
+func TestModelWithoutPrimaryKey(t *testing.T) {
+	g := New(nil, nil)
+	if err := g.RegisterModelWithOptions(&testKeyless{}); err == nil {
+		t.Error("registering a model without a primary key succeeded")
+	}
+
+	info := ModelInfo{Type: reflect.TypeOf(testKeyless{})}
+	if _, err := primaryKeyValue(&testKeyless{}, info); err == nil {
+		t.Error("primaryKeyValue of a model without an ID field succeeded")
+	}
+	if id := recordID(&testKeyless{}, info); id != "" {
+		t.Errorf("recordID of a model without an ID field: got %q", id)
+	}
+}
@@ -0,0 +1,148 @@
+package apigen
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// ValidationErrorMapper turns the error returned by binding a request body into the
+// error reported to the client. It should name fields by their JSON names.
+type ValidationErrorMapper func(modelInfo ModelInfo, err error) error
+
+// DefaultValidationMessages maps validator tags to the message reported for a failed
+// check. "{param}" is replaced by the tag parameter, e.g. 3 for min=3.
+var DefaultValidationMessages = map[string]string{
+	"required": "is required",
+	"email":    "must be a valid email address",
+	"min":      "must be at least {param}",
+	"gte":      "must be at least {param}",
+	"max":      "must be at most {param}",
+	"lte":      "must be at most {param}",
+	"len":      "must have length {param}",
+	"oneof":    "must be one of {param}",
+	"url":      "must be a valid URL",
+	"uuid":     "must be a valid UUID",
+}
+
+// FieldError describes why a field of a request body is invalid
+type FieldError struct {
+	Field   string `json:"field"`           // JSON name of the field
+	Message string `json:"message"`         // Problem description, e.g. "is required"
+	Tag     string `json:"tag,omitempty"`   // Failed check, e.g. "email", "type" or "unknown"
+	Value   any    `json:"value,omitempty"` // Offending value of a failed oneof check, never of other checks
+}
+
+// echoedTags lists the validator tags whose failing value is reported in FieldError.Value.
+// Other values are left out as they may be secrets, e.g. a password failing min=8.
+var echoedTags = map[string]bool{"oneof": true}
+
+// ValidationError lists the invalid fields of a request body. It is answered as
+// {"errors": [...]}, one FieldError per field.
+type ValidationError struct {
+	Errors []FieldError
+}
+
+// Error joins the problems of all fields
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, fieldErr := range e.Errors {
+		messages = append(messages, fieldErr.Field+" "+fieldErr.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// newValidationError returns a ValidationError listing its field errors by field name
+func newValidationError(fieldErrors []FieldError) *ValidationError {
+	sort.SliceStable(fieldErrors, func(i, j int) bool { return fieldErrors[i].Field < fieldErrors[j].Field })
+	return &ValidationError{Errors: fieldErrors}
+}
+
+// DefaultValidationErrorMapper reports validator and JSON type errors as a
+// ValidationError naming the JSON fields. Other errors are returned unchanged.
+func DefaultValidationErrorMapper(modelInfo ModelInfo, err error) error {
+	return mapValidationError(modelInfo, err, DefaultValidationMessages)
+}
+
+// mapValidationError is DefaultValidationErrorMapper with the given tag messages
+func mapValidationError(modelInfo ModelInfo, err error, messages map[string]string) error {
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		fieldErrors := make([]FieldError, 0, len(validationErrors))
+		for _, fieldErr := range validationErrors {
+			name := fieldErr.Field()
+			for _, field := range modelInfo.Fields {
+				if field.Name == fieldErr.StructField() {
+					name = field.JSONName
+				}
+			}
+			fieldError := FieldError{
+				Field:   name,
+				Message: validationMessage(fieldErr, messages),
+				Tag:     fieldErr.Tag(),
+			}
+			if echoedTags[fieldErr.Tag()] {
+				fieldError.Value = fieldErr.Value()
+			}
+			fieldErrors = append(fieldErrors, fieldError)
+		}
+		return newValidationError(fieldErrors)
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		name := typeErr.Field
+		for _, field := range modelInfo.Fields {
+			if field.WireName != "" && field.WireName == name {
+				name = field.JSONName
+			}
+		}
+		return newValidationError([]FieldError{{
+			Field:   name,
+			Message: fmt.Sprintf("must be of type %s, not %s", typeErr.Type, typeErr.Value),
+			Tag:     "type",
+		}})
+	}
+
+	return err
+}
+
+// unknownFields returns a ValidationError naming the keys of a request body that aren't
+// API names of the model's fields, or nil when every key is known
+func unknownFields(values map[string]any, modelInfo ModelInfo) error {
+	known := modelInfo.apiFieldNames()
+	var fieldErrors []FieldError
+	for name := range values {
+		if !known[name] {
+			fieldErrors = append(fieldErrors, FieldError{Field: name, Message: "is not a known field", Tag: "unknown"})
+		}
+	}
+	if len(fieldErrors) == 0 {
+		return nil
+	}
+	return newValidationError(fieldErrors)
+}
+
+// validationMessage describes a failed validator tag in plain words
+func validationMessage(fieldErr validator.FieldError, messages map[string]string) string {
+	message, ok := messages[fieldErr.Tag()]
+	if !ok {
+		message, ok = DefaultValidationMessages[fieldErr.Tag()]
+	}
+	if !ok {
+		return fmt.Sprintf("failed the %s validation", fieldErr.Tag())
+	}
+	return strings.ReplaceAll(message, "{param}", fieldErr.Param())
+}
+
+// validationError maps a binding error with the generator's ValidationErrorMapper
+func (g *APIGenerator) validationError(modelInfo ModelInfo, err error) error {
+	if g.ValidationErrorMapper != nil {
+		return g.ValidationErrorMapper(modelInfo, err)
+	}
+	return mapValidationError(modelInfo, err, g.ValidationMessages)
+}
@@ -0,0 +1,106 @@
+package apigen
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type testAccount struct {
+	ID       uint   `json:"id" gorm:"primaryKey"`
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"min=8"`
+	Plan     string `json:"plan" binding:"oneof=free pro"`
+	Age      int    `json:"age"`
+}
+
+func TestValidationErrors(t *testing.T) {
+	_, router := newTestAPI(t, nil, &testAccount{})
+
+	w := serve(router, http.MethodPost, "/api/test_accounts", `{"email":"nope","password":"hunter2","plan":"gold"}`)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("invalid body: got %d %s", w.Code, w.Body)
+	}
+	if strings.Contains(w.Body.String(), "hunter2") {
+		t.Errorf("the password was echoed back: %s", w.Body)
+	}
+
+	body := decode[struct{ Errors []FieldError }](t, w)
+	want := []FieldError{
+		{Field: "email", Message: "must be a valid email address", Tag: "email"},
+		{Field: "password", Message: "must be at least 8", Tag: "min"},
+		{Field: "plan", Message: "must be one of free pro", Tag: "oneof", Value: "gold"},
+	}
+	if len(body.Errors) != len(want) {
+		t.Fatalf("got errors %+v, want %+v", body.Errors, want)
+	}
+	for i := range want {
+		if body.Errors[i] != want[i] {
+			t.Errorf("error %d: got %+v, want %+v", i, body.Errors[i], want[i])
+		}
+	}
+}
+
+func TestTypeValidationError(t *testing.T) {
+	_, router := newTestAPI(t, nil, &testAccount{})
+
+	w := serve(router, http.MethodPost, "/api/test_accounts", `{"email":"a@b.co","password":"12345678","plan":"free","age":"secret"}`)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("mistyped body: got %d %s", w.Code, w.Body)
+	}
+	body := decode[struct{ Errors []FieldError }](t, w)
+	if len(body.Errors) != 1 || body.Errors[0] != (FieldError{Field: "age", Message: "must be of type int, not string", Tag: "type"}) {
+		t.Errorf("got errors %+v", body.Errors)
+	}
+}
+
+func TestRequiredFieldError(t *testing.T) {
+	_, router := newTestAPI(t, nil, &testAccount{})
+
+	w := serve(router, http.MethodPost, "/api/test_accounts", `{"password":"12345678","plan":"free"}`)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("missing email: got %d %s", w.Code, w.Body)
+	}
+	body := decode[struct{ Errors []FieldError }](t, w)
+	if len(body.Errors) != 1 || body.Errors[0] != (FieldError{Field: "email", Message: "is required", Tag: "required"}) {
+		t.Errorf("got errors %+v", body.Errors)
+	}
+}
+
+func TestUpdateIDMismatch(t *testing.T) {
+	g, router := newTestAPI(t, nil, &testAccount{})
+	g.DB.Create(&testAccount{Email: "a@b.co", Password: "12345678", Plan: "free"})
+
+	if w := serve(router, http.MethodPatch, "/api/test_accounts/1", `{"id":2,"plan":"pro"}`); w.Code != http.StatusConflict {
+		t.Errorf("another ID: got %d, want 409", w.Code)
+	}
+	if w := serve(router, http.MethodPatch, "/api/test_accounts/1", `{"id":1,"plan":"pro"}`); w.Code != http.StatusOK {
+		t.Errorf("the same ID: got %d %s", w.Code, w.Body)
+	}
+}
+
+func TestStrictSchemaValidation(t *testing.T) {
+	g, router := newTestAPI(t, func(g *APIGenerator) {
+		g.StrictSchemaValidation = true
+	}, &testUser{})
+
+	w := serve(router, http.MethodPost, "/api/test_users", `{"name":"Ada","admin":true}`)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unknown field: got %d %s", w.Code, w.Body)
+	}
+	body := decode[struct{ Errors []FieldError }](t, w)
+	if len(body.Errors) != 1 || body.Errors[0] != (FieldError{Field: "admin", Message: "is not a known field", Tag: "unknown"}) {
+		t.Errorf("got errors %+v", body.Errors)
+	}
+
+	g.DB.Create(&testUser{Name: "Ada"})
+	if w := serve(router, http.MethodPatch, "/api/test_users/1", `{"admin":true}`); w.Code != http.StatusBadRequest {
+		t.Errorf("unknown field on update: got %d, want 400", w.Code)
+	}
+	if w := serve(router, http.MethodPatch, "/api/test_users/1", `{"name":42}`); w.Code != http.StatusBadRequest {
+		t.Errorf("mistyped field: got %d, want 400", w.Code)
+	}
+	if w := serve(router, http.MethodPatch, "/api/test_users/1", `{"name":"Grace"}`); w.Code != http.StatusOK {
+		t.Errorf("known field: got %d %s", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,175 @@
+package apigen
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// defaultVendorName is the vendor of the versioned media types when VendorName is empty
+const defaultVendorName = "api"
+
+// APIVersion is a version of the API selected by content negotiation: requests sending
+// Accept: application/vnd.{VendorName}.v{Version}+json are served with the models of
+// the version. Models it doesn't override are served as registered.
+type APIVersion struct {
+	Version string               // e.g. "2"
+	Models  map[string]ModelInfo // Overrides of registered models, keyed by model name; see VersionModel
+}
+
+// versionedAPI holds the routes generated for the models of an API version
+type versionedAPI struct {
+	APIVersion
+	engine *gin.Engine // Serves the routes of the overridden models, set by GenerateAPI
+}
+
+// VersionModel builds the ModelInfo of a model for APIVersion.Models, e.g. a struct
+// exposing the fields of an older version. It is served on the routes, and stored in
+// the table, of the registered model it overrides.
+func (g *APIGenerator) VersionModel(model any, opts ...ModelOption) (ModelInfo, error) {
+	return g.analyzeModel(model, opts...)
+}
+
+// RegisterVersion adds a version of the API selected by the Accept header. It must be
+// called before GenerateAPI.
+func (g *APIGenerator) RegisterVersion(version APIVersion) error {
+	if version.Version == "" {
+		return fmt.Errorf("version is required")
+	}
+	if _, exists := g.versions[version.Version]; exists {
+		return fmt.Errorf("version %s is already registered", version.Version)
+	}
+
+	models := make(map[string]ModelInfo, len(version.Models))
+	for name, override := range version.Models {
+		base, exists := g.Models[name]
+		if !exists {
+			return fmt.Errorf("version %s overrides model %s, which is not registered", version.Version, name)
+		}
+
+		// The override answers on the routes of the registered model, from its table
+		override.ResourceName, override.PluralName = base.ResourceName, base.PluralName
+		if override.TableName != base.TableName {
+			table := base.TableName
+			override.TableName = table
+			override.Scopes = append([]func(*gorm.DB) *gorm.DB{func(db *gorm.DB) *gorm.DB {
+				return db.Table(table)
+			}}, override.Scopes...)
+		}
+		models[name] = override
+	}
+	version.Models = models
+
+	if g.versions == nil {
+		g.versions = make(map[string]*versionedAPI)
+	}
+	g.versions[version.Version] = &versionedAPI{APIVersion: version}
+	return nil
+}
+
+// generateVersions generates the routes of the models overridden by each version on
+// an engine of its own, and serves the Swagger document of each version
+func (g *APIGenerator) generateVersions() error {
+	names := make([]string, 0, len(g.versions))
+	for name := range g.versions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		version := g.versions[name]
+
+		// A copy of the generator serves the version's models on a private engine
+		generator := *g
+		generator.Models = maps.Clone(g.Models)
+		maps.Copy(generator.Models, version.Models)
+		generator.SwaggerInfo.Version = name
+		generator.versions = nil
+		generator.routes = nil
+		version.engine = gin.New()
+		generator.Router = version.engine
+		generator.Group = version.engine.Group(g.mountPath)
+		for modelName := range version.Models {
+			generator.generateModelAPI(generator.Models[modelName])
+		}
+
+		swaggerPath := versionedPath("/swagger.json", name)
+		if !g.RegisteredPaths[swaggerPath] {
+			g.RegisteredPaths[swaggerPath] = true
+			document, err := json.Marshal(generator.swaggerGenerator().GenerateDocument(generator.SwaggerInfo))
+			if err != nil {
+				return fmt.Errorf("failed to build swagger document of version %s: %w", name, err)
+			}
+			g.Group.GET(swaggerPath, func(c *gin.Context) {
+				c.Data(http.StatusOK, "application/json; charset=utf-8", document)
+			})
+		}
+		if g.SwaggerFilePath != "" {
+			if err := generator.writeSwaggerFile(versionedPath(g.SwaggerFilePath, name)); err != nil {
+				return fmt.Errorf("failed to write swagger file of version %s: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// versionedPath inserts a version before the extension of a path, e.g.
+// swagger.v2.json for swagger.json
+func versionedPath(path, version string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + ".v" + version + ext
+}
+
+// versionMiddleware returns the middleware handing the requests for an API version
+// overriding a model to the routes of the version. It returns nil when no version is
+// registered.
+func (g *APIGenerator) versionMiddleware(modelInfo ModelInfo) gin.HandlerFunc {
+	if len(g.versions) == 0 {
+		return nil
+	}
+
+	vendor := g.VendorName
+	if vendor == "" {
+		vendor = defaultVendorName
+	}
+	mediaType := regexp.MustCompile(`application/vnd\.` + regexp.QuoteMeta(vendor) + `\.v([0-9A-Za-z._-]+)\+json`)
+	modelName := modelInfo.Type.Name()
+
+	return func(c *gin.Context) {
+		c.Writer.Header().Add("Vary", "Accept")
+
+		name := g.DefaultVersion
+		match := mediaType.FindStringSubmatch(c.GetHeader("Accept"))
+		if match != nil {
+			name = match[1]
+		}
+		if name == "" {
+			c.Next()
+			return
+		}
+
+		version, exists := g.versions[name]
+		if !exists {
+			g.respondError(c, http.StatusNotAcceptable, fmt.Errorf("API version %s is not supported", name))
+			return
+		}
+		if match != nil {
+			c.Header("Content-Type", match[0]+"; charset=utf-8")
+		}
+		if _, overridden := version.Models[modelName]; !overridden || version.engine == nil {
+			c.Next()
+			return
+		}
+
+		version.engine.ServeHTTP(c.Writer, c.Request)
+		c.Abort()
+	}
+}
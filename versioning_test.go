@@ -0,0 +1,61 @@
+package apigen
+
+import (
+	"net/http"
+	"testing"
+)
+
+// testUserV1 is testUser as served by version 1, before emails were added
+type testUserV1 struct {
+	ID   uint   `json:"id" gorm:"primaryKey"`
+	Name string `json:"name"`
+}
+
+func TestAPIVersions(t *testing.T) {
+	g, router := newTestAPI(t, func(g *APIGenerator) {
+		g.RegisterModelWithOptions(&testUser{})
+		v1, err := g.VersionModel(&testUserV1{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := g.RegisterVersion(APIVersion{Version: "1", Models: map[string]ModelInfo{"testUser": v1}}); err != nil {
+			t.Fatal(err)
+		}
+		if err := g.RegisterVersion(APIVersion{Version: "2"}); err != nil {
+			t.Fatal(err)
+		}
+		g.DefaultVersion = "2"
+	}, &testUser{})
+	g.DB.Create(&testUser{Name: "Ada", Email: "ada@example.com"})
+
+	w := serve(router, http.MethodGet, "/api/test_users/1", "", "Accept", "application/vnd.api.v1+json")
+	if user := decode[map[string]any](t, w); w.Code != http.StatusOK || len(user) != 2 || user["name"] != "Ada" {
+		t.Errorf("v1: got %d %v", w.Code, user)
+	}
+	w = serve(router, http.MethodGet, "/api/test_users/1", "", "Accept", "application/vnd.api.v2+json")
+	if user := decode[map[string]any](t, w); w.Code != http.StatusOK || user["email"] != "ada@example.com" {
+		t.Errorf("v2: got %d %v", w.Code, user)
+	}
+	if user := decode[map[string]any](t, serve(router, http.MethodGet, "/api/test_users/1", "")); user["email"] != "ada@example.com" {
+		t.Errorf("default version: got %v", user)
+	}
+	if w := serve(router, http.MethodGet, "/api/test_users/1", "", "Accept", "application/vnd.api.v3+json"); w.Code != http.StatusNotAcceptable {
+		t.Errorf("unknown version: got %d, want 406", w.Code)
+	}
+
+	// Version 1 writes through its own model
+	if w := serve(router, http.MethodPost, "/api/test_users", `{"name":"Grace","email":"grace@example.com"}`, "Accept", "application/vnd.api.v1+json"); w.Code != http.StatusCreated {
+		t.Fatalf("v1 create: got %d %s", w.Code, w.Body)
+	}
+	var grace testUser
+	g.DB.First(&grace, 2)
+	if grace.Name != "Grace" || grace.Email != "" {
+		t.Errorf("v1 create stored %+v", grace)
+	}
+
+	document := decode[map[string]any](t, serve(router, http.MethodGet, "/swagger.v1.json", ""))
+	properties := document["definitions"].(map[string]any)["testUser"].(map[string]any)["properties"].(map[string]any)
+	if _, ok := properties["email"]; ok {
+		t.Errorf("v1 swagger documents the email: %v", properties)
+	}
+}